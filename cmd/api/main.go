@@ -5,6 +5,8 @@ import (
 	"assignment-2/internal/config"
 	"assignment-2/internal/http/server"
 	"log"
+	// Embed the timezone database, so the timezones of the dashboards are known without one on the host
+	_ "time/tzdata"
 )
 
 func init() {
@@ -0,0 +1,173 @@
+// Package cache caches responses from the external services in memory, with a TTL per cache and stale-while-revalidate
+// refreshing.
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Stats are the hit and miss statistics of a cache.
+type Stats struct {
+	Hits    uint64 `json:"hits"`
+	Stale   uint64 `json:"stale"`
+	Misses  uint64 `json:"misses"`
+	Evicted uint64 `json:"evicted"`
+	Entries int    `json:"entries"`
+}
+
+// entry is a cached value and when it was fetched.
+type entry[T any] struct {
+	value   T
+	fetched time.Time
+}
+
+// call is a fetch in flight, which concurrent requests for the same key wait for.
+type call[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// Cache caches values by key. Values younger than the TTL are fresh. Values older than the TTL, but younger than twice
+// the TTL, are stale: they are still returned, while being refreshed in the background. Older values are fetched again
+// before being returned, and are evicted the next time the cache is used after they expire, at most once per TTL, so
+// keys that are no longer requested do not stay in memory. Errors are not cached.
+type Cache[T any] struct {
+	name string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]entry[T]
+	calls   map[string]*call[T]
+	stats   Stats
+	evicted time.Time
+}
+
+// registry holds the statistics of every cache, by name.
+var (
+	registry   = map[string]func() Stats{}
+	registryMu sync.Mutex
+)
+
+// New creates a cache with the given TTL, and registers its statistics under the given name.
+func New[T any](name string, ttl time.Duration) *Cache[T] {
+	c := &Cache[T]{
+		name:    name,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]entry[T]{},
+		calls:   map[string]*call[T]{},
+	}
+	c.evicted = c.now()
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = c.Stats
+
+	return c
+}
+
+// Get returns the value for the key, calling fetch if it is not cached or has expired. Concurrent requests for the
+// same key share a single call to fetch. The context only bounds how long the caller waits: if it is done before the
+// value is fetched, the context error is returned, while the fetch carries on so the value is cached for later
+// requests. Fetch is therefore not given the context, and should use its own, such as context.Background().
+func (c *Cache[T]) Get(ctx context.Context, key string, fetch func() (T, error)) (T, error) {
+	c.mu.Lock()
+	c.evictExpired()
+
+	if e, ok := c.entries[key]; ok {
+		age := c.now().Sub(e.fetched)
+		if age < c.ttl {
+			c.stats.Hits++
+			c.mu.Unlock()
+			return e.value, nil
+		}
+		if age < 2*c.ttl {
+			// Serve the stale value, and refresh it in the background
+			c.stats.Stale++
+			c.startFetch(key, fetch)
+			c.mu.Unlock()
+			return e.value, nil
+		}
+	}
+
+	c.stats.Misses++
+	inFlight := c.startFetch(key, fetch)
+	c.mu.Unlock()
+
+	select {
+	case <-inFlight.done:
+		return inFlight.value, inFlight.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// evictExpired removes the values older than twice the TTL, which would be fetched again anyway, unless they were
+// already removed within the last TTL. Must be called with the lock held.
+func (c *Cache[T]) evictExpired() {
+	now := c.now()
+	if now.Sub(c.evicted) < c.ttl {
+		return
+	}
+	c.evicted = now
+
+	for key, e := range c.entries {
+		if now.Sub(e.fetched) >= 2*c.ttl {
+			delete(c.entries, key)
+			c.stats.Evicted++
+		}
+	}
+}
+
+// startFetch starts fetching the value for the key, unless it is already being fetched. Returns the call in flight.
+// Must be called with the lock held.
+func (c *Cache[T]) startFetch(key string, fetch func() (T, error)) *call[T] {
+	if inFlight, ok := c.calls[key]; ok {
+		return inFlight
+	}
+
+	inFlight := &call[T]{done: make(chan struct{})}
+	c.calls[key] = inFlight
+
+	go func() {
+		inFlight.value, inFlight.err = fetch()
+
+		c.mu.Lock()
+		if inFlight.err == nil {
+			c.entries[key] = entry[T]{value: inFlight.value, fetched: c.now()}
+		}
+		delete(c.calls, key)
+		c.mu.Unlock()
+
+		close(inFlight.done)
+	}()
+
+	return inFlight
+}
+
+// Stats returns the hit and miss statistics of the cache.
+func (c *Cache[T]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	return stats
+}
+
+// AllStats returns the statistics of every cache, by name.
+func AllStats() map[string]Stats {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	allStats := make(map[string]Stats, len(registry))
+	for name, stats := range registry {
+		allStats[name] = stats()
+	}
+	return allStats
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// clock is a fake clock for testing expiry.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestCache(name string) (*Cache[int], *clock) {
+	c := New[int](name, time.Minute)
+	fake := &clock{now: time.Now()}
+	c.now = fake.Now
+	c.evicted = fake.Now()
+	return c, fake
+}
+
+func TestCache_Get(t *testing.T) {
+	c, fake := newTestCache("TestCache_Get")
+
+	var calls int32
+	fetch := func() (int, error) {
+		return int(atomic.AddInt32(&calls, 1)), nil
+	}
+
+	// The first request is a miss
+	if got, err := c.Get(context.Background(), "key", fetch); got != 1 || err != nil {
+		t.Errorf("Get() = %v, %v, want 1 from the first fetch", got, err)
+	}
+
+	// A fresh value is a hit
+	fake.Advance(30 * time.Second)
+	if got, _ := c.Get(context.Background(), "key", fetch); got != 1 {
+		t.Errorf("Get() = %v, want cached 1", got)
+	}
+
+	// A stale value is returned, while it is refreshed in the background
+	fake.Advance(time.Minute)
+	if got, _ := c.Get(context.Background(), "key", fetch); got != 1 {
+		t.Errorf("Get() = %v, want stale 1", got)
+	}
+	waitForFetches(c)
+	if got, _ := c.Get(context.Background(), "key", fetch); got != 2 {
+		t.Errorf("Get() = %v, want refreshed 2", got)
+	}
+
+	// An expired value is evicted, and fetched again
+	fake.Advance(3 * time.Minute)
+	if got, _ := c.Get(context.Background(), "key", fetch); got != 3 {
+		t.Errorf("Get() = %v, want 3 from a new fetch", got)
+	}
+
+	want := Stats{Hits: 2, Stale: 1, Misses: 2, Evicted: 1, Entries: 1}
+	if stats := c.Stats(); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+	if stats := AllStats()["TestCache_Get"]; stats != want {
+		t.Errorf("AllStats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestCache_Evict(t *testing.T) {
+	c, fake := newTestCache("TestCache_Evict")
+	fetch := func() (int, error) { return 1, nil }
+
+	if _, err := c.Get(context.Background(), "old", fetch); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	waitForFetches(c)
+	fake.Advance(90 * time.Second)
+	if _, err := c.Get(context.Background(), "new", fetch); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	waitForFetches(c)
+
+	// Expired values are kept until the cache is used again
+	fake.Advance(time.Minute)
+	if stats := c.Stats(); stats.Entries != 2 || stats.Evicted != 0 {
+		t.Errorf("Stats() = %+v, want 2 entries before the cache is used again", stats)
+	}
+
+	// Using the cache evicts the expired values, whichever key is requested, but keeps the stale ones
+	if got, err := c.Get(context.Background(), "new", fetch); got != 1 || err != nil {
+		t.Fatalf("Get() = %v, %v, want stale 1", got, err)
+	}
+	waitForFetches(c)
+	if stats := c.Stats(); stats.Entries != 1 || stats.Evicted != 1 {
+		t.Errorf("Stats() = %+v, want the old value evicted", stats)
+	}
+	c.mu.Lock()
+	_, old := c.entries["old"]
+	c.mu.Unlock()
+	if old {
+		t.Errorf("the old value is still cached")
+	}
+}
+
+func TestCache_GetError(t *testing.T) {
+	c, _ := newTestCache("TestCache_GetError")
+
+	fetchErr := errors.New("upstream unavailable")
+	if _, err := c.Get(context.Background(), "key", func() (int, error) { return 0, fetchErr }); !errors.Is(err, fetchErr) {
+		t.Errorf("Get() error = %v, want %v", err, fetchErr)
+	}
+
+	// Errors are not cached
+	if got, err := c.Get(context.Background(), "key", func() (int, error) { return 1, nil }); got != 1 || err != nil {
+		t.Errorf("Get() = %v, %v, want 1 after a failed fetch", got, err)
+	}
+}
+
+func TestCache_GetCollapsesConcurrentRequests(t *testing.T) {
+	c, _ := newTestCache("TestCache_GetCollapsesConcurrentRequests")
+
+	var calls int32
+	release := make(chan struct{})
+	fetch := func() (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := c.Get(context.Background(), "key", fetch); got != 42 || err != nil {
+				t.Errorf("Get() = %v, %v, want 42", got, err)
+			}
+		}()
+	}
+
+	// Wait for every request to wait for the fetch, then let it finish
+	for c.Stats().Misses < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("fetch was called %v times, want 1", calls)
+	}
+}
+
+func TestCache_GetContextDone(t *testing.T) {
+	c, _ := newTestCache("TestCache_GetContextDone")
+
+	release := make(chan struct{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.Get(
+		ctx, "key", func() (int, error) {
+			<-release
+			return 42, nil
+		},
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The fetch carries on after the deadline, and its value is cached
+	close(release)
+	waitForFetches(c)
+	if got, _ := c.Get(context.Background(), "key", func() (int, error) { return 0, nil }); got != 42 {
+		t.Errorf("Get() = %v, want 42 cached by the fetch that outlived the deadline", got)
+	}
+}
+
+// waitForFetches waits until no fetches are in flight.
+func waitForFetches(c *Cache[int]) {
+	for {
+		c.mu.Lock()
+		inFlight := len(c.calls)
+		c.mu.Unlock()
+		if inFlight == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
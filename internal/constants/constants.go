@@ -21,6 +21,15 @@ const NotificationsPath = DashboardPath + "/notifications/"
 // StatusPath Path for the status
 const StatusPath = DashboardPath + "/status/"
 
+// EventsPath Path for the server-sent event stream
+const EventsPath = DashboardPath + "/events/"
+
+// GraphQLPath Path for the GraphQL endpoint
+const GraphQLPath = DashboardPath + "/graphql/"
+
+// ComparePath Path for the comparison of dashboards
+const ComparePath = DashboardPath + "/compare/"
+
 // RestCountriesApi Christopher's RestCountries API
 const RestCountriesApi = "http://129.241.150.113:8080/v3.1/"
 
@@ -39,4 +48,43 @@ const TestCurrencyApi = "/mock/currency/"
 // TestMeteoApi Local stub for Meteo API
 const TestMeteoApi = "/mock/meteo/"
 
+// IdempotencyKeyHeader Header used by clients to make POST requests safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader Header set on responses that are replayed from a stored idempotency record
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// DashboardPartialHeader Header set on dashboards that miss features because an external service failed
+const DashboardPartialHeader = "Dashboard-Partial"
+
+// MaxForecastDays Largest number of days of weather forecast that can be registered, as supported by the Meteo API
+const MaxForecastDays = 16
+
+// MaxWeatherPoints Largest number of points the weather of a dashboard can be measured at
+const MaxWeatherPoints = 10
+
+// MaxRegionCountries Largest number of ISO codes a regional dashboard can be registered with
+const MaxRegionCountries = 50
+
+// MaxPrecision Largest number of decimals the quantities of a dashboard can be rounded to
+const MaxPrecision = 6
+
+// MaxComparedDashboards Largest number of dashboards that can be compared at once
+const MaxComparedDashboards = 10
+
+// MaxComputedFeatures Largest number of computed features a dashboard can be registered with
+const MaxComputedFeatures = 10
+
+// MaxExpressionLength Largest number of bytes of the expression of a computed feature
+const MaxExpressionLength = 200
+
+// MinViewRefresh Shortest time in seconds between refreshes of the HTML view of a dashboard
+const MinViewRefresh = 10
+
+// MaxViewRefresh Longest time in seconds between refreshes of the HTML view of a dashboard
+const MaxViewRefresh = 24 * 60 * 60
+
+// MaxBadgeLabelLength Largest number of characters of the label of a badge
+const MaxBadgeLabelLength = 50
+
 /* https://open-meteo.com/en/features#available-apis */
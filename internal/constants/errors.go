@@ -29,9 +29,17 @@ var (
 
 	ErrExternalResponse = "error getting response from external service"
 	ErrExternalRequest  = "error making request to external service"
+	ErrExternalBreaker  = "circuit breaker of external service is open"
+	ErrExternalStatus   = "external service responded with a failing status"
+	ErrExternalProvider = "unknown provider of external data"
 
 	ErrWriteResponse = "error writing response"
 
+	ErrRequestContentType  = "content type must be application/json"
+	ErrRequestTooLarge     = "request body too large"
+	ErrRequestEmptyBody    = "request body must not be empty"
+	ErrRequestTrailingData = "request body must contain a single JSON object"
+
 	ErrIDFromRequest = "error getting ID from request"
 	ErrIDRequired    = "ID is required for endpoints with path ending in {id}."
 	ErrIDInvalid     = "invalid ID provided"
@@ -41,8 +49,38 @@ var (
 
 	ErrNotificationsInvalidType  = "invalid event type provided"
 	ErrNotificationsGetDocFromDB = "error getting notification document from database"
+	ErrNotificationsTrigger      = "error triggering event: "
+
+	ErrEventsStreamingUnsupported = "streaming is not supported by the connection"
+	ErrEventsInvalidLastEventID   = "invalid Last-Event-ID provided"
+
+	ErrGraphQLSchema       = "error building GraphQL schema"
+	ErrGraphQLQueryMissing = "no GraphQL query provided"
+	ErrGraphQLVariables    = "invalid GraphQL variables provided"
 
 	ErrLoadingEnvFile = "error loading environment file"
+	ErrParsingEnvVar  = "error parsing environment variable"
+
+	ErrIdempotencyReadBody    = "error reading request body for idempotency check"
+	ErrIdempotencyKeyConflict = "idempotency key has already been used with a different request body"
+	ErrIdempotencyLookup      = "error looking up idempotency key"
+	ErrIdempotencyStore       = "error storing idempotency record"
+
+	ErrRegistrationForecastDays     = "forecastDays must be between 0 and 16"
+	ErrRegistrationWeatherSource    = "weatherLocation source must be centroid, capital or points"
+	ErrRegistrationWeatherPoints    = "weatherLocation points must be 1 to 10 valid coordinates, given only for the points source"
+	ErrRegistrationTarget           = "only one of isoCode, region, subregion and isoCodes can be given"
+	ErrRegistrationIsoCodes         = "isoCodes must be at most 50 codes"
+	ErrRegistrationUnits            = "units system must be metric, imperial or custom, with known units given only for custom"
+	ErrRegistrationPrecision        = "precision must be between 0 and 6"
+	ErrRegistrationComputed         = "computed must be at most 10 features with unique names, each with either a builtin of density, areaRank or rateChange, or an expression over numeric features"
+	ErrRegistrationComputedRegional = "computed features are only supported by dashboards of a single country"
+
+	ErrExpressionLength   = "expression must be at most 200 bytes"
+	ErrExpressionSyntax   = "invalid expression"
+	ErrExpressionVariable = "unknown variable in expression"
+	ErrExpressionDivision = "division by zero in expression"
+	ErrExpressionResult   = "expression is not a finite number"
 
 	ErrDashboardGetCountryData       = "error getting country data"
 	ErrDashboardGetCurrencyData      = "error getting currency data"
@@ -51,4 +89,33 @@ var (
 	ErrDashboardFilterByRegistration = "error filtering data by registration"
 	ErrDashboardCountryNotFound      = "country not found"
 	ErrDashboardCountryNotMatch      = "country does not match"
+	ErrDashboardNoCoordinates        = "country has no coordinates"
+	ErrDashboardNoCapitalCoordinates = "capital has no coordinates"
+	ErrDashboardNoCurrency           = "country has no currency"
+	ErrDashboardTimeout              = "timed out getting data from external services"
+	ErrDashboardLiveUpgrade          = "error upgrading to websocket connection"
+	ErrDashboardLiveWrite            = "error writing to websocket connection"
+	ErrDashboardRegistrationDeleted  = "registration has been deleted"
+	ErrDashboardGetRegionData        = "error getting the countries of the region"
+	ErrDashboardRegionNotFound       = "region not found"
+	ErrDashboardSnapshot             = "error storing dashboard snapshot"
+	ErrDashboardHistory              = "error getting dashboard history"
+	ErrDashboardHistoryTime          = "from and to must be RFC 3339 times or YYYY-MM-DD dates"
+	ErrDashboardHistoryRange         = "from must not be after to"
+	ErrDashboardUnits                = "units must be metric or imperial"
+	ErrDashboardAmount               = "amount must be a non-negative number"
+	ErrDashboardCompareTargets       = "either ids or iso must be given"
+	ErrDashboardCompareCount         = "2 to 10 different dashboards must be compared"
+	ErrDashboardCompareIsoCode       = "iso must be ISO 3166-1 alpha-2 or alpha-3 codes"
+	ErrDashboardComputed             = "error computing feature "
+	ErrDashboardNoArea               = "country has no area"
+	ErrDashboardNoRegion             = "country has no region"
+	ErrDashboardNoPreviousRate       = "no previous exchange rate to compare with"
+	ErrDashboardViewRefresh          = "refresh must be 0 or 10 to 86400 seconds"
+	ErrDashboardView                 = "error rendering dashboard view"
+	ErrDashboardBadgeFeature         = "feature must be given"
+	ErrDashboardBadgeLabel           = "label must be at most 50 characters"
+	ErrDashboardBadgeColor           = "colors must be named colors or hex codes"
+	ErrDashboardBadgeThresholds      = "thresholds must be comma-separated value:color pairs"
+	ErrDashboardBadge                = "error rendering dashboard badge"
 )
@@ -15,6 +15,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 )
 
 /*
@@ -30,6 +31,9 @@ var client *firestore.Client
 const (
 	DashboardCollection    = "dashboards"
 	NotificationCollection = "notifications"
+	IdempotencyCollection  = "idempotency_keys"
+	// SnapshotCollection is the subcollection of a dashboard registration holding its snapshots
+	SnapshotCollection = "snapshots"
 )
 
 type dummyStruct struct {
@@ -262,6 +266,184 @@ func DeleteDocument(id string, collection string) error {
 	return nil
 }
 
+/*
+AddSubDocument Structures data by the provided struct and adds it to the subcollection of the document with the
+provided ID.
+*/
+func AddSubDocument[T any](
+	data interface{}, parentID string, collection string, subcollection string,
+) error {
+	// Assert type to target struct
+	target, ok := data.(T)
+	if !ok {
+		return fmt.Errorf(constants.ErrDataNotMatchingTargetStruct)
+	}
+
+	parent, err := getDocumentByID(parentID, collection)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = parent.Ref.Collection(subcollection).Add(ctx, target)
+	return err
+}
+
+/*
+GetSubDocumentsBetween Returns the documents of the subcollection of the document with the provided ID whose time
+field is between from and to, both included, ordered by the time field.
+*/
+func GetSubDocumentsBetween[T any](
+	parentID string, collection string, subcollection string, field string, from time.Time, to time.Time,
+) ([]T, error) {
+	parent, err := getDocumentByID(parentID, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := parent.Ref.Collection(subcollection).
+		Where(field, ">=", from).
+		Where(field, "<=", to).
+		OrderBy(field, firestore.Asc).
+		Documents(ctx)
+	return documentsTo[T](iter)
+}
+
+/*
+GetLatestSubDocuments Returns at most limit documents of the subcollection of the document with the provided ID with
+the latest time field, newest first.
+*/
+func GetLatestSubDocuments[T any](
+	parentID string, collection string, subcollection string, field string, limit int,
+) ([]T, error) {
+	parent, err := getDocumentByID(parentID, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := parent.Ref.Collection(subcollection).OrderBy(field, firestore.Desc).Limit(limit).Documents(ctx)
+	return documentsTo[T](iter)
+}
+
+// documentsTo structures every document of the iterator by the provided struct, and stops the iterator.
+func documentsTo[T any](iter *firestore.DocumentIterator) ([]T, error) {
+	defer iter.Stop()
+
+	var allData []T
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			log.Printf("Failed to iterate: %v", err)
+			return nil, err
+		}
+
+		var data T
+		if err2 := doc.DataTo(&data); err2 != nil {
+			log.Println("Error unmarshalling document data:", err2)
+			return nil, err2
+		}
+		allData = append(allData, data)
+	}
+	return allData, nil
+}
+
+/*
+GetLatestSubDocument Returns the document of the subcollection of the document with the provided ID with the latest
+time field, or ErrDBDocNotFound if the subcollection is empty.
+*/
+func GetLatestSubDocument[T any](
+	parentID string, collection string, subcollection string, field string,
+) (T, error) {
+	var data T
+
+	parent, err := getDocumentByID(parentID, collection)
+	if err != nil {
+		return data, err
+	}
+
+	iter := parent.Ref.Collection(subcollection).OrderBy(field, firestore.Desc).Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err != nil {
+		if errors.Is(err, iterator.Done) {
+			return data, fmt.Errorf(constants.ErrDBDocNotFound)
+		}
+		return data, err
+	}
+
+	if err2 := doc.DataTo(&data); err2 != nil {
+		log.Println("Error unmarshalling document data:", err2)
+		return data, err2
+	}
+	return data, nil
+}
+
+/*
+PruneSubDocuments Deletes the documents of the subcollection of the document with the provided ID whose time field is
+before the provided time, and all but the newest keep documents. A zero time or a keep of zero disables that rule.
+*/
+func PruneSubDocuments(
+	parentID string, collection string, subcollection string, field string, before time.Time, keep int,
+) error {
+	parent, err := getDocumentByID(parentID, collection)
+	if err != nil {
+		return err
+	}
+	documents := parent.Ref.Collection(subcollection)
+
+	if !before.IsZero() {
+		err = deleteAll(documents.Where(field, "<", before).Documents(ctx))
+		if err != nil {
+			return err
+		}
+	}
+	if keep > 0 {
+		err = deleteAll(documents.OrderBy(field, firestore.Desc).Offset(keep).Documents(ctx))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+DeleteSubCollection Deletes all documents of the subcollection of the document with the provided ID, as Firestore
+does not delete them with the document.
+*/
+func DeleteSubCollection(parentID string, collection string, subcollection string) error {
+	parent, err := getDocumentByID(parentID, collection)
+	if err != nil {
+		return err
+	}
+	return deleteAll(parent.Ref.Collection(subcollection).Documents(ctx))
+}
+
+/*
+deleteAll Deletes every document of the iterator.
+*/
+func deleteAll(iter *firestore.DocumentIterator) error {
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		}
+		if err != nil {
+			log.Printf("Failed to iterate: %v", err)
+			return err
+		}
+
+		_, err = doc.Ref.Delete(ctx)
+		if err != nil {
+			log.Println("Error while deleting document:" + doc.Ref.ID)
+			return err
+		}
+	}
+}
+
 /*
 documentExists Checks if a document with the provided ID exists in the collection.
 */
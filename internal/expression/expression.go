@@ -0,0 +1,264 @@
+// Package expression parses and evaluates arithmetic expressions over named numeric variables, such as
+// "population / area". Expressions have numbers, variables, the operators +, -, * and / and parentheses, and nothing
+// else, so they are safe to evaluate on behalf of clients.
+package expression
+
+import (
+	"assignment-2/internal/constants"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"unicode"
+)
+
+// Expression is a parsed expression, which can be evaluated any number of times.
+type Expression struct {
+	root      node
+	variables []string
+}
+
+// node is a number, a variable or an operation of an expression.
+type node interface {
+	evaluate(variables map[string]float64) (float64, error)
+}
+
+type number float64
+
+type variable string
+
+// negation is the unary minus of its operand
+type negation struct {
+	operand node
+}
+
+// operation is a binary operation of +, -, * or /
+type operation struct {
+	operator    rune
+	left, right node
+}
+
+// Parse parses the given expression. Variables start with a letter, followed by letters, digits, underscores and dots,
+// such as "targetCurrencies.EUR". The returned error message is safe to show to the client.
+func Parse(source string) (*Expression, error) {
+	if len(source) > constants.MaxExpressionLength {
+		return nil, fmt.Errorf(constants.ErrExpressionLength)
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := parser{tokens: tokens}
+	root, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.position < len(p.tokens) {
+		return nil, p.unexpected()
+	}
+	return &Expression{root: root, variables: p.variables}, nil
+}
+
+// Variables returns the names of the variables of the expression, in the order they first appear.
+func (e *Expression) Variables() []string {
+	return e.variables
+}
+
+// Evaluate evaluates the expression with the given values of its variables. Missing variables, division by zero and
+// results that are not finite numbers are errors.
+func (e *Expression) Evaluate(variables map[string]float64) (float64, error) {
+	result, err := e.root.evaluate(variables)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, fmt.Errorf(constants.ErrExpressionResult)
+	}
+	return result, nil
+}
+
+func (n number) evaluate(map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+func (v variable) evaluate(variables map[string]float64) (float64, error) {
+	value, ok := variables[string(v)]
+	if !ok {
+		return 0, fmt.Errorf("%s: %s", constants.ErrExpressionVariable, string(v))
+	}
+	return value, nil
+}
+
+func (n negation) evaluate(variables map[string]float64) (float64, error) {
+	operand, err := n.operand.evaluate(variables)
+	return -operand, err
+}
+
+func (o operation) evaluate(variables map[string]float64) (float64, error) {
+	left, err := o.left.evaluate(variables)
+	if err != nil {
+		return 0, err
+	}
+	right, err := o.right.evaluate(variables)
+	if err != nil {
+		return 0, err
+	}
+
+	switch o.operator {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	default:
+		if right == 0 {
+			return 0, fmt.Errorf(constants.ErrExpressionDivision)
+		}
+		return left / right, nil
+	}
+}
+
+// token is a number, a variable, an operator or a parenthesis, and where it starts in the expression
+type token struct {
+	text     string
+	position int
+}
+
+// tokenize splits the given expression into tokens, leaving out whitespace.
+func tokenize(source string) ([]token, error) {
+	runes := []rune(source)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		start := i
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case isDigit(r) || r == '.':
+			for i < len(runes) && (isDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+		case isLetter(r):
+			for i < len(runes) && (isLetter(runes[i]) || isDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+		case r == '+' || r == '-' || r == '*' || r == '/' || r == '(' || r == ')':
+			i++
+		default:
+			return nil, fmt.Errorf("%s: unexpected %q at %d", constants.ErrExpressionSyntax, r, start+1)
+		}
+		tokens = append(tokens, token{text: string(runes[start:i]), position: start + 1})
+	}
+	return tokens, nil
+}
+
+// isDigit returns whether the given rune is an ASCII digit.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isLetter returns whether the given rune is an ASCII letter.
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// parser parses the tokens of an expression by recursive descent, where a sum is of products, and a product of factors.
+type parser struct {
+	tokens    []token
+	position  int
+	variables []string
+}
+
+// peek returns the text of the next token, or an empty string at the end of the expression.
+func (p *parser) peek() string {
+	if p.position < len(p.tokens) {
+		return p.tokens[p.position].text
+	}
+	return ""
+}
+
+// unexpected returns the error for the next token, or for the end of the expression.
+func (p *parser) unexpected() error {
+	if p.position < len(p.tokens) {
+		next := p.tokens[p.position]
+		return fmt.Errorf("%s: unexpected %q at %d", constants.ErrExpressionSyntax, next.text, next.position)
+	}
+	return fmt.Errorf("%s: unexpected end", constants.ErrExpressionSyntax)
+}
+
+// sum parses products separated by + and -.
+func (p *parser) sum() (node, error) {
+	left, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for operator := p.peek(); operator == "+" || operator == "-"; operator = p.peek() {
+		p.position++
+		right, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		left = operation{operator: rune(operator[0]), left: left, right: right}
+	}
+	return left, nil
+}
+
+// product parses factors separated by * and /.
+func (p *parser) product() (node, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for operator := p.peek(); operator == "*" || operator == "/"; operator = p.peek() {
+		p.position++
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = operation{operator: rune(operator[0]), left: left, right: right}
+	}
+	return left, nil
+}
+
+// factor parses a number, a variable, a sum in parentheses, or a factor with a sign.
+func (p *parser) factor() (node, error) {
+	text := p.peek()
+	switch {
+	case text == "":
+		return nil, p.unexpected()
+	case text == "-" || text == "+":
+		p.position++
+		operand, err := p.factor()
+		if err != nil || text == "+" {
+			return operand, err
+		}
+		return negation{operand: operand}, nil
+	case text == "(":
+		p.position++
+		inner, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, p.unexpected()
+		}
+		p.position++
+		return inner, nil
+	case isLetter(rune(text[0])):
+		p.position++
+		name := variable(text)
+		if !slices.Contains(p.variables, text) {
+			p.variables = append(p.variables, text)
+		}
+		return name, nil
+	default:
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, p.unexpected()
+		}
+		p.position++
+		return number(value), nil
+	}
+}
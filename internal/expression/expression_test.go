@@ -0,0 +1,96 @@
+package expression
+
+import (
+	"assignment-2/internal/constants"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpression_Evaluate(t *testing.T) {
+	variables := map[string]float64{
+		"population":           5000,
+		"area":                 250,
+		"temperature":          4.5,
+		"targetCurrencies.EUR": 0.1,
+		"reference.area":       0,
+		"largest":              math.MaxFloat64,
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       float64
+		wantErr    string
+	}{
+		{name: "Number", expression: "42", want: 42},
+		{name: "Fraction", expression: ".5", want: 0.5},
+		{name: "Variable", expression: "temperature", want: 4.5},
+		{name: "Quotient", expression: "population / area", want: 20},
+		{name: "Precedence", expression: "1 + 2 * 3 - 4 / 2", want: 5},
+		{name: "Left associative", expression: "100 / 10 / 5 - 1 - 1", want: 0},
+		{name: "Parentheses", expression: "(1 + 2) * (3 - 4)", want: -3},
+		{name: "Signs", expression: "-temperature * -2 + +1", want: 10},
+		{name: "Dotted variable", expression: "1000 * targetCurrencies.EUR", want: 100},
+		{name: "Missing variable", expression: "population / gini.value", wantErr: constants.ErrExpressionVariable},
+		{name: "Division by zero", expression: "area / reference.area", wantErr: constants.ErrExpressionDivision},
+		{name: "Not finite", expression: "largest * 10", wantErr: constants.ErrExpressionResult},
+		{name: "Too long", expression: "1" + strings.Repeat(" + 1", 50), wantErr: constants.ErrExpressionLength},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				parsed, err := Parse(tt.expression)
+				if err == nil {
+					var got float64
+					got, err = parsed.Evaluate(variables)
+					if err == nil && got != tt.want {
+						t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+					}
+				}
+				if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)) {
+					t.Errorf("Evaluate() error = %v, want %q", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    string
+	}{
+		{name: "Empty", expression: "", wantErr: "invalid expression: unexpected end"},
+		{name: "Missing operand", expression: "population /", wantErr: "invalid expression: unexpected end"},
+		{name: "Unbalanced", expression: "(1 + 2", wantErr: "invalid expression: unexpected end"},
+		{name: "Extra parenthesis", expression: "1 + 2)", wantErr: `invalid expression: unexpected ")" at 6`},
+		{name: "Adjacent operands", expression: "population area", wantErr: `invalid expression: unexpected "area" at 12`},
+		{name: "Function call", expression: "sqrt(area)", wantErr: `invalid expression: unexpected "(" at 5`},
+		{name: "Unknown operator", expression: "area ^ 2", wantErr: `invalid expression: unexpected '^' at 6`},
+		{name: "Invalid number", expression: "1.2.3", wantErr: `invalid expression: unexpected "1.2.3" at 1`},
+		{name: "Exponent", expression: "1e3", wantErr: `invalid expression: unexpected "e3" at 2`},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := Parse(tt.expression)
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestExpression_Variables(t *testing.T) {
+	parsed, err := Parse("(population - reference.population) / population * 100")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if want := []string{"population", "reference.population"}; !reflect.DeepEqual(parsed.Variables(), want) {
+		t.Errorf("Variables() = %v, want %v", parsed.Variables(), want)
+	}
+}
@@ -1,5 +1,7 @@
 package inhouse
 
+import "time"
+
 type Endpoint struct {
 	Path        string   `json:"path"`
 	Methods     []string `json:"methods"`
@@ -10,3 +12,33 @@ type Coordinates struct {
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
 }
+
+// Flag is the flag of a country, as an emoji and as images.
+type Flag struct {
+	Emoji string `json:"emoji"`
+	Png   string `json:"png"`
+	Svg   string `json:"svg"`
+}
+
+// Gini is the Gini index of income inequality of a country, for the year it was last measured.
+type Gini struct {
+	Year  int     `json:"year"`
+	Value float64 `json:"value"`
+}
+
+// DashboardSnapshot is a populated dashboard as it was retrieved, kept for the history of the dashboard. The dashboard
+// is stored as JSON, as it is returned to the client.
+type DashboardSnapshot struct {
+	LastRetrieval time.Time `json:"lastRetrieval"`
+	Dashboard     []byte    `json:"dashboard"`
+}
+
+// IdempotencyRecord is a stored response for a request made with an Idempotency-Key header.
+type IdempotencyRecord struct {
+	ID          string    `json:"id"`
+	RequestHash string    `json:"requestHash"`
+	StatusCode  int       `json:"statusCode"`
+	ContentType string    `json:"contentType"`
+	Body        []byte    `json:"body"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
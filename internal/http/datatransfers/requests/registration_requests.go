@@ -1,21 +1,158 @@
 package requests
 
-import "time"
+import (
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"time"
+)
 
+// Where the weather of a dashboard can be measured
+const (
+	WeatherAtCentroid = "centroid"
+	WeatherAtCapital  = "capital"
+	WeatherAtPoints   = "points"
+)
+
+// ImplementedWeatherSources are the implemented sources of the weather location
+var ImplementedWeatherSources = []string{WeatherAtCentroid, WeatherAtCapital, WeatherAtPoints}
+
+// Unit systems of a dashboard
+const (
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+	UnitsCustom   = "custom"
+)
+
+// ImplementedUnitSystems are the implemented unit systems of a dashboard
+var ImplementedUnitSystems = []string{UnitsMetric, UnitsImperial, UnitsCustom}
+
+// Units of the quantities of a dashboard
+const (
+	UnitCelsius           = "celsius"
+	UnitFahrenheit        = "fahrenheit"
+	UnitMillimetres       = "mm"
+	UnitInches            = "in"
+	UnitKilometresPerHour = "km/h"
+	UnitMetresPerSecond   = "m/s"
+	UnitMilesPerHour      = "mph"
+	UnitKnots             = "kn"
+	UnitSquareKilometres  = "km2"
+	UnitSquareMiles       = "mi2"
+)
+
+// Implemented units of each quantity, of which the first is the metric and the last the imperial unit
+var (
+	TemperatureUnits   = []string{UnitCelsius, UnitFahrenheit}
+	PrecipitationUnits = []string{UnitMillimetres, UnitInches}
+	WindSpeedUnits     = []string{UnitKilometresPerHour, UnitMetresPerSecond, UnitKnots, UnitMilesPerHour}
+	AreaUnits          = []string{UnitSquareKilometres, UnitSquareMiles}
+)
+
+// Builtin computed features of a dashboard
+const (
+	ComputedDensity    = "density"
+	ComputedAreaRank   = "areaRank"
+	ComputedRateChange = "rateChange"
+)
+
+// ImplementedComputedBuiltins are the implemented builtin computed features
+var ImplementedComputedBuiltins = []string{ComputedDensity, ComputedAreaRank, ComputedRateChange}
+
+// ComputedVariables are the numeric features the expressions of computed features can use. They can also use the
+// exchange rates to and from each target currency, such as targetCurrencies.EUR and inverseRates.EUR, the computed
+// features declared before them, and the features of their reference country, such as reference.temperature.
+var ComputedVariables = []string{
+	"temperature", "precipitation", "temperatureMin", "temperatureMax", "windSpeed", "windGusts", "humidity",
+	"cloudCover", "uvIndex", "population", "area", "coordinates.latitude", "coordinates.longitude", "gini.value",
+	"gini.year",
+}
+
+// Prefixes of the variables of computed features that are exchange rates or features of the reference country
+const (
+	TargetCurrenciesPrefix = "targetCurrencies."
+	InverseRatesPrefix     = "inverseRates."
+	ReferencePrefix        = "reference."
+)
+
+// DashboardConfig is a registration of a dashboard. A dashboard targets the country with the ISO code, or is a
+// regional dashboard of the countries of a region, of a subregion or with the ISO codes, in which case the country is
+// the name of the group. The quantities of the dashboard are in the registered units, and rounded to the precision if
+// it is set. The computed features are evaluated in order, after the other features.
 type DashboardConfig struct {
-	ID         string         `json:"id"`
-	Country    string         `json:"country"`
-	IsoCode    string         `json:"isoCode"`
-	Features   ConfigFeatures `json:"features"`
-	LastChange time.Time      `json:"lastChange"`
+	ID         string            `json:"id"`
+	Country    string            `json:"country"`
+	IsoCode    string            `json:"isoCode"`
+	Region     string            `json:"region,omitempty"`
+	Subregion  string            `json:"subregion,omitempty"`
+	IsoCodes   []string          `json:"isoCodes,omitempty"`
+	Features   ConfigFeatures    `json:"features"`
+	Units      Units             `json:"units"`
+	Precision  *int              `json:"precision,omitempty"`
+	Computed   []ComputedFeature `json:"computed,omitempty"`
+	LastChange time.Time         `json:"lastChange"`
+}
+
+// ComputedFeature is a feature of a dashboard computed from its other features, by either a builtin or an arithmetic
+// expression over numeric features. The currency is the target currency of the rateChange builtin, and the reference
+// is the ISO code of a country whose features the expression can use.
+type ComputedFeature struct {
+	Name       string `json:"name"`
+	Builtin    string `json:"builtin,omitempty"`
+	Expression string `json:"expression,omitempty"`
+	Currency   string `json:"currency,omitempty"`
+	Reference  string `json:"reference,omitempty"`
+}
+
+// Units is the unit system of a dashboard, metric by default. The unit of each quantity is that of the system, unless
+// it is given, which is only allowed for the custom system. A custom system is metric but for the given units.
+type Units struct {
+	System        string `json:"system"`
+	Temperature   string `json:"temperature,omitempty"`
+	Precipitation string `json:"precipitation,omitempty"`
+	WindSpeed     string `json:"windSpeed,omitempty"`
+	Area          string `json:"area,omitempty"`
+}
+
+// IsRegional returns whether the dashboard is of several countries instead of one.
+func (c DashboardConfig) IsRegional() bool {
+	return c.Region != "" || c.Subregion != "" || len(c.IsoCodes) > 0
 }
 
 type ConfigFeatures struct {
-	Temperature      bool     `json:"temperature"`
-	Precipitation    bool     `json:"precipitation"`
-	Capital          bool     `json:"capital"`
-	Coordinates      bool     `json:"coordinates"`
-	Population       bool     `json:"population"`
-	Area             bool     `json:"area"`
-	TargetCurrencies []string `json:"targetCurrencies"`
+	Temperature      bool            `json:"temperature"`
+	Precipitation    bool            `json:"precipitation"`
+	Capital          bool            `json:"capital"`
+	Coordinates      bool            `json:"coordinates"`
+	Population       bool            `json:"population"`
+	Area             bool            `json:"area"`
+	TargetCurrencies []string        `json:"targetCurrencies"`
+	TemperatureMin   bool            `json:"temperatureMin"`
+	TemperatureMax   bool            `json:"temperatureMax"`
+	WindSpeed        bool            `json:"windSpeed"`
+	WindGusts        bool            `json:"windGusts"`
+	Humidity         bool            `json:"humidity"`
+	CloudCover       bool            `json:"cloudCover"`
+	UvIndex          bool            `json:"uvIndex"`
+	ForecastDays     int             `json:"forecastDays"`
+	WeatherLocation  WeatherLocation `json:"weatherLocation"`
+	PrimaryCapital   string          `json:"primaryCapital"`
+	PrimaryCurrency  string          `json:"primaryCurrency"`
+	Languages        bool            `json:"languages"`
+	Borders          bool            `json:"borders"`
+	Region           bool            `json:"region"`
+	Flag             bool            `json:"flag"`
+	Timezones        bool            `json:"timezones"`
+	CallingCodes     bool            `json:"callingCodes"`
+	DrivingSide      bool            `json:"drivingSide"`
+	Gini             bool            `json:"gini"`
+	// BaseCurrency is the currency the exchange rates of a regional dashboard are from
+	BaseCurrency string `json:"baseCurrency"`
+}
+
+// WeatherLocation is where the weather of a dashboard is measured. The source is the centroid of the country by
+// default, its capital, or the given points. The weather at several points is averaged, and also reported for each
+// point if separate is set.
+type WeatherLocation struct {
+	Source   string                `json:"source"`
+	Points   []inhouse.Coordinates `json:"points,omitempty"`
+	Separate bool                  `json:"separate"`
 }
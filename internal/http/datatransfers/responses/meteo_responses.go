@@ -9,11 +9,22 @@ type MeteoForecastResponse struct {
 	Timezone             string  `json:"timezone"`
 	TimezoneAbbreviation string  `json:"timezone_abbreviation"`
 	Hourly               struct {
-		Time          []interface{} `json:"time"`
-		Temperature2M []float64     `json:"temperature_2m"`
-		Precipitation []float64     `json:"precipitation"`
+		Time               []string   `json:"time"`
+		Temperature2M      []*float64 `json:"temperature_2m"`
+		Precipitation      []*float64 `json:"precipitation"`
+		RelativeHumidity2M []*float64 `json:"relative_humidity_2m"`
+		CloudCover         []*float64 `json:"cloud_cover"`
+		WindSpeed10M       []*float64 `json:"wind_speed_10m"`
+		WindGusts10M       []*float64 `json:"wind_gusts_10m"`
+		UvIndex            []*float64 `json:"uv_index"`
 	} `json:"hourly,omitempty"`
 	HourlyUnits struct {
-		Temperature2M string `json:"temperature_2m"`
+		Temperature2M      string `json:"temperature_2m"`
+		Precipitation      string `json:"precipitation"`
+		RelativeHumidity2M string `json:"relative_humidity_2m"`
+		CloudCover         string `json:"cloud_cover"`
+		WindSpeed10M       string `json:"wind_speed_10m"`
+		WindGusts10M       string `json:"wind_gusts_10m"`
+		UvIndex            string `json:"uv_index"`
 	} `json:"hourly_units,omitempty"`
 }
@@ -5,12 +5,38 @@ type ResponseFromRestcountries struct {
 		Common   string `json:"common"`
 		Official string `json:"official"`
 	} `json:"name"`
-	Cca2       string              `json:"cca2"`
-	Currencies map[string]Currency `json:"currencies"`
-	Capital    []string            `json:"capital"`
-	Latlng     []float64           `json:"latlng"`
-	Area       float64             `json:"area"`
-	Population int                 `json:"population"`
+	Cca2        string              `json:"cca2"`
+	Cca3        string              `json:"cca3"`
+	Currencies  map[string]Currency `json:"currencies"`
+	Capital     []string            `json:"capital"`
+	CapitalInfo struct {
+		Latlng []float64 `json:"latlng"`
+	} `json:"capitalInfo"`
+	Latlng     []float64         `json:"latlng"`
+	Area       float64           `json:"area"`
+	Population int               `json:"population"`
+	Timezones  []string          `json:"timezones"`
+	Languages  map[string]string `json:"languages"`
+	Borders    []string          `json:"borders"`
+	Region     string            `json:"region"`
+	Subregion  string            `json:"subregion"`
+	Flag       string            `json:"flag"`
+	Flags      struct {
+		Png string `json:"png"`
+		Svg string `json:"svg"`
+	} `json:"flags"`
+	Idd struct {
+		Root     string   `json:"root"`
+		Suffixes []string `json:"suffixes"`
+	} `json:"idd"`
+	Car struct {
+		Side string `json:"side"`
+	} `json:"car"`
+	Gini         map[string]float64 `json:"gini"`
+	Translations map[string]struct {
+		Official string `json:"official"`
+		Common   string `json:"common"`
+	} `json:"translations"`
 }
 
 type Currency struct {
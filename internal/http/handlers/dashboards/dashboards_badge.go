@@ -0,0 +1,410 @@
+package dashboards
+
+import (
+	"assignment-2/internal/cache"
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	utils2 "assignment-2/internal/utils"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// badgeTemplate renders a badge as a shields-style SVG image
+var badgeTemplate = template.Must(template.ParseFS(viewFiles, "view/badge.svg"))
+
+// Name of the cache of the dashboards of badges
+const badgeSource = "badges"
+
+// badgeCache caches the dashboards of badges by registration ID and units, as badges are embedded in pages that may be
+// viewed far more often than the data changes
+var badgeCache = cache.New[Dashboard](badgeSource, utils2.GetBadgeCacheTTL())
+
+// badgeColors are the named colors of badges, as those of shields.io
+var badgeColors = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellowgreen": "#a4a61d",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"grey":        "#555",
+	"lightgrey":   "#9f9f9f",
+}
+
+// hexColor matches the hex codes of colors, without the leading #
+var hexColor = regexp.MustCompile(`^([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Default colors of badges, and the color of a badge whose feature the dashboard does not have
+const (
+	defaultBadgeLabelColor = "#555"
+	defaultBadgeColor      = "#007ec6"
+	missingBadgeColor      = "#9f9f9f"
+)
+
+// Value of a badge whose feature the dashboard does not have
+const missingBadgeValue = "n/a"
+
+// Implemented methods for the badge endpoint
+var implementedMethodsBadge = []string{
+	http.MethodGet,
+}
+
+// Endpoint for badges of dashboards
+var dashboardsBadgeEndpoint = inhouse.Endpoint{
+	Path:    constants.DashboardsPath + "{id}/badge.svg",
+	Methods: implementedMethodsBadge,
+	Description: "Endpoint for badges of dashboards. Renders a feature of the cached dashboard as an SVG badge, " +
+		"with an optional label, color, label color and thresholds coloring the badge by the value of the feature.",
+}
+
+// badgeOptions are the parameters of a badge request.
+type badgeOptions struct {
+	Feature    string
+	Label      string
+	Color      string
+	LabelColor string
+	Thresholds []badgeThreshold
+	Units      string
+}
+
+// badgeThreshold colors badges whose value is at least the threshold, unless a higher threshold applies.
+type badgeThreshold struct {
+	Value float64
+	Color string
+}
+
+// badge is the data a badge is rendered from, with the widths and text positions of its two parts.
+type badge struct {
+	Label, Value      string
+	LabelColor, Color string
+	Width             int
+	LabelWidth        int
+	ValueWidth        int
+	LabelX, ValueX    float64
+}
+
+// BadgeHandler handles the /dashboard/v1/dashboards/{id}/badge.svg path.
+// It currently only supports GET requests
+func BadgeHandler(w http.ResponseWriter, r *http.Request) {
+	// Switch on the HTTP request method
+	switch r.Method {
+	case http.MethodGet:
+		handleDashboardsBadgeRequest(w, r)
+
+	default:
+		// If the method is not implemented, return an error with the allowed methods
+		http.Error(
+			w, fmt.Sprintf(
+				"REST Method '%s' not supported. Currently only '%v' are supported.", r.Method,
+				implementedMethodsBadge,
+			), http.StatusNotImplemented,
+		)
+		return
+	}
+}
+
+// handleDashboardsBadgeRequest handles the GET request for the /dashboard/v1/dashboards/{id}/badge.svg path.
+// It is used to embed a feature of a dashboard as an image, which is cached by the client until the dashboard expires.
+func handleDashboardsBadgeRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := utils2.GetIDFromRequest(r)
+	if err != nil {
+		http.Error(w, constants.ErrIDInvalid, http.StatusBadRequest)
+		return
+	}
+
+	options, err := parseBadgeOptions(r.URL.Query())
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dashboard, err := getBadgeDashboard(r.Context(), id, options.Units)
+	if err != nil {
+		http.Error(w, err.Error(), badgeErrorStatus(err))
+		return
+	}
+
+	var image bytes.Buffer
+	if err := badgeTemplate.Execute(&image, newBadge(dashboard, options)); err != nil {
+		log.Println(constants.ErrDashboardBadge + err.Error())
+		http.Error(w, constants.ErrDashboardBadge, http.StatusInternalServerError)
+		return
+	}
+
+	// Clients may cache the badge until the cached dashboard expires, and revalidate it by its ETag after that
+	maxAge := max(utils2.GetBadgeCacheTTL()-time.Since(dashboard.LastRetrieval), 0)
+	sum := sha256.Sum256(image.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", dashboard.LastRetrieval.UTC().Format(http.TimeFormat))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("content-type", "image/svg+xml; charset=utf-8")
+	_, err = w.Write(image.Bytes())
+	if err != nil {
+		log.Println(constants.ErrWriteResponse + err.Error())
+		http.Error(w, constants.ErrWriteResponse, http.StatusInternalServerError)
+		return
+	}
+}
+
+// badgeErrorStatus returns the status code of an error of getting the dashboard of a badge.
+func badgeErrorStatus(err error) int {
+	switch err.Error() {
+	case constants.ErrIDInvalid:
+		return http.StatusBadRequest
+	case constants.ErrDBDocNotFound:
+		return http.StatusNotFound
+	case constants.ErrDashboardTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// parseBadgeOptions parses the parameters of a badge request. The feature is required, and the colors are named
+// colors or hex codes without the leading #. The returned error message is safe to show to the client.
+func parseBadgeOptions(query url.Values) (badgeOptions, error) {
+	options := badgeOptions{
+		Feature:    strings.TrimSpace(query.Get("feature")),
+		Label:      query.Get("label"),
+		Color:      defaultBadgeColor,
+		LabelColor: defaultBadgeLabelColor,
+		Units:      query.Get("units"),
+	}
+	if options.Feature == "" {
+		return badgeOptions{}, fmt.Errorf(constants.ErrDashboardBadgeFeature)
+	}
+	if utf8.RuneCountInString(options.Label) > constants.MaxBadgeLabelLength {
+		return badgeOptions{}, fmt.Errorf(constants.ErrDashboardBadgeLabel)
+	}
+	if options.Units != "" && options.Units != requests.UnitsMetric && options.Units != requests.UnitsImperial {
+		return badgeOptions{}, fmt.Errorf(constants.ErrDashboardUnits)
+	}
+
+	var ok bool
+	if value := query.Get("color"); value != "" {
+		if options.Color, ok = parseBadgeColor(value); !ok {
+			return badgeOptions{}, fmt.Errorf(constants.ErrDashboardBadgeColor)
+		}
+	}
+	if value := query.Get("labelColor"); value != "" {
+		if options.LabelColor, ok = parseBadgeColor(value); !ok {
+			return badgeOptions{}, fmt.Errorf(constants.ErrDashboardBadgeColor)
+		}
+	}
+
+	for _, pair := range parseList(query.Get("thresholds")) {
+		value, color, found := strings.Cut(pair, ":")
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !found || err != nil || math.IsNaN(threshold) || math.IsInf(threshold, 0) {
+			return badgeOptions{}, fmt.Errorf(constants.ErrDashboardBadgeThresholds)
+		}
+		if color, ok = parseBadgeColor(strings.TrimSpace(color)); !ok {
+			return badgeOptions{}, fmt.Errorf(constants.ErrDashboardBadgeThresholds)
+		}
+		options.Thresholds = append(options.Thresholds, badgeThreshold{Value: threshold, Color: color})
+	}
+	slices.SortStableFunc(
+		options.Thresholds, func(a, b badgeThreshold) int {
+			return cmp.Compare(a.Value, b.Value)
+		},
+	)
+	return options, nil
+}
+
+// parseBadgeColor returns the hex code of a named color or of a hex code without the leading #, and whether it is
+// either.
+func parseBadgeColor(value string) (string, bool) {
+	if color, ok := badgeColors[strings.ToLower(value)]; ok {
+		return color, true
+	}
+	if hexColor.MatchString(value) {
+		return "#" + strings.ToLower(value), true
+	}
+	return "", false
+}
+
+// getBadgeDashboard returns the dashboard of the registration with the given ID in the given unit system, or in its
+// registered units if none is given, from the cache of badges. Badges are neither recorded as snapshots nor trigger
+// events. The returned error message is safe to show to the client.
+func getBadgeDashboard(ctx context.Context, id string, units string) (Dashboard, error) {
+	return badgeCache.Get(
+		ctx,
+		id+","+units,
+		func() (Dashboard, error) {
+			dashboardConfig, err := db.GetDocument[requests.DashboardConfig](id, db.DashboardCollection)
+			if err != nil {
+				log.Println(constants.ErrDBGetDoc + err.Error())
+				switch err.Error() {
+				case constants.ErrIDInvalid, constants.ErrDBDocNotFound:
+					return Dashboard{}, err
+				default:
+					return Dashboard{}, fmt.Errorf(constants.ErrDBGetDoc)
+				}
+			}
+			if units != "" {
+				dashboardConfig.Units = requests.Units{System: units}
+			}
+
+			return buildDashboard(context.Background(), dashboardConfig)
+		},
+	)
+}
+
+// newBadge returns the badge of a feature of the dashboard, by its path as in the dashboard history, such as
+// "temperature", "targetCurrencies.EUR" or "aggregates.population". A badge without a label is labelled by the
+// currencies of an exchange rate, or by the country and the feature. Numeric values are colored by the highest
+// threshold they reach, and a feature the dashboard does not have is shown as not available.
+func newBadge(dashboard Dashboard, options badgeOptions) badge {
+	value, numeric, ok := badgeValue(dashboard, options.Feature)
+
+	b := badge{Label: options.Label, Value: value, LabelColor: options.LabelColor, Color: options.Color}
+	if b.Label == "" {
+		b.Label = badgeLabel(dashboard, options.Feature)
+	}
+	switch {
+	case !ok:
+		b.Value = missingBadgeValue
+		b.Color = missingBadgeColor
+	case numeric != nil:
+		for _, threshold := range options.Thresholds {
+			if *numeric >= threshold.Value {
+				b.Color = threshold.Color
+			}
+		}
+	}
+
+	b.LabelWidth = badgeTextWidth(b.Label) + 10
+	b.ValueWidth = badgeTextWidth(b.Value) + 10
+	b.Width = b.LabelWidth + b.ValueWidth
+	b.LabelX = float64(b.LabelWidth) / 2
+	b.ValueX = float64(b.LabelWidth) + float64(b.ValueWidth)/2
+	return b
+}
+
+// badgeValue returns the value of the feature of the dashboard with the given path, formatted with its unit, the
+// number if it is numeric, and whether the dashboard has the feature.
+func badgeValue(dashboard Dashboard, feature string) (string, *float64, bool) {
+	marshaled, err := json.Marshal(dashboard)
+	if err != nil {
+		log.Println(constants.ErrJsonMarshal + err.Error())
+		return "", nil, false
+	}
+
+	numbers, units, err := numericFeatures(marshaled)
+	if err != nil {
+		log.Println(constants.ErrJsonUnmarshal + err.Error())
+		return "", nil, false
+	}
+	if number, ok := numbers[feature]; ok {
+		return formatBadgeNumber(number) + badgeUnit(feature, units), &number, true
+	}
+
+	// Other values are formatted as in the HTML view
+	var fields struct {
+		Features   map[string]any `json:"features"`
+		Aggregates map[string]any `json:"aggregates"`
+	}
+	if err := json.Unmarshal(marshaled, &fields); err != nil {
+		log.Println(constants.ErrJsonUnmarshal + err.Error())
+		return "", nil, false
+	}
+	values := make(map[string]string)
+	flattenValues("", fields.Features, values)
+	flattenValues("aggregates", fields.Aggregates, values)
+	value, ok := values[feature]
+	return value, nil, ok
+}
+
+// formatBadgeNumber formats a number for a badge, with four significant digits, but all digits of whole numbers.
+func formatBadgeNumber(value float64) string {
+	if value == 0 || math.Abs(value) >= 1000 {
+		return strconv.FormatFloat(math.Round(value), 'f', -1, 64)
+	}
+	digits := 3 - int(math.Floor(math.Log10(math.Abs(value))))
+	pow := math.Pow10(digits)
+	return strconv.FormatFloat(math.Round(value*pow)/pow, 'f', -1, 64)
+}
+
+// badgeUnit returns the unit a numeric feature of a dashboard in the given units is shown with on a badge, if it has
+// one.
+func badgeUnit(feature string, units DashboardUnits) string {
+	switch strings.TrimPrefix(feature, "aggregates.") {
+	case "temperature", "temperatureMin", "temperatureMax":
+		if units.Temperature == requests.UnitFahrenheit {
+			return "°F"
+		}
+		return "°C"
+	case "precipitation":
+		return " " + units.Precipitation
+	case "windSpeed", "windGusts":
+		return " " + units.WindSpeed
+	case "area":
+		return " " + strings.TrimSuffix(units.Area, "2") + "²"
+	case "humidity", "cloudCover":
+		return "%"
+	default:
+		return ""
+	}
+}
+
+// badgeLabel returns the default label of a badge of a feature: the currencies of an exchange rate, such as
+// "NOK→EUR", or the country and the feature.
+func badgeLabel(dashboard Dashboard, feature string) string {
+	primary := dashboard.Features.Currency.Code
+	if dashboard.Aggregates != nil {
+		primary = dashboard.Aggregates.BaseCurrency
+	}
+	for _, prefix := range []string{requests.TargetCurrenciesPrefix, "aggregates.exchangeRates."} {
+		if currency, ok := strings.CutPrefix(feature, prefix); ok && primary != "" {
+			return primary + "→" + currency
+		}
+	}
+	if currency, ok := strings.CutPrefix(feature, requests.InverseRatesPrefix); ok && primary != "" {
+		return currency + "→" + primary
+	}
+	return dashboard.Country + " " + strings.TrimPrefix(feature, "aggregates.")
+}
+
+// badgeTextWidth estimates the width in pixels of a text on a badge, in 11px Verdana.
+func badgeTextWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune("fijlrtI.,:;!|' ()[]", r):
+			width += 4
+		case strings.ContainsRune("mwMW@%", r):
+			width += 11
+		case r >= 'A' && r <= 'Z':
+			width += 8
+		default:
+			width += 7
+		}
+	}
+	return width
+}
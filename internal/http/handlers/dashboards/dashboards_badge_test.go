@@ -0,0 +1,332 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/http/datatransfers/responses"
+	"bytes"
+	"encoding/xml"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBadgeHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		statusCode int
+		wantBody   string
+	}{
+		{
+			name:       "NegativeTestBadgeHandler",
+			method:     http.MethodPost,
+			target:     "/?id=1&feature=temperature",
+			statusCode: http.StatusNotImplemented,
+		},
+		{
+			name:       "NoIDTestBadgeHandler",
+			method:     http.MethodGet,
+			target:     "/?feature=temperature",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrIDInvalid,
+		},
+		{
+			name:       "NoFeatureTestBadgeHandler",
+			method:     http.MethodGet,
+			target:     "/?id=1",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardBadgeFeature,
+		},
+		{
+			name:       "InvalidColorTestBadgeHandler",
+			method:     http.MethodGet,
+			target:     "/?id=1&feature=temperature&color=%23ff0000",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardBadgeColor,
+		},
+		{
+			name:       "InvalidThresholdsTestBadgeHandler",
+			method:     http.MethodGet,
+			target:     "/?id=1&feature=temperature&thresholds=0:blue,warm:red",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardBadgeThresholds,
+		},
+	}
+
+	// Run the tests
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Create a mock request
+				req := httptest.NewRequest(tt.method, tt.target, nil)
+
+				// Create a mock response recorder
+				w := httptest.NewRecorder()
+
+				// Call the handler
+				BadgeHandler(w, req)
+
+				// Check if the status code matches expected
+				if w.Code != tt.statusCode {
+					log.Println("Testing: ", tt.name)
+					t.Errorf(
+						"handler returned wrong status code: got %v want %v",
+						w.Code, tt.statusCode,
+					)
+				}
+				if !strings.Contains(w.Body.String(), tt.wantBody) {
+					t.Errorf("handler returned body %q, want %q", w.Body.String(), tt.wantBody)
+				}
+			},
+		)
+	}
+}
+
+func Test_parseBadgeOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   url.Values
+		want    badgeOptions
+		wantErr string
+	}{
+		{
+			name:  "Defaults",
+			query: url.Values{"feature": {"temperature"}},
+			want: badgeOptions{
+				Feature: "temperature", Color: defaultBadgeColor, LabelColor: defaultBadgeLabelColor,
+			},
+		},
+		{
+			name: "Colors and thresholds",
+			query: url.Values{
+				"feature":    {"temperature"},
+				"label":      {"Oslo"},
+				"color":      {"Green"},
+				"labelColor": {"1F2328"},
+				"thresholds": {"25:red, -5:blue,10:fe7d37"},
+				"units":      {requests.UnitsImperial},
+			},
+			want: badgeOptions{
+				Feature:    "temperature",
+				Label:      "Oslo",
+				Color:      "#97ca00",
+				LabelColor: "#1f2328",
+				Thresholds: []badgeThreshold{{Value: -5, Color: "#007ec6"}, {Value: 10, Color: "#fe7d37"}, {Value: 25, Color: "#e05d44"}},
+				Units:      requests.UnitsImperial,
+			},
+		},
+		{
+			name:    "Too long label",
+			query:   url.Values{"feature": {"temperature"}, "label": {strings.Repeat("a", 51)}},
+			wantErr: constants.ErrDashboardBadgeLabel,
+		},
+		{
+			name:    "Invalid label color",
+			query:   url.Values{"feature": {"temperature"}, "labelColor": {"ff00"}},
+			wantErr: constants.ErrDashboardBadgeColor,
+		},
+		{
+			name:    "Threshold without a color",
+			query:   url.Values{"feature": {"temperature"}, "thresholds": {"10"}},
+			wantErr: constants.ErrDashboardBadgeThresholds,
+		},
+		{
+			name:    "Threshold with an unknown color",
+			query:   url.Values{"feature": {"temperature"}, "thresholds": {"10:pink"}},
+			wantErr: constants.ErrDashboardBadgeThresholds,
+		},
+		{
+			name:    "Invalid units",
+			query:   url.Values{"feature": {"temperature"}, "units": {"kelvin"}},
+			wantErr: constants.ErrDashboardUnits,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := parseBadgeOptions(tt.query)
+				if err != nil || tt.wantErr != "" {
+					if err == nil || err.Error() != tt.wantErr {
+						t.Errorf("parseBadgeOptions() error = %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("parseBadgeOptions() = %+v, want %+v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_newBadge(t *testing.T) {
+	units := resolveUnits(requests.Units{System: requests.UnitsImperial})
+	norway := Dashboard{
+		Country: "Norway",
+		IsoCode: "NO",
+		Features: DashboardFeatures{
+			Temperature:      float(39.6),
+			Humidity:         float(81),
+			Area:             float(125020.6),
+			Capital:          nonEmpty("Oslo"),
+			TargetCurrencies: map[string]float64{"EUR": 0.087701435},
+			InverseRates:     map[string]float64{"EUR": 11.402},
+			Currency:         responses.Currency{Code: "NOK"},
+		},
+		Units: &units,
+	}
+	nordics := Dashboard{
+		Country:    "Nordics",
+		Aggregates: &RegionAggregates{Countries: 3, Population: intPointer(21000000), BaseCurrency: "EUR"},
+	}
+	thresholds := []badgeThreshold{{Value: 32, Color: "#007ec6"}, {Value: 50, Color: "#4c1"}, {Value: 77, Color: "#e05d44"}}
+
+	tests := []struct {
+		name      string
+		dashboard Dashboard
+		options   badgeOptions
+		wantLabel string
+		wantValue string
+		wantColor string
+	}{
+		{
+			name:      "Temperature with a label and thresholds",
+			dashboard: norway,
+			options:   badgeOptions{Feature: "temperature", Label: "Oslo", Color: "#555", Thresholds: thresholds},
+			wantLabel: "Oslo",
+			wantValue: "39.6°F",
+			wantColor: "#007ec6",
+		},
+		{
+			name:      "Below every threshold",
+			dashboard: norway,
+			options:   badgeOptions{Feature: "temperature", Color: "#555", Thresholds: thresholds[1:]},
+			wantLabel: "Norway temperature",
+			wantValue: "39.6°F",
+			wantColor: "#555",
+		},
+		{
+			name:      "Highest threshold",
+			dashboard: norway,
+			options:   badgeOptions{Feature: "humidity", Color: "#555", Thresholds: thresholds},
+			wantLabel: "Norway humidity",
+			wantValue: "81%",
+			wantColor: "#e05d44",
+		},
+		{
+			name:      "Area",
+			dashboard: norway,
+			options:   badgeOptions{Feature: "area", Color: "#555"},
+			wantLabel: "Norway area",
+			wantValue: "125021 mi²",
+			wantColor: "#555",
+		},
+		{
+			name:      "Exchange rate",
+			dashboard: norway,
+			options:   badgeOptions{Feature: "targetCurrencies.EUR", Color: "#555"},
+			wantLabel: "NOK→EUR",
+			wantValue: "0.0877",
+			wantColor: "#555",
+		},
+		{
+			name:      "Inverse exchange rate",
+			dashboard: norway,
+			options:   badgeOptions{Feature: "inverseRates.EUR", Color: "#555"},
+			wantLabel: "EUR→NOK",
+			wantValue: "11.4",
+			wantColor: "#555",
+		},
+		{
+			name:      "Text feature ignores thresholds",
+			dashboard: norway,
+			options:   badgeOptions{Feature: "capital", Color: "#555", Thresholds: thresholds},
+			wantLabel: "Norway capital",
+			wantValue: "Oslo",
+			wantColor: "#555",
+		},
+		{
+			name:      "Missing feature",
+			dashboard: norway,
+			options:   badgeOptions{Feature: "precipitation", Color: "#555"},
+			wantLabel: "Norway precipitation",
+			wantValue: missingBadgeValue,
+			wantColor: missingBadgeColor,
+		},
+		{
+			name:      "Aggregate of a region",
+			dashboard: nordics,
+			options:   badgeOptions{Feature: "aggregates.population", Color: "#555"},
+			wantLabel: "Nordics population",
+			wantValue: "21000000",
+			wantColor: "#555",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := newBadge(tt.dashboard, tt.options)
+				if got.Label != tt.wantLabel || got.Value != tt.wantValue || got.Color != tt.wantColor {
+					t.Errorf(
+						"newBadge() = %q %q %q, want %q %q %q",
+						got.Label, got.Value, got.Color, tt.wantLabel, tt.wantValue, tt.wantColor,
+					)
+				}
+				if got.Width != got.LabelWidth+got.ValueWidth || got.ValueX <= got.LabelX {
+					t.Errorf("newBadge() has inconsistent widths %+v", got)
+				}
+			},
+		)
+	}
+}
+
+func Test_formatBadgeNumber(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{value: 0, want: "0"},
+		{value: 4.2, want: "4.2"},
+		{value: -12.345, want: "-12.35"},
+		{value: 0.087701435, want: "0.0877"},
+		{value: 999.96, want: "1000"},
+		{value: 5379475, want: "5379475"},
+	}
+	for _, tt := range tests {
+		if got := formatBadgeNumber(tt.value); got != tt.want {
+			t.Errorf("formatBadgeNumber(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func Test_renderBadge(t *testing.T) {
+	var image bytes.Buffer
+	b := newBadge(
+		Dashboard{Country: "<Norway>", Features: DashboardFeatures{Capital: nonEmpty("Oslo & co")}},
+		badgeOptions{Feature: "capital", Color: "#4c1", LabelColor: "#555"},
+	)
+	if err := badgeTemplate.Execute(&image, b); err != nil {
+		t.Fatalf("badgeTemplate.Execute() error = %v", err)
+	}
+
+	// The badge is well-formed XML, with the texts escaped
+	var svg struct {
+		XMLName xml.Name `xml:"svg"`
+		Title   string   `xml:"title"`
+	}
+	if err := xml.Unmarshal(image.Bytes(), &svg); err != nil {
+		t.Fatalf("badge is not valid XML: %v\n%s", err, image.String())
+	}
+	if want := "<Norway> capital: Oslo & co"; svg.Title != want {
+		t.Errorf("badge title = %q, want %q", svg.Title, want)
+	}
+	if !strings.Contains(image.String(), `fill="#4c1"`) {
+		t.Errorf("badge does not have the color:\n%s", image.String())
+	}
+}
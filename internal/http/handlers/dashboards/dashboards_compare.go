@@ -0,0 +1,325 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	utils2 "assignment-2/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Comparison is the comparison of several dashboards, in the order they were asked for, with their numeric features
+// aligned feature by feature. All dashboards are in the same units.
+type Comparison struct {
+	Dashboards []Dashboard       `json:"dashboards"`
+	Features   []ComparedFeature `json:"features"`
+	Units      *DashboardUnits   `json:"units,omitempty"`
+	Language   string            `json:"language,omitempty"`
+}
+
+// ComparedFeature is a numeric feature of every compared dashboard, in the order of the dashboards.
+type ComparedFeature struct {
+	Feature string          `json:"feature"`
+	Values  []ComparedValue `json:"values"`
+}
+
+// ComparedValue is the value of a feature of a compared dashboard, its difference from the value of the first
+// dashboard, and its rank among the dashboards, where the highest value is 1 and equal values share a rank. A dashboard
+// without the feature has no value, difference or rank, and no dashboard has a difference if the first lacks it.
+type ComparedValue struct {
+	Country    string   `json:"country"`
+	IsoCode    string   `json:"isoCode,omitempty"`
+	Value      *float64 `json:"value"`
+	Difference *float64 `json:"difference,omitempty"`
+	Rank       int      `json:"rank,omitempty"`
+}
+
+// comparedFeatures are the numeric features dashboards are compared by, in the order they are returned. A regional
+// dashboard has them as aggregates, but for the precipitation.
+var comparedFeatures = []struct {
+	name  string
+	value func(Dashboard) *float64
+}{
+	{
+		name: "population",
+		value: func(d Dashboard) *float64 {
+			population := d.Features.Population
+			if d.Aggregates != nil {
+				population = d.Aggregates.Population
+			}
+			if population == nil {
+				return nil
+			}
+			value := float64(*population)
+			return &value
+		},
+	},
+	{
+		name: "area",
+		value: func(d Dashboard) *float64 {
+			if d.Aggregates != nil {
+				return d.Aggregates.Area
+			}
+			return d.Features.Area
+		},
+	},
+	{
+		name: "temperature",
+		value: func(d Dashboard) *float64 {
+			if d.Aggregates != nil {
+				return d.Aggregates.Temperature
+			}
+			return d.Features.Temperature
+		},
+	},
+	{
+		name:  "precipitation",
+		value: func(d Dashboard) *float64 { return d.Features.Precipitation },
+	},
+}
+
+// Implemented methods for the compare endpoint
+var implementedMethodsCompare = []string{
+	http.MethodGet,
+}
+
+// Endpoint for comparing dashboards
+var dashboardsCompareEndpoint = inhouse.Endpoint{
+	Path:    constants.ComparePath,
+	Methods: implementedMethodsCompare,
+	Description: "Endpoint for comparing dashboards. Returns the dashboards of the comma-separated registration ids, " +
+		"or of the countries of the comma-separated iso codes, with the differences and rankings of their numeric " +
+		"features.",
+}
+
+// CompareHandler handles the /dashboard/v1/compare path.
+// It currently only supports GET requests
+func CompareHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", "application/json")
+	// Switch on the HTTP request method
+	switch r.Method {
+	case http.MethodGet:
+		handleCompareRequest(w, r)
+
+	default:
+		// If the method is not implemented, return an error with the allowed methods
+		http.Error(
+			w, fmt.Sprintf(
+				"REST Method '%s' not supported. Currently only '%v' are supported.", r.Method,
+				implementedMethodsCompare,
+			), http.StatusNotImplemented,
+		)
+		return
+	}
+}
+
+// handleCompareRequest handles the GET request for the /dashboard/v1/compare path.
+// It is used to populate several dashboards at once and compare them.
+func handleCompareRequest(w http.ResponseWriter, r *http.Request) {
+	// The dashboards are compared in a single unit system, metric unless another is asked for
+	units := r.URL.Query().Get("units")
+	if units == "" {
+		units = requests.UnitsMetric
+	}
+	if units != requests.UnitsMetric && units != requests.UnitsImperial {
+		http.Error(w, constants.ErrDashboardUnits, http.StatusBadRequest)
+		return
+	}
+
+	configs, err := compareConfigs(r.URL.Query())
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), compareErrorStatus(err))
+		return
+	}
+
+	comparison, err := compareDashboards(r.Context(), configs, units, utils2.GetLanguageFromRequest(r))
+	if err != nil {
+		http.Error(w, err.Error(), compareErrorStatus(err))
+		return
+	}
+
+	marshaled, err := json.MarshalIndent(comparison, "", "\t")
+	if err != nil {
+		log.Println(constants.ErrJsonMarshal + err.Error())
+		http.Error(w, constants.ErrJsonMarshal, http.StatusInternalServerError)
+		return
+	}
+
+	if slices.ContainsFunc(comparison.Dashboards, Dashboard.IsPartial) {
+		w.Header().Set(constants.DashboardPartialHeader, "true")
+	}
+	if comparison.Language != "" {
+		w.Header().Set("Content-Language", comparison.Language)
+	}
+
+	_, err = w.Write(marshaled)
+	if err != nil {
+		log.Println(constants.ErrWriteResponse + err.Error())
+		http.Error(w, constants.ErrWriteResponse, http.StatusInternalServerError)
+		return
+	}
+}
+
+// compareErrorStatus returns the status code of an error of a comparison.
+func compareErrorStatus(err error) int {
+	switch err.Error() {
+	case constants.ErrDashboardCompareTargets, constants.ErrDashboardCompareCount, constants.ErrDashboardCompareIsoCode,
+		constants.ErrIDInvalid:
+		return http.StatusBadRequest
+	case constants.ErrDBDocNotFound:
+		return http.StatusNotFound
+	case constants.ErrDashboardTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// compareConfigs returns the registrations of the dashboards to compare: those of the ids parameter, or registrations
+// of the countries of the iso parameter with the compared features. Duplicates are compared once. The returned error
+// message is safe to show to the client.
+func compareConfigs(query url.Values) ([]requests.DashboardConfig, error) {
+	ids := parseList(query.Get("ids"))
+	isoCodes := parseList(query.Get("iso"))
+	if (len(ids) > 0) == (len(isoCodes) > 0) {
+		return nil, fmt.Errorf(constants.ErrDashboardCompareTargets)
+	}
+
+	var configs []requests.DashboardConfig
+	if len(isoCodes) > 0 {
+		for i, isoCode := range isoCodes {
+			isoCodes[i] = strings.ToUpper(isoCode)
+			if !utils2.IsIsoCode(isoCodes[i]) {
+				return nil, fmt.Errorf(constants.ErrDashboardCompareIsoCode)
+			}
+		}
+		isoCodes = withoutDuplicates(isoCodes)
+		if len(isoCodes) < 2 || len(isoCodes) > constants.MaxComparedDashboards {
+			return nil, fmt.Errorf(constants.ErrDashboardCompareCount)
+		}
+
+		features := requests.ConfigFeatures{Population: true, Area: true, Temperature: true, Precipitation: true}
+		for _, isoCode := range isoCodes {
+			configs = append(configs, requests.DashboardConfig{IsoCode: isoCode, Features: features})
+		}
+		return configs, nil
+	}
+
+	ids = withoutDuplicates(ids)
+	if len(ids) < 2 || len(ids) > constants.MaxComparedDashboards {
+		return nil, fmt.Errorf(constants.ErrDashboardCompareCount)
+	}
+	for _, id := range ids {
+		config, err := db.GetDocument[requests.DashboardConfig](id, db.DashboardCollection)
+		if err != nil {
+			log.Println(constants.ErrDBGetDoc + err.Error())
+			switch err.Error() {
+			case constants.ErrIDInvalid, constants.ErrDBDocNotFound:
+				return nil, err
+			default:
+				return nil, fmt.Errorf(constants.ErrDBGetDoc)
+			}
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// withoutDuplicates returns the given elements without duplicates, in the order they are first given.
+func withoutDuplicates(elements []string) []string {
+	var unique []string
+	for _, element := range elements {
+		if !slices.Contains(unique, element) {
+			unique = append(unique, element)
+		}
+	}
+	return unique
+}
+
+// compareDashboards populates the dashboards of the given registrations concurrently in the given unit system, names
+// their countries in the given language, if any, and compares their numeric features. The dashboards share the caches
+// of the dashboards endpoint, but are neither recorded as snapshots nor trigger events. Failing to populate any
+// dashboard is an error. The returned error message is safe to show to the client.
+func compareDashboards(
+	ctx context.Context,
+	configs []requests.DashboardConfig,
+	units string,
+	language string,
+) (Comparison, error) {
+	dashboards := make([]Dashboard, len(configs))
+	errs := make([]error, len(configs))
+	var wg sync.WaitGroup
+	for i := range configs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			config := configs[i]
+			config.Units = requests.Units{System: units}
+			dashboards[i], errs[i] = buildDashboard(ctx, config)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range dashboards {
+		if errs[i] != nil {
+			return Comparison{}, errs[i]
+		}
+		dashboards[i] = localizeDashboard(ctx, dashboards[i], language)
+	}
+
+	return Comparison{
+		Dashboards: dashboards,
+		Features:   compareFeatures(dashboards),
+		Units:      dashboards[0].Units,
+		Language:   language,
+	}, nil
+}
+
+// compareFeatures aligns the compared features of the given dashboards, leaving out the features none of them has.
+func compareFeatures(dashboards []Dashboard) []ComparedFeature {
+	features := make([]ComparedFeature, 0, len(comparedFeatures))
+	for _, compared := range comparedFeatures {
+		values := make([]*float64, len(dashboards))
+		for i, dashboard := range dashboards {
+			values[i] = compared.value(dashboard)
+		}
+		if !slices.ContainsFunc(values, func(value *float64) bool { return value != nil }) {
+			continue
+		}
+
+		feature := ComparedFeature{Feature: compared.name, Values: make([]ComparedValue, len(dashboards))}
+		for i, dashboard := range dashboards {
+			feature.Values[i] = ComparedValue{
+				Country: dashboard.Country,
+				IsoCode: dashboard.IsoCode,
+				Value:   values[i],
+			}
+			if values[i] == nil {
+				continue
+			}
+			if values[0] != nil {
+				difference := *values[i] - *values[0]
+				feature.Values[i].Difference = &difference
+			}
+			// The rank is one more than the number of higher values
+			feature.Values[i].Rank = 1
+			for _, other := range values {
+				if other != nil && *other > *values[i] {
+					feature.Values[i].Rank++
+				}
+			}
+		}
+		features = append(features, feature)
+	}
+	return features
+}
@@ -0,0 +1,202 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		statusCode int
+		wantBody   string
+	}{
+		{
+			name:       "NegativeTestCompareHandler",
+			method:     http.MethodPost,
+			target:     "/?iso=NO,SE",
+			statusCode: http.StatusNotImplemented,
+		},
+		{
+			name:       "NoTargetsTestCompareHandler",
+			method:     http.MethodGet,
+			target:     "/",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardCompareTargets,
+		},
+		{
+			name:       "BothTargetsTestCompareHandler",
+			method:     http.MethodGet,
+			target:     "/?ids=a,b&iso=NO,SE",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardCompareTargets,
+		},
+		{
+			name:       "SingleCountryTestCompareHandler",
+			method:     http.MethodGet,
+			target:     "/?iso=NO,no",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardCompareCount,
+		},
+		{
+			name:       "InvalidIsoCodeTestCompareHandler",
+			method:     http.MethodGet,
+			target:     "/?iso=NO,Sweden",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardCompareIsoCode,
+		},
+		{
+			name:       "InvalidUnitsTestCompareHandler",
+			method:     http.MethodGet,
+			target:     "/?iso=NO,SE&units=kelvin",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardUnits,
+		},
+		{
+			name:       "PositiveTestCompareHandler",
+			method:     http.MethodGet,
+			target:     "/?iso=NO,SE",
+			statusCode: http.StatusOK,
+			wantBody:   `"feature": "population"`,
+		},
+	}
+
+	// Run the tests
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Create a mock request
+				req := httptest.NewRequest(tt.method, tt.target, nil)
+
+				// Create a mock response recorder
+				w := httptest.NewRecorder()
+
+				// Call the handler
+				CompareHandler(w, req)
+
+				// Check if the status code matches expected
+				if w.Code != tt.statusCode {
+					log.Println("Testing: ", tt.name)
+					t.Errorf(
+						"handler returned wrong status code: got %v want %v",
+						w.Code, tt.statusCode,
+					)
+				}
+				if !strings.Contains(w.Body.String(), tt.wantBody) {
+					t.Errorf("handler returned body %q, want %q", w.Body.String(), tt.wantBody)
+				}
+			},
+		)
+	}
+}
+
+func Test_compareConfigs(t *testing.T) {
+	configs, err := compareConfigs(url.Values{"iso": {" se, nor ,SE"}})
+	if err != nil {
+		t.Fatalf("compareConfigs() error = %v", err)
+	}
+
+	var isoCodes []string
+	for _, config := range configs {
+		isoCodes = append(isoCodes, config.IsoCode)
+		if !config.Features.Population || !config.Features.Area || !config.Features.Temperature ||
+			!config.Features.Precipitation || config.Features.Capital {
+			t.Errorf("compareConfigs() features = %+v, want only the compared features", config.Features)
+		}
+	}
+	if want := []string{"SE", "NOR"}; !reflect.DeepEqual(isoCodes, want) {
+		t.Errorf("compareConfigs() ISO codes = %v, want %v", isoCodes, want)
+	}
+
+	_, err = compareConfigs(url.Values{"iso": {"NO,SE,DK,FI,IS,DE,FR,IT,ES,PT,NL"}})
+	if err == nil || err.Error() != constants.ErrDashboardCompareCount {
+		t.Errorf("compareConfigs() error = %v, want %v", err, constants.ErrDashboardCompareCount)
+	}
+}
+
+func Test_compareFeatures(t *testing.T) {
+	norway := Dashboard{
+		Country:  "Norway",
+		IsoCode:  "NO",
+		Features: DashboardFeatures{Population: intPointer(5), Temperature: float(4)},
+	}
+	sweden := Dashboard{
+		Country:  "Sweden",
+		IsoCode:  "SE",
+		Features: DashboardFeatures{Population: intPointer(10), Temperature: float(6)},
+	}
+	denmark := Dashboard{
+		Country:  "Denmark",
+		IsoCode:  "DK",
+		Features: DashboardFeatures{Population: intPointer(5), Precipitation: float(2)},
+	}
+	nordics := Dashboard{
+		Country:    "Nordics",
+		Aggregates: &RegionAggregates{Countries: 2, Population: intPointer(15), Area: float(3)},
+	}
+
+	got := compareFeatures([]Dashboard{norway, sweden, denmark, nordics})
+
+	want := []ComparedFeature{
+		{
+			Feature: "population",
+			Values: []ComparedValue{
+				{Country: "Norway", IsoCode: "NO", Value: float(5), Difference: float(0), Rank: 3},
+				{Country: "Sweden", IsoCode: "SE", Value: float(10), Difference: float(5), Rank: 2},
+				{Country: "Denmark", IsoCode: "DK", Value: float(5), Difference: float(0), Rank: 3},
+				{Country: "Nordics", Value: float(15), Difference: float(10), Rank: 1},
+			},
+		},
+		{
+			Feature: "area",
+			Values: []ComparedValue{
+				{Country: "Norway", IsoCode: "NO"},
+				{Country: "Sweden", IsoCode: "SE"},
+				{Country: "Denmark", IsoCode: "DK"},
+				{Country: "Nordics", Value: float(3), Rank: 1},
+			},
+		},
+		{
+			Feature: "temperature",
+			Values: []ComparedValue{
+				{Country: "Norway", IsoCode: "NO", Value: float(4), Difference: float(0), Rank: 2},
+				{Country: "Sweden", IsoCode: "SE", Value: float(6), Difference: float(2), Rank: 1},
+				{Country: "Denmark", IsoCode: "DK"},
+				{Country: "Nordics"},
+			},
+		},
+		{
+			Feature: "precipitation",
+			Values: []ComparedValue{
+				{Country: "Norway", IsoCode: "NO"},
+				{Country: "Sweden", IsoCode: "SE"},
+				{Country: "Denmark", IsoCode: "DK", Value: float(2), Rank: 1},
+				{Country: "Nordics"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJson, _ := json.Marshal(got)
+		wantJson, _ := json.Marshal(want)
+		t.Errorf("compareFeatures() = %s, want %s", gotJson, wantJson)
+	}
+
+	// Features no dashboard has are left out
+	if got := compareFeatures([]Dashboard{{Country: "Norway"}, {Country: "Sweden"}}); len(got) != 0 {
+		t.Errorf("compareFeatures() = %+v, want no features", got)
+	}
+}
+
+// intPointer returns a pointer to the given int.
+func intPointer(value int) *int {
+	return &value
+}
@@ -0,0 +1,203 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/expression"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/providers"
+	utils2 "assignment-2/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// computeFeatures returns the dashboard of a country with the computed features of its registration, evaluated in
+// order from its features, in its units, and rounded to its precision. A computed feature that cannot be evaluated,
+// such as one using a feature the dashboard does not have, is left out. Regional dashboards have no computed features.
+func computeFeatures(ctx context.Context, dashboardConfig requests.DashboardConfig, dashboard Dashboard) Dashboard {
+	if len(dashboardConfig.Computed) == 0 || dashboard.Aggregates != nil {
+		return dashboard
+	}
+
+	variables, err := dashboardVariables(dashboard)
+	if err != nil {
+		log.Println(constants.ErrJsonMarshal + err.Error())
+		return dashboard
+	}
+
+	converter := newUnitConverter(dashboardConfig)
+	computed := make(map[string]float64, len(dashboardConfig.Computed))
+	references := make(map[string]map[string]float64)
+	for _, feature := range dashboardConfig.Computed {
+		var value float64
+		var err error
+		if feature.Builtin != "" {
+			value, err = computeBuiltin(ctx, dashboardConfig, dashboard, feature)
+		} else {
+			value, err = computeExpression(ctx, dashboardConfig, feature, variables, references)
+		}
+		if err != nil {
+			log.Println(constants.ErrDashboardComputed + feature.Name + ": " + err.Error())
+			continue
+		}
+
+		value = *converter.scale(&value, 1)
+		computed[feature.Name] = value
+		variables[feature.Name] = value
+	}
+
+	if len(computed) > 0 {
+		dashboard.Features.Computed = computed
+	}
+	return dashboard
+}
+
+// dashboardVariables returns the numeric features of the dashboard by their path, as the variables of expressions.
+func dashboardVariables(dashboard Dashboard) (map[string]float64, error) {
+	marshaled, err := json.Marshal(dashboard)
+	if err != nil {
+		return nil, err
+	}
+	variables, _, err := numericFeatures(marshaled)
+	return variables, err
+}
+
+// computeExpression evaluates the expression of a computed feature with the given variables, and the features of its
+// reference country, if it has one. The dashboard of a reference country has the features of the registration, and
+// is populated once per evaluation of the computed features, which the given references hold by ISO code.
+func computeExpression(
+	ctx context.Context,
+	dashboardConfig requests.DashboardConfig,
+	feature requests.ComputedFeature,
+	variables map[string]float64,
+	references map[string]map[string]float64,
+) (float64, error) {
+	parsed, err := expression.Parse(feature.Expression)
+	if err != nil {
+		return 0, err
+	}
+	if feature.Reference == "" {
+		return parsed.Evaluate(variables)
+	}
+
+	isoCode := strings.ToUpper(feature.Reference)
+	if _, ok := references[isoCode]; !ok {
+		referenceConfig := requests.DashboardConfig{
+			IsoCode:   isoCode,
+			Features:  dashboardConfig.Features,
+			Units:     dashboardConfig.Units,
+			Precision: dashboardConfig.Precision,
+		}
+		reference, err := buildCountryDashboard(ctx, referenceConfig)
+		if err != nil {
+			return 0, err
+		}
+		references[isoCode], err = dashboardVariables(newUnitConverter(referenceConfig).dashboard(reference))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	withReference := make(map[string]float64, len(variables)+len(references[isoCode]))
+	for name, value := range variables {
+		withReference[name] = value
+	}
+	for name, value := range references[isoCode] {
+		withReference[requests.ReferencePrefix+name] = value
+	}
+	return parsed.Evaluate(withReference)
+}
+
+// computeBuiltin evaluates a builtin computed feature of the dashboard of a country:
+//   - density is the population per unit of area, in the area unit of the registration
+//   - areaRank is the rank of the area of the country among the countries of its region, where the largest is 1
+//   - rateChange is the change in percent of the exchange rate to the currency since the previous snapshot
+//
+// The population, area and region are those of the country data, whether they are enabled or not.
+func computeBuiltin(
+	ctx context.Context,
+	dashboardConfig requests.DashboardConfig,
+	dashboard Dashboard,
+	feature requests.ComputedFeature,
+) (float64, error) {
+	if feature.Builtin == requests.ComputedRateChange {
+		return computeRateChange(dashboardConfig.ID, feature.Currency, dashboard.Features.TargetCurrencies)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, utils2.GetDashboardTimeout())
+	defer cancel()
+
+	// The country data has been cached when the dashboard was populated
+	country, err := getCountryData(ctx, dashboard.IsoCode)
+	if err != nil {
+		return 0, err
+	}
+	if country.Area == nil || *country.Area <= 0 {
+		return 0, fmt.Errorf(constants.ErrDashboardNoArea)
+	}
+
+	switch feature.Builtin {
+	case requests.ComputedDensity:
+		area := *country.Area * areaFactors[resolveUnits(dashboardConfig.Units).Area]
+		return float64(*country.Population) / area, nil
+	case requests.ComputedAreaRank:
+		if country.Region == nil {
+			return 0, fmt.Errorf(constants.ErrDashboardNoRegion)
+		}
+		countries, err := providers.Countries().Region(ctx, *country.Region)
+		if err != nil {
+			return 0, err
+		}
+		return areaRank(*country.Area, countries), nil
+	default:
+		return 0, fmt.Errorf(constants.ErrRegistrationComputed)
+	}
+}
+
+// areaRank returns the rank of the given area among the areas of the given countries, where the largest is 1.
+func areaRank(area float64, countries []providers.Country) float64 {
+	rank := 1
+	for _, country := range countries {
+		if country.Area > area {
+			rank++
+		}
+	}
+	return float64(rank)
+}
+
+// computeRateChange returns the change in percent of the exchange rate to the given currency since the latest
+// snapshot of the dashboard with the given ID. Dashboards that are not of a registration have no snapshots.
+func computeRateChange(id string, currency string, rates map[string]float64) (float64, error) {
+	if id == "" {
+		return 0, fmt.Errorf(constants.ErrDashboardNoPreviousRate)
+	}
+	previous, err := db.GetLatestSubDocument[inhouse.DashboardSnapshot](
+		id,
+		db.DashboardCollection,
+		db.SnapshotCollection,
+		snapshotTimeField,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return rateChange(previous.Dashboard, currency, rates)
+}
+
+// rateChange returns the change in percent of the exchange rate to the given currency from the given snapshot to the
+// given rates. Unknown rates cannot be compared.
+func rateChange(snapshot []byte, currency string, rates map[string]float64) (float64, error) {
+	values, _, err := numericFeatures(snapshot)
+	if err != nil {
+		return 0, err
+	}
+	previous := values[requests.TargetCurrenciesPrefix+currency]
+	current, ok := rates[currency]
+	if previous == 0 || !ok || current == 0 {
+		return 0, fmt.Errorf(constants.ErrDashboardNoPreviousRate)
+	}
+	return (current - previous) / previous * 100, nil
+}
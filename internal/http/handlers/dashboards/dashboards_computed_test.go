@@ -0,0 +1,191 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/providers"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func Test_computeFeatures(t *testing.T) {
+	precision := 1
+	norway := Dashboard{
+		Country:  "Norway",
+		IsoCode:  "NO",
+		Features: DashboardFeatures{Population: intPointer(5379475), Area: float(323802)},
+	}
+
+	tests := []struct {
+		name      string
+		config    requests.DashboardConfig
+		dashboard Dashboard
+		want      map[string]float64
+	}{
+		{
+			name: "Builtins",
+			config: requests.DashboardConfig{
+				IsoCode:   "NO",
+				Precision: &precision,
+				Computed: []requests.ComputedFeature{
+					{Name: "density", Builtin: requests.ComputedDensity},
+					{Name: "rank", Builtin: requests.ComputedAreaRank},
+				},
+			},
+			dashboard: norway,
+			want:      map[string]float64{"density": 16.6, "rank": 3},
+		},
+		{
+			name: "Density in square miles",
+			config: requests.DashboardConfig{
+				IsoCode:   "NO",
+				Units:     requests.Units{System: requests.UnitsImperial},
+				Precision: &precision,
+				Computed:  []requests.ComputedFeature{{Name: "density", Builtin: requests.ComputedDensity}},
+			},
+			dashboard: norway,
+			want:      map[string]float64{"density": 43},
+		},
+		{
+			name: "Expressions using earlier computed features",
+			config: requests.DashboardConfig{
+				IsoCode: "NO",
+				Computed: []requests.ComputedFeature{
+					{Name: "perArea", Expression: "population / area"},
+					{Name: "doubled", Expression: "2 * perArea"},
+				},
+			},
+			dashboard: Dashboard{
+				Country:  "Norway",
+				IsoCode:  "NO",
+				Features: DashboardFeatures{Population: intPointer(10), Area: float(4)},
+			},
+			want: map[string]float64{"perArea": 2.5, "doubled": 5},
+		},
+		{
+			name: "Expression with a reference country",
+			config: requests.DashboardConfig{
+				IsoCode:  "NO",
+				Features: requests.ConfigFeatures{Population: true},
+				Computed: []requests.ComputedFeature{
+					{Name: "share", Expression: "population / reference.population", Reference: "no"},
+				},
+			},
+			dashboard: norway,
+			want:      map[string]float64{"share": 1},
+		},
+		{
+			name: "Features that cannot be computed are left out",
+			config: requests.DashboardConfig{
+				IsoCode: "NO",
+				Computed: []requests.ComputedFeature{
+					{Name: "warm", Expression: "temperature * 2"},
+					{Name: "empty", Expression: "population / (area - area)"},
+					{Name: "change", Builtin: requests.ComputedRateChange, Currency: "EUR"},
+				},
+			},
+			dashboard: norway,
+		},
+		{
+			name: "Regional dashboards have no computed features",
+			config: requests.DashboardConfig{
+				Region:   "Europe",
+				Computed: []requests.ComputedFeature{{Name: "one", Expression: "1"}},
+			},
+			dashboard: Dashboard{Country: "Europe", Aggregates: &RegionAggregates{Countries: 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := computeFeatures(context.Background(), tt.config, tt.dashboard)
+				if !reflect.DeepEqual(got.Features.Computed, tt.want) {
+					t.Errorf("computeFeatures() = %v, want %v", got.Features.Computed, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_areaRank(t *testing.T) {
+	countries := []providers.Country{
+		{IsoCode: "FI", Area: 338424},
+		{IsoCode: "NO", Area: 323802},
+		{IsoCode: "SE", Area: 450295},
+		{IsoCode: "DK", Area: 323802},
+	}
+
+	tests := []struct {
+		name string
+		area float64
+		want float64
+	}{
+		{name: "Largest", area: 450295, want: 1},
+		{name: "Shared rank", area: 323802, want: 3},
+		{name: "Smallest", area: 1, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := areaRank(tt.area, countries); got != tt.want {
+					t.Errorf("areaRank() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_rateChange(t *testing.T) {
+	snapshot := []byte(`{"features": {"targetCurrencies": {"EUR": 0.08, "USD": 0}}}`)
+
+	tests := []struct {
+		name     string
+		currency string
+		rates    map[string]float64
+		want     float64
+		wantErr  bool
+	}{
+		{
+			name:     "Increase",
+			currency: "EUR",
+			rates:    map[string]float64{"EUR": 0.1},
+			want:     25,
+		},
+		{
+			name:     "Decrease",
+			currency: "EUR",
+			rates:    map[string]float64{"EUR": 0.06},
+			want:     -25,
+		},
+		{
+			name:     "Unknown previous rate",
+			currency: "USD",
+			rates:    map[string]float64{"USD": 0.1},
+			wantErr:  true,
+		},
+		{
+			name:     "Unknown current rate",
+			currency: "EUR",
+			rates:    map[string]float64{"USD": 0.1},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := rateChange(snapshot, tt.currency, tt.rates)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("rateChange() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil && err.Error() != constants.ErrDashboardNoPreviousRate {
+					t.Errorf("rateChange() error = %v, want %v", err, constants.ErrDashboardNoPreviousRate)
+				}
+				// Compare to the cent, as the rates are not exact
+				if diff := got - tt.want; diff > 0.01 || diff < -0.01 {
+					t.Errorf("rateChange() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
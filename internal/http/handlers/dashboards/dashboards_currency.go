@@ -0,0 +1,78 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// deriveRates returns the inverse rates, from each target currency to the base currency, and the cross rates between
+// every pair of target currencies, by the currency converted from and then to. They are derived from the exchange
+// rates from the base currency to the target currencies, so no other base is fetched. Unknown rates are 0, and so are
+// the rates derived from them.
+func deriveRates(rates map[string]float64) (map[string]float64, map[string]map[string]float64) {
+	inverseRates := make(map[string]float64, len(rates))
+	crossRates := make(map[string]map[string]float64, len(rates))
+	for from, fromRate := range rates {
+		inverseRates[from] = inverse(fromRate)
+		crossRates[from] = make(map[string]float64, len(rates))
+		for to, toRate := range rates {
+			crossRates[from][to] = toRate * inverse(fromRate)
+		}
+	}
+	return inverseRates, crossRates
+}
+
+// inverse returns the inverse of the given exchange rate, or 0 if the rate is unknown.
+func inverse(rate float64) float64 {
+	if rate == 0 {
+		return 0
+	}
+	return 1 / rate
+}
+
+// parseAmount parses the amount of the primary currency to convert to the target currencies, given as a query
+// parameter. No amount is nil.
+func parseAmount(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || !validAmount(amount) {
+		return nil, fmt.Errorf(constants.ErrDashboardAmount)
+	}
+	return &amount, nil
+}
+
+// validAmount returns whether the given amount is a finite and non-negative number.
+func validAmount(amount float64) bool {
+	return !math.IsNaN(amount) && !math.IsInf(amount, 0) && amount >= 0
+}
+
+// withAmount returns the dashboard with the given amount of its primary currency converted to each target currency,
+// and the dashboards of its members with theirs. The converted amount of an unknown rate is 0. A dashboard without an
+// amount is returned as it is.
+func withAmount(dashboard Dashboard, amount *float64) Dashboard {
+	if amount == nil {
+		return dashboard
+	}
+
+	if len(dashboard.Features.TargetCurrencies) > 0 {
+		value := *amount
+		dashboard.Features.Amount = &value
+		dashboard.Features.ConvertedAmounts = make(map[string]float64, len(dashboard.Features.TargetCurrencies))
+		for currency, rate := range dashboard.Features.TargetCurrencies {
+			dashboard.Features.ConvertedAmounts[currency] = value * rate
+		}
+	}
+
+	if len(dashboard.Members) > 0 {
+		members := make([]Dashboard, len(dashboard.Members))
+		for i, member := range dashboard.Members {
+			members[i] = withAmount(member, amount)
+		}
+		dashboard.Members = members
+	}
+	return dashboard
+}
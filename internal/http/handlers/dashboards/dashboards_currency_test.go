@@ -0,0 +1,97 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_deriveRates(t *testing.T) {
+	inverseRates, crossRates := deriveRates(map[string]float64{"EUR": 0.1, "USD": 0.125, "XXX": 0})
+
+	wantInverse := map[string]float64{"EUR": 10, "USD": 8, "XXX": 0}
+	if !reflect.DeepEqual(inverseRates, wantInverse) {
+		t.Errorf("deriveRates() inverse rates = %v, want %v", inverseRates, wantInverse)
+	}
+
+	wantCross := map[string]map[string]float64{
+		"EUR": {"EUR": 1, "USD": 1.25, "XXX": 0},
+		"USD": {"EUR": 0.8, "USD": 1, "XXX": 0},
+		"XXX": {"EUR": 0, "USD": 0, "XXX": 0},
+	}
+	for from, rates := range wantCross {
+		for to, want := range rates {
+			if got := crossRates[from][to]; math.Abs(got-want) > 1e-9 {
+				t.Errorf("deriveRates() cross rate from %s to %s = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+	if len(crossRates) != len(wantCross) {
+		t.Errorf("deriveRates() cross rates = %v, want %v", crossRates, wantCross)
+	}
+}
+
+func Test_parseAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    *float64
+		wantErr bool
+	}{
+		{name: "No amount", value: "", want: nil},
+		{name: "Amount", value: "1500", want: float(1500)},
+		{name: "Fraction", value: "12.5", want: float(12.5)},
+		{name: "Zero", value: "0", want: float(0)},
+		{name: "Negative", value: "-1", wantErr: true},
+		{name: "Not a number", value: "NaN", wantErr: true},
+		{name: "Infinite", value: "Inf", wantErr: true},
+		{name: "Text", value: "1500 NOK", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := parseAmount(tt.value)
+				if tt.wantErr {
+					if err == nil || err.Error() != constants.ErrDashboardAmount {
+						t.Errorf("parseAmount() error = %v, want %v", err, constants.ErrDashboardAmount)
+					}
+					return
+				}
+				if err != nil || !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("parseAmount() = %v, %v, want %v", got, err, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_withAmount(t *testing.T) {
+	dashboard := Dashboard{
+		Country:  "Norway",
+		Features: DashboardFeatures{TargetCurrencies: map[string]float64{"EUR": 0.1, "XXX": 0}},
+		Members: []Dashboard{
+			{Features: DashboardFeatures{TargetCurrencies: map[string]float64{"USD": 0.125}}},
+			{Features: DashboardFeatures{Population: new(int)}},
+		},
+	}
+
+	got := withAmount(dashboard, float(1500))
+	if *got.Features.Amount != 1500 ||
+		!reflect.DeepEqual(got.Features.ConvertedAmounts, map[string]float64{"EUR": 150, "XXX": 0}) {
+		t.Errorf("withAmount() features = %+v, want 1500 converted to 150 EUR", got.Features)
+	}
+	if !reflect.DeepEqual(got.Members[0].Features.ConvertedAmounts, map[string]float64{"USD": 187.5}) {
+		t.Errorf("withAmount() members = %+v, want 1500 converted to 187.5 USD", got.Members)
+	}
+	if got.Members[1].Features.Amount != nil || got.Members[1].Features.ConvertedAmounts != nil {
+		t.Errorf("withAmount() members = %+v, want no amount without target currencies", got.Members)
+	}
+	if dashboard.Features.Amount != nil || dashboard.Members[0].Features.ConvertedAmounts != nil {
+		t.Errorf("withAmount() modified the original dashboard to %+v", dashboard)
+	}
+
+	if got := withAmount(dashboard, nil); !reflect.DeepEqual(got, dashboard) {
+		t.Errorf("withAmount() = %+v, want the dashboard unchanged without an amount", got)
+	}
+}
@@ -1,41 +1,126 @@
 package dashboards
 
 import (
+	"assignment-2/internal/cache"
 	"assignment-2/internal/constants"
 	"assignment-2/internal/db"
 	"assignment-2/internal/http/datatransfers/inhouse"
 	"assignment-2/internal/http/datatransfers/requests"
 	"assignment-2/internal/http/datatransfers/responses"
 	"assignment-2/internal/http/handlers/notifications"
+	"assignment-2/internal/providers"
 	utils2 "assignment-2/internal/utils"
+	"context"
 	"dario.cat/mergo"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
-// dashboard is the struct for the response object
-type dashboard struct {
-	Country       string            `json:"country"`
-	IsoCode       string            `json:"isoCode"`
-	Features      dashboardFeatures `json:"features"`
-	LastRetrieval time.Time         `json:"lastRetrieval"`
+// Dashboard is the struct for the response object. The timezone and local retrieval time are those of the weather
+// location, or of the country if it has a single timezone. A regional dashboard has the dashboards of its countries as
+// members, and the aggregates over them, instead of features. The delta is the change since the previous snapshot, and
+// the units are those of the quantities of the dashboard and its members. The language is that of the names of the
+// countries, if the request asked for one.
+type Dashboard struct {
+	Country        string                    `json:"country"`
+	IsoCode        string                    `json:"isoCode"`
+	Features       DashboardFeatures         `json:"features"`
+	Errors         map[string]DashboardError `json:"errors,omitempty"`
+	LastRetrieval  time.Time                 `json:"lastRetrieval"`
+	Timezone       string                    `json:"timezone,omitempty"`
+	LocalRetrieval *time.Time                `json:"localRetrieval,omitempty"`
+	Members        []Dashboard               `json:"members,omitempty"`
+	Aggregates     *RegionAggregates         `json:"aggregates,omitempty"`
+	Delta          *DashboardDelta           `json:"delta,omitempty"`
+	Units          *DashboardUnits           `json:"units,omitempty"`
+	Language       string                    `json:"language,omitempty"`
 }
 
-// dashboardFeatures is the struct for the features of the dashboard
-type dashboardFeatures struct {
+// Options are overrides of the registration of a dashboard for a single request.
+type Options struct {
+	// Units is the unit system of the quantities instead of the registered units, if given
+	Units string
+	// Language is the supported language the names of the countries are in, if given
+	Language string
+	// Amount is the amount of the primary currency to convert to the target currencies, if given
+	Amount *float64
+}
+
+// DashboardError is the struct for an external service that failed, by which the dashboard is partial
+type DashboardError struct {
+	Features []string `json:"features"`
+	Message  string   `json:"message"`
+}
+
+// DashboardFeatures is the struct for the features of the dashboard. The weather features are those of today.
+type DashboardFeatures struct {
 	Temperature      *float64             `json:"temperature,omitempty"`
 	Precipitation    *float64             `json:"precipitation,omitempty"`
+	TemperatureMin   *float64             `json:"temperatureMin,omitempty"`
+	TemperatureMax   *float64             `json:"temperatureMax,omitempty"`
+	WindSpeed        *float64             `json:"windSpeed,omitempty"`
+	WindGusts        *float64             `json:"windGusts,omitempty"`
+	Humidity         *float64             `json:"humidity,omitempty"`
+	CloudCover       *float64             `json:"cloudCover,omitempty"`
+	UvIndex          *float64             `json:"uvIndex,omitempty"`
 	Capital          *string              `json:"capital,omitempty"`
 	Coordinates      *inhouse.Coordinates `json:"coordinates,omitempty"`
 	Population       *int                 `json:"population,omitempty"`
 	Area             *float64             `json:"area,omitempty"`
 	TargetCurrencies map[string]float64   `json:"targetCurrencies,omitempty"`
 	Currency         responses.Currency   `json:"currency"`
+	// Capitals and Currencies are all those of the country, of which Capital and Currency are the primary ones, and
+	// ExchangeRates are the exchange rates to the target currencies from each currency, by currency code
+	Capitals      []string                      `json:"capitals,omitempty"`
+	Currencies    []responses.Currency          `json:"currencies,omitempty"`
+	ExchangeRates map[string]map[string]float64 `json:"exchangeRates,omitempty"`
+	// InverseRates are the exchange rates from the target currencies to the primary currency, and CrossRates those
+	// between the target currencies, by the currency converted from. ConvertedAmounts is the amount of the primary
+	// currency in each target currency, if the request has an amount.
+	InverseRates     map[string]float64            `json:"inverseRates,omitempty"`
+	CrossRates       map[string]map[string]float64 `json:"crossRates,omitempty"`
+	Amount           *float64                      `json:"amount,omitempty"`
+	ConvertedAmounts map[string]float64            `json:"convertedAmounts,omitempty"`
+	Forecast         []ForecastDay                 `json:"forecast,omitempty"`
+	// WeatherLocation is where the weather features are measured, and WeatherPoints the weather at each of its points
+	WeatherLocation *requests.WeatherLocation `json:"weatherLocation,omitempty"`
+	WeatherPoints   []PointWeather            `json:"weatherPoints,omitempty"`
+	// Languages and Borders are the names of the official languages and of the bordering countries, Region also
+	// enables Subregion, and Gini is the latest Gini index
+	Languages    []string      `json:"languages,omitempty"`
+	Borders      []string      `json:"borders,omitempty"`
+	Region       *string       `json:"region,omitempty"`
+	Subregion    *string       `json:"subregion,omitempty"`
+	Flag         *inhouse.Flag `json:"flag,omitempty"`
+	Timezones    []string      `json:"timezones,omitempty"`
+	CallingCodes []string      `json:"callingCodes,omitempty"`
+	DrivingSide  *string       `json:"drivingSide,omitempty"`
+	Gini         *inhouse.Gini `json:"gini,omitempty"`
+	// Computed are the computed features of the registration, by name
+	Computed map[string]float64 `json:"computed,omitempty"`
+	// Location is the timezone of the data, CapitalCoordinates where the weather of the capital is measured, and Name
+	// and Translations the names of the country, which are not features of their own
+	Location           *time.Location       `json:"-"`
+	CapitalCoordinates *inhouse.Coordinates `json:"-"`
+	Name               string               `json:"-"`
+	Translations       map[string]string    `json:"-"`
 }
 
+// Names of the external services, as used for caches and errors in partial dashboards
+const (
+	countrySource  = "countries"
+	meteoSource    = "meteo"
+	currencySource = "currency"
+)
+
 // Implemented methods for the endpoint
 var implementedMethods = []string{
 	http.MethodGet,
@@ -48,9 +133,16 @@ var dashboardsEndpoint = inhouse.Endpoint{
 	Description: "Endpoint for managing dashboards.",
 }
 
-// GetEndpointStructs returns the endpoint struct for the dashboards endpoint.
+// GetEndpointStructs returns the endpoint structs for the dashboards endpoints.
 func GetEndpointStructs() []inhouse.Endpoint {
-	return []inhouse.Endpoint{dashboardsEndpoint}
+	return []inhouse.Endpoint{
+		dashboardsEndpoint,
+		dashboardsLiveEndpoint,
+		dashboardsHistoryEndpoint,
+		dashboardsCompareEndpoint,
+		dashboardsViewEndpoint,
+		dashboardsBadgeEndpoint,
+	}
 }
 
 // HandlerWithID handles the /dashboard/v1/dashboards path.
@@ -79,317 +171,525 @@ func HandlerWithID(w http.ResponseWriter, r *http.Request) {
 // It is used to retrieve the populated dashboards.
 func handleDashboardsGetRequest(w http.ResponseWriter, r *http.Request) {
 	id, err := utils2.GetIDFromRequest(r)
-
-	dashboardConfig, err := db.GetDocument[requests.DashboardConfig](
-		id,
-		db.DashboardCollection,
-	)
 	if err != nil {
-		log.Println(constants.ErrDBGetDoc + err.Error())
-		http.Error(
-			w,
-			constants.ErrDBGetDoc,
-			http.StatusInternalServerError,
-		)
+		http.Error(w, constants.ErrIDInvalid, http.StatusBadRequest)
 		return
 	}
 
-	// Create the response object and assign the country and iso code
-	var response dashboard
-	response.Country = dashboardConfig.Country
-	response.IsoCode = dashboardConfig.IsoCode
-
-	// Get the features for the dashboard
-	var features dashboardFeatures
-	countryFeatures, err := getCountryData(dashboardConfig.IsoCode)
+	amount, err := parseAmount(r.URL.Query().Get("amount"))
 	if err != nil {
-		log.Println(constants.ErrDashboardGetCountryData + err.Error())
-		http.Error(
-			w,
-			constants.ErrDashboardGetCountryData,
-			http.StatusInternalServerError,
-		)
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Merge the features
-	err = mergo.Merge(&features, countryFeatures, mergo.WithOverride, mergo.WithoutDereference)
+	// Populate the dashboard and trigger the INVOKE event
+	options := Options{Units: r.URL.Query().Get("units"), Language: utils2.GetLanguageFromRequest(r), Amount: amount}
+	filteredResponse, err := GetDashboard(r.Context(), id, options)
 	if err != nil {
-		log.Println(constants.ErrDashboardMergingData + err.Error())
-		http.Error(
-			w,
-			constants.ErrDashboardMergingData,
-			http.StatusInternalServerError,
-		)
+		http.Error(w, err.Error(), dashboardErrorStatus(err))
 		return
 	}
 
-	// Get the meteo features
-	meteoFeatures, err := getMeteoData(features.Coordinates)
+	writeDashboard(w, filteredResponse)
+}
+
+// dashboardErrorStatus returns the status code of an error of GetDashboard.
+func dashboardErrorStatus(err error) int {
+	switch err.Error() {
+	case constants.ErrDashboardUnits, constants.ErrDashboardAmount, constants.ErrIDInvalid:
+		return http.StatusBadRequest
+	case constants.ErrDBDocNotFound, constants.ErrDashboardRegionNotFound:
+		return http.StatusNotFound
+	case constants.ErrDashboardTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeDashboard writes the dashboard to the response, marking it as partial if any external service failed.
+func writeDashboard(w http.ResponseWriter, dashboard Dashboard) {
+	// Marshal the status object to JSON
+	marshaled, err := json.MarshalIndent(
+		dashboard,
+		"",
+		"\t",
+	)
 	if err != nil {
-		log.Println(constants.ErrDashboardGetWeatherData + err.Error())
-		http.Error(
-			w,
-			constants.ErrDashboardGetWeatherData,
-			http.StatusInternalServerError,
-		)
+		log.Println(constants.ErrJsonMarshal + err.Error())
+		http.Error(w, constants.ErrJsonMarshal, http.StatusInternalServerError)
 		return
 	}
 
-	// Merge the features
-	err = mergo.Merge(&features, meteoFeatures, mergo.WithOverride, mergo.WithoutDereference)
+	if dashboard.IsPartial() {
+		w.Header().Set(constants.DashboardPartialHeader, "true")
+	}
+	if dashboard.Language != "" {
+		w.Header().Set("Content-Language", dashboard.Language)
+	}
+
+	// Write the JSON to the response
+	_, err = w.Write(marshaled)
 	if err != nil {
-		log.Println(constants.ErrDashboardMergingData + err.Error())
-		http.Error(
-			w,
-			constants.ErrDashboardMergingData,
-			http.StatusInternalServerError,
-		)
+		log.Println(constants.ErrWriteResponse + err.Error())
+		http.Error(w, constants.ErrWriteResponse, http.StatusInternalServerError)
 		return
 	}
+}
+
+// IsPartial returns whether any external service failed to supply the features of the dashboard, or of any of its
+// members.
+func (d Dashboard) IsPartial() bool {
+	if len(d.Errors) > 0 {
+		return true
+	}
+	for _, member := range d.Members {
+		if member.IsPartial() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDashboard gets the registration with the given ID, populates its dashboard with the given overrides, records it
+// as a snapshot with the delta against the previous one, and triggers the INVOKE event. The dashboard is localized and
+// the amount is converted after the snapshot is recorded, as they depend on the request and are not data of the
+// dashboard. The returned error message is safe to show to the client.
+func GetDashboard(ctx context.Context, id string, options Options) (Dashboard, error) {
+	dashboardConfig, dashboard, err := populateDashboard(ctx, id, options)
+	if err != nil {
+		return Dashboard{}, err
+	}
+	recordSnapshot(dashboardConfig.ID, &dashboard)
+	dashboard = localizeDashboard(ctx, dashboard, options.Language)
+	dashboard = withAmount(dashboard, options.Amount)
+
+	err = notifications.TriggerEvent(requests.EventInvoke, dashboard.IsoCode, dashboardConfig.ID)
+	if err != nil {
+		return Dashboard{}, err
+	}
+
+	return dashboard, nil
+}
+
+// populateDashboard gets the registration with the given ID, and populates its dashboard with the units of the given
+// overrides, without recording it or triggering any event. Returns the registration with the overrides. The returned
+// error message is safe to show to the client.
+func populateDashboard(
+	ctx context.Context,
+	id string,
+	options Options,
+) (requests.DashboardConfig, Dashboard, error) {
+	// Only the systems without units of their own can be chosen per request
+	if options.Units != "" && options.Units != requests.UnitsMetric && options.Units != requests.UnitsImperial {
+		return requests.DashboardConfig{}, Dashboard{}, fmt.Errorf(constants.ErrDashboardUnits)
+	}
+	if options.Amount != nil && !validAmount(*options.Amount) {
+		return requests.DashboardConfig{}, Dashboard{}, fmt.Errorf(constants.ErrDashboardAmount)
+	}
 
-	// Get the currency features
-	currencyFeatures, err := getCurrencyData(
-		dashboardConfig.Features.TargetCurrencies,
-		countryFeatures.Currency,
+	dashboardConfig, err := db.GetDocument[requests.DashboardConfig](
+		id,
+		db.DashboardCollection,
 	)
 	if err != nil {
-		log.Println(constants.ErrDashboardGetCurrencyData + err.Error())
-		http.Error(
-			w,
-			constants.ErrDashboardGetCurrencyData,
-			http.StatusInternalServerError,
-		)
-		return
+		log.Println(constants.ErrDBGetDoc + err.Error())
+		switch err.Error() {
+		case constants.ErrIDInvalid, constants.ErrDBDocNotFound:
+			return requests.DashboardConfig{}, Dashboard{}, err
+		default:
+			return requests.DashboardConfig{}, Dashboard{}, fmt.Errorf(constants.ErrDBGetDoc)
+		}
 	}
 
+	if options.Units != "" {
+		dashboardConfig.Units = requests.Units{System: options.Units}
+	}
+
+	// Populate the dashboard from the external services
+	dashboard, err := buildDashboard(ctx, dashboardConfig)
+	if err != nil {
+		return requests.DashboardConfig{}, Dashboard{}, err
+	}
+	return dashboardConfig, dashboard, nil
+}
+
+// buildDashboard populates the dashboard for the given registration, of a country or of a region, in its units, and
+// with its computed features. The returned error message is safe to show to the client.
+func buildDashboard(ctx context.Context, dashboardConfig requests.DashboardConfig) (Dashboard, error) {
+	var dashboard Dashboard
+	var err error
+	if dashboardConfig.IsRegional() {
+		dashboard, err = buildRegionalDashboard(ctx, dashboardConfig)
+	} else {
+		dashboard, err = buildCountryDashboard(ctx, dashboardConfig)
+	}
+	if err != nil {
+		return Dashboard{}, err
+	}
+	dashboard = newUnitConverter(dashboardConfig).dashboard(dashboard)
+	return computeFeatures(ctx, dashboardConfig, dashboard), nil
+}
+
+// buildCountryDashboard populates the dashboard for the given registration with data from the external services, and
+// filters it by the registered features. The external services share a single deadline, and only the services
+// supplying enabled features are called. If the weather or currency service fails, the dashboard is partial: it
+// holds the features that could be supplied, and the errors of the failed services. As every feature depends on the
+// country data, failing to get it is an error. A registration without a country is named after the country. The
+// returned error message is safe to show to the client.
+func buildCountryDashboard(ctx context.Context, dashboardConfig requests.DashboardConfig) (Dashboard, error) {
+	ctx, cancel := context.WithTimeout(ctx, utils2.GetDashboardTimeout())
+	defer cancel()
+
+	// Create the response object and assign the country and iso code
+	var response Dashboard
+	response.Country = dashboardConfig.Country
+	response.IsoCode = dashboardConfig.IsoCode
+
+	// Get the country features first, as the other services need its coordinates and currencies
+	var features DashboardFeatures
+	countryFeatures, err := getCountryData(ctx, dashboardConfig.IsoCode)
+	if err != nil {
+		log.Println(constants.ErrDashboardGetCountryData + err.Error())
+		return Dashboard{}, dashboardError(err, constants.ErrDashboardGetCountryData)
+	}
+	if dashboardConfig.Country == "" {
+		dashboardConfig.Country = countryFeatures.Name
+		response.Country = countryFeatures.Name
+	}
+	countryFeatures = withPrimary(countryFeatures, dashboardConfig.Features)
+
 	// Merge the features
-	err = mergo.Merge(&features, currencyFeatures, mergo.WithOverride, mergo.WithoutDereference)
+	err = mergo.Merge(&features, countryFeatures, mergo.WithOverride, mergo.WithoutDereference)
 	if err != nil {
 		log.Println(constants.ErrDashboardMergingData + err.Error())
-		http.Error(
-			w,
-			constants.ErrDashboardMergingData,
-			http.StatusInternalServerError,
-		)
-		return
+		return Dashboard{}, fmt.Errorf(constants.ErrDashboardMergingData)
+	}
+
+	// Get the meteo and currency features concurrently
+	var meteoFeatures, currencyFeatures DashboardFeatures
+	var meteoErr, currencyErr error
+	var wg sync.WaitGroup
+
+	if hasWeatherFeatures(dashboardConfig.Features) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			meteoFeatures, meteoErr = getWeatherData(
+				ctx,
+				dashboardConfig.Features.WeatherLocation,
+				countryFeatures,
+				forecastDays(dashboardConfig.Features),
+			)
+		}()
+	}
+	if len(dashboardConfig.Features.TargetCurrencies) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			currencyFeatures, currencyErr = getExchangeRates(
+				ctx,
+				dashboardConfig.Features.TargetCurrencies,
+				countryFeatures.Currencies,
+				countryFeatures.Currency,
+			)
+		}()
+	}
+	wg.Wait()
+
+	// Record the failed services, and leave out the features they supply
+	dashboardErrors := make(map[string]DashboardError)
+	if meteoErr != nil {
+		log.Println(constants.ErrDashboardGetWeatherData + meteoErr.Error())
+		dashboardErrors[meteoSource] = DashboardError{
+			Features: enabledMeteoFeatures(dashboardConfig.Features),
+			Message:  dashboardError(meteoErr, constants.ErrDashboardGetWeatherData).Error(),
+		}
+		meteoFeatures = DashboardFeatures{}
+	}
+	if currencyErr != nil {
+		log.Println(constants.ErrDashboardGetCurrencyData + currencyErr.Error())
+		dashboardErrors[currencySource] = DashboardError{
+			Features: currencyFeatureNames(countryFeatures.Currencies),
+			Message:  dashboardError(currencyErr, constants.ErrDashboardGetCurrencyData).Error(),
+		}
+		currencyFeatures = DashboardFeatures{}
+	}
+
+	// Merge the features
+	for _, serviceFeatures := range []DashboardFeatures{meteoFeatures, currencyFeatures} {
+		err = mergo.Merge(&features, serviceFeatures, mergo.WithOverride, mergo.WithoutDereference)
+		if err != nil {
+			log.Println(constants.ErrDashboardMergingData + err.Error())
+			return Dashboard{}, fmt.Errorf(constants.ErrDashboardMergingData)
+		}
 	}
 
 	// Assign the features to the response
 	response.Features = features
 	response.LastRetrieval = time.Now()
+	response.Timezone, response.LocalRetrieval = localTime(features.Location, response.LastRetrieval)
 
 	// Filter the response by the config
 	filteredResponse, err := filterDashboardByConfig(response, dashboardConfig)
 	if err != nil {
 		log.Println(constants.ErrDashboardFilterByRegistration + err.Error())
-		http.Error(
-			w,
-			constants.ErrDashboardFilterByRegistration,
-			http.StatusInternalServerError,
-		)
-		return
+		return Dashboard{}, fmt.Errorf(constants.ErrDashboardFilterByRegistration)
 	}
 
-	// Check if any notifications are registered for the event
-	foundNotifications, err4 := notifications.FindNotificationsByCountry(
-		requests.EventInvoke,
-		filteredResponse.IsoCode,
-	)
-	if err4 != nil {
-		log.Println(constants.ErrNotificationsGetDocFromDB, err4.Error())
-		http.Error(w, constants.ErrNotificationsGetDocFromDB, http.StatusInternalServerError)
-		return
-	}
-
-	// If found, invoke the notifications
-	if len(foundNotifications) > 0 {
-		for _, n := range foundNotifications {
-			notifications.InvokeNotification(n)
-		}
+	if len(dashboardErrors) > 0 {
+		filteredResponse.Errors = dashboardErrors
 	}
 
-	// Marshal the status object to JSON
-	marshaled, err := json.MarshalIndent(
-		filteredResponse,
-		"",
-		"\t",
-	)
-	if err != nil {
-		log.Println(constants.ErrJsonMarshal + err.Error())
-		http.Error(w, constants.ErrJsonMarshal, http.StatusInternalServerError)
-		return
-	}
-
-	// Write the JSON to the response
-	_, err = w.Write(marshaled)
-	if err != nil {
-		log.Println(constants.ErrWriteResponse + err.Error())
-		http.Error(w, constants.ErrWriteResponse, http.StatusInternalServerError)
-		return
-	}
+	return filteredResponse, nil
 }
 
-// getMeteoData gets the meteo data for the given coordinates.
-// This data includes the mean temperature and precipitation.
-func getMeteoData(coordinates *inhouse.Coordinates) (dashboardFeatures, error) {
-	// Get the weather data from the meteo API
-	r, err1 := http.NewRequest(
-		http.MethodGet,
-		fmt.Sprintf(
-			"%s?latitude=%f&longitude=%f&hourly=temperature_2m,precipitation&timezone=Europe%%2FBerlin&forecast_days=1",
-			utils2.CurrentMeteoApi, coordinates.Latitude, coordinates.Longitude,
-		),
-		nil,
-	)
-	if err1 != nil {
-		log.Println(constants.ErrExternalRequest, err1.Error())
-		return dashboardFeatures{}, fmt.Errorf(constants.ErrExternalRequest)
+// dashboardError returns the error to show to the client for an error from an external service. Running out of time
+// is reported as such, instead of as the given message.
+func dashboardError(err error, message string) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf(constants.ErrDashboardTimeout)
 	}
+	return fmt.Errorf(message)
+}
 
-	r.Header.Add("content-type", "application/json")
+// Caches of the data from the providers, with a TTL per kind of data
+var (
+	countryCache  = cache.New[providers.Country](countrySource, utils2.GetCountryCacheTTL())
+	meteoCache    = cache.New[providers.Forecast](meteoSource, utils2.GetWeatherCacheTTL())
+	currencyCache = cache.New[providers.ExchangeRates](currencySource, utils2.GetCurrencyCacheTTL())
+)
 
-	// Issue request
-	res, err2 := utils2.Client.Do(r)
-	if err2 != nil {
-		log.Println(constants.ErrExternalResponse, err2.Error())
-		return dashboardFeatures{}, fmt.Errorf(constants.ErrExternalResponse)
+// getMeteoData gets the weather for the given coordinates for the given number of days, starting today. The weather
+// features are those of today, and the forecast has the weather features of every day.
+func getMeteoData(ctx context.Context, coordinates *inhouse.Coordinates, days int) (DashboardFeatures, error) {
+	if coordinates == nil {
+		return DashboardFeatures{}, fmt.Errorf(constants.ErrDashboardNoCoordinates)
 	}
 
-	// Decode JSON
-	var meteo responses.MeteoForecastResponse
-	err3 := json.NewDecoder(res.Body).Decode(&meteo)
-	if err3 != nil {
-		log.Println(constants.ErrJsonDecode, err3.Error())
-		return dashboardFeatures{}, fmt.Errorf(constants.ErrJsonDecode)
+	provider := providers.Weather()
+	forecast, err := meteoCache.Get(
+		ctx,
+		fmt.Sprintf("%f,%f,%d", coordinates.Latitude, coordinates.Longitude, days),
+		func() (providers.Forecast, error) {
+			return provider.Forecast(context.Background(), *coordinates, days)
+		},
+	)
+	if err != nil {
+		return DashboardFeatures{}, err
 	}
 
-	// Gets the average of all hourly temperatures and rounds to 5 decimal points
-	averageTemperature := float64(int(average(meteo.Hourly.Temperature2M)*100000)) / 100000
-	averagePrecipitation := float64(int(average(meteo.Hourly.Precipitation)*100000)) / 100000
-
-	features := dashboardFeatures{
-		Temperature:   &averageTemperature,
-		Precipitation: &averagePrecipitation,
+	// A forecast without any hours has no weather, which is a failure of the service rather than a dashboard without it
+	forecastDays := summarizeDays(forecast)
+	if len(forecastDays) == 0 {
+		log.Println(constants.ErrDashboardGetWeatherData + "no forecast")
+		return DashboardFeatures{}, fmt.Errorf(constants.ErrDashboardGetWeatherData)
 	}
 
+	features := DashboardFeatures{
+		Forecast: forecastDays,
+		Location: forecast.Location,
+	}.withWeather(forecastDays[0].WeatherFeatures)
+
 	return features, nil
 }
 
-// getCountryData gets the country data for the given ISO code. This data includes the capital, coordinates, population,
-// area, and currency.
-func getCountryData(isoCode string) (dashboardFeatures, error) {
-	// Get the country data from the restcountries API
-	r, err1 := http.NewRequest(
-		http.MethodGet,
-		utils2.CurrentRestCountriesApi+"alpha/"+isoCode+"?fields=name,cca2,currencies,capital,latlng,area,population",
-		nil,
+// getCountryData gets the country data for the given ISO code. This data includes the capitals, coordinates,
+// population, area, and currencies. The primary capital is the first one, and the primary currency the first one by
+// code, unless the registration chooses others with withPrimary.
+func getCountryData(ctx context.Context, isoCode string) (DashboardFeatures, error) {
+	provider := providers.Countries()
+	country, err := countryCache.Get(
+		ctx,
+		strings.ToUpper(isoCode),
+		func() (providers.Country, error) {
+			return provider.Country(context.Background(), isoCode)
+		},
 	)
-	if err1 != nil {
-		log.Println(constants.ErrExternalRequest, err1.Error())
-		return dashboardFeatures{}, fmt.Errorf(constants.ErrExternalRequest)
-	}
-
-	r.Header.Add("content-type", "application/json")
+	if err != nil {
+		return DashboardFeatures{}, err
+	}
+
+	// Copy the values the features point to, as the country is shared by the cache
+	population := country.Population
+	area := country.Area
+	flag := country.Flag
+	features := DashboardFeatures{
+		Name:               country.Name,
+		Capitals:           slices.Clone(country.Capitals),
+		Coordinates:        copyCoordinates(country.Coordinates),
+		Population:         &population,
+		Area:               &area,
+		Currencies:         slices.Clone(country.Currencies),
+		Location:           country.Location,
+		CapitalCoordinates: copyCoordinates(country.CapitalCoordinates),
+		Languages:          slices.Clone(country.Languages),
+		Borders:            slices.Clone(country.Borders),
+		Region:             nonEmpty(country.Region),
+		Subregion:          nonEmpty(country.Subregion),
+		Flag:               &flag,
+		Timezones:          slices.Clone(country.Timezones),
+		CallingCodes:       slices.Clone(country.CallingCodes),
+		DrivingSide:        nonEmpty(country.DrivingSide),
+		Translations:       maps.Clone(country.Translations),
+	}
+	if country.Gini != nil {
+		gini := *country.Gini
+		features.Gini = &gini
+	}
+
+	return withPrimary(features, requests.ConfigFeatures{}), nil
+}
 
-	// Issue request
-	res, err2 := utils2.Client.Do(r)
-	if err2 != nil {
-		log.Println(constants.ErrExternalResponse, err2.Error())
-		return dashboardFeatures{}, fmt.Errorf(constants.ErrExternalResponse)
+// withPrimary returns the country features with the primary capital and currency chosen by the registration, or the
+// first ones if the registration chooses none, or ones the country does not have. Countries without capitals have no
+// capital, and countries without currencies no currency.
+func withPrimary(features DashboardFeatures, config requests.ConfigFeatures) DashboardFeatures {
+	features.Capital = nil
+	if len(features.Capitals) > 0 {
+		capital := features.Capitals[0]
+		if slices.Contains(features.Capitals, config.PrimaryCapital) {
+			capital = config.PrimaryCapital
+		}
+		features.Capital = &capital
 	}
 
-	// Decode JSON
-	var country responses.ResponseFromRestcountries
-	err3 := json.NewDecoder(res.Body).Decode(&country)
-	if err3 != nil {
-		log.Println(constants.ErrJsonDecode, err3.Error())
-		return dashboardFeatures{}, fmt.Errorf(constants.ErrJsonDecode)
+	features.Currency = responses.Currency{}
+	if len(features.Currencies) > 0 {
+		features.Currency = features.Currencies[0]
+		for _, currency := range features.Currencies {
+			if strings.EqualFold(currency.Code, config.PrimaryCurrency) {
+				features.Currency = currency
+			}
+		}
 	}
 
-	if country.Name.Common == "" {
-		log.Println(constants.ErrDashboardCountryNotFound)
-		return dashboardFeatures{}, fmt.Errorf(constants.ErrDashboardCountryNotFound)
+	return features
+}
+
+// nonEmpty returns a pointer to the given string, or nil if it is empty, so unknown values are left out.
+func nonEmpty(value string) *string {
+	if value == "" {
+		return nil
 	}
+	return &value
+}
 
-	lat := country.Latlng[0]
-	lng := country.Latlng[1]
-	// Task specifies to take the first capital where multiple capitals are available
-	capital := country.Capital[0]
-	// Same goes for currency
-	var currency responses.Currency
-	for key, value := range country.Currencies {
-		currency = value
-		currency.Code = key
-		break
+// copyCoordinates returns a copy of the given coordinates, which may be nil.
+func copyCoordinates(coordinates *inhouse.Coordinates) *inhouse.Coordinates {
+	if coordinates == nil {
+		return nil
 	}
+	coordinatesCopy := *coordinates
+	return &coordinatesCopy
+}
 
-	features := dashboardFeatures{
-		Capital: &capital,
-		Coordinates: &inhouse.Coordinates{
-			Latitude:  lat,
-			Longitude: lng,
-		},
-		Population: &country.Population,
-		Area:       &country.Area,
-		Currency:   currency,
+// getExchangeRates gets the exchange rates to the given target currencies from every given currency of a country. The
+// target currencies are those of the primary currency, and the exchange rates are those of every currency if the
+// country has several.
+func getExchangeRates(
+	ctx context.Context,
+	targetCurrencies []string,
+	currencies []responses.Currency,
+	primary responses.Currency,
+) (DashboardFeatures, error) {
+	if len(currencies) == 0 {
+		return DashboardFeatures{}, fmt.Errorf(constants.ErrDashboardNoCurrency)
+	}
+
+	// Get the exchange rates from every currency concurrently
+	currencyFeatures := make([]DashboardFeatures, len(currencies))
+	currencyErrs := make([]error, len(currencies))
+	var wg sync.WaitGroup
+	for i := range currencies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			currencyFeatures[i], currencyErrs[i] = getCurrencyData(ctx, targetCurrencies, currencies[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var features DashboardFeatures
+	for i, currency := range currencies {
+		if currencyErrs[i] != nil {
+			return DashboardFeatures{}, currencyErrs[i]
+		}
+		if currency.Code == primary.Code {
+			features.TargetCurrencies = currencyFeatures[i].TargetCurrencies
+			features.InverseRates = currencyFeatures[i].InverseRates
+			features.CrossRates = currencyFeatures[i].CrossRates
+		}
+		if len(currencies) > 1 {
+			if features.ExchangeRates == nil {
+				features.ExchangeRates = make(map[string]map[string]float64, len(currencies))
+			}
+			features.ExchangeRates[currency.Code] = currencyFeatures[i].TargetCurrencies
+		}
 	}
 
 	return features, nil
 }
 
-// getCurrencyData gets the currency data for the given target currencies. This data includes the exchange rates.
+// currencyFeatureNames returns the names of the features supplied by the currency service for a country with the
+// given currencies.
+func currencyFeatureNames(currencies []responses.Currency) []string {
+	if len(currencies) > 1 {
+		return []string{"targetCurrencies", "inverseRates", "crossRates", "exchangeRates"}
+	}
+	return []string{"targetCurrencies", "inverseRates", "crossRates"}
+}
+
+// getCurrencyData gets the currency data for the given target currencies. This data includes the exchange rates, and
+// the inverse and cross rates derived from them.
 func getCurrencyData(
+	ctx context.Context,
 	targetCurrencies []string,
 	exchangeCurrency responses.Currency,
-) (dashboardFeatures, error) {
-	featuresFromCurrency := dashboardFeatures{
+) (DashboardFeatures, error) {
+	featuresFromCurrency := DashboardFeatures{
 		TargetCurrencies: make(map[string]float64),
 	}
-	// Get the exchange rates from the currency API
-	r, err1 := http.NewRequest(
-		http.MethodGet,
-		fmt.Sprintf(
-			"%s%s",
-			utils2.CurrentCurrencyApi, exchangeCurrency.Code,
-		),
-		nil,
-	)
-	if err1 != nil {
-		log.Println(constants.ErrExternalRequest, err1.Error())
-		return dashboardFeatures{}, fmt.Errorf(constants.ErrExternalRequest)
-	}
-
-	r.Header.Add("content-type", "application/json")
 
-	// Issue request
-	res, err2 := utils2.Client.Do(r)
-	if err2 != nil {
-		log.Println(constants.ErrExternalResponse, err2.Error())
-		return dashboardFeatures{}, fmt.Errorf(constants.ErrExternalResponse)
-	}
-
-	// Decode JSON
-	var response responses.ResponseFromCurrency
-	err3 := json.NewDecoder(res.Body).Decode(&response)
-	if err3 != nil {
-		log.Println(constants.ErrJsonDecode, err3.Error())
-		return dashboardFeatures{}, fmt.Errorf(constants.ErrJsonDecode)
+	// The exchange rates are cached by base currency, so they are shared by all target currencies
+	provider := providers.Currencies()
+	exchangeRates, err := currencyCache.Get(
+		ctx,
+		exchangeCurrency.Code,
+		func() (providers.ExchangeRates, error) {
+			return provider.ExchangeRates(context.Background(), exchangeCurrency.Code)
+		},
+	)
+	if err != nil {
+		return DashboardFeatures{}, err
 	}
 
 	// Get the exchange rates for the target currencies
 	for _, targetCurrency := range targetCurrencies {
-		if _, ok := response.Rates[targetCurrency]; !ok {
+		if _, ok := exchangeRates.Rates[targetCurrency]; !ok {
 			// Not returning error, just setting the rate to 0
 			featuresFromCurrency.TargetCurrencies[targetCurrency] = 0
 		} else {
-			featuresFromCurrency.TargetCurrencies[targetCurrency] = response.Rates[targetCurrency]
+			featuresFromCurrency.TargetCurrencies[targetCurrency] = exchangeRates.Rates[targetCurrency]
 		}
 	}
+	featuresFromCurrency.InverseRates, featuresFromCurrency.CrossRates = deriveRates(
+		featuresFromCurrency.TargetCurrencies,
+	)
 
 	return featuresFromCurrency, nil
 }
 
+// localTime returns the name of the given timezone and the given time in it, or no time if the timezone is unknown.
+func localTime(location *time.Location, t time.Time) (string, *time.Time) {
+	if location == nil {
+		return "", nil
+	}
+	local := t.In(location)
+	return location.String(), &local
+}
+
 // average calculates the mean of a slice of float64 elements.
 func average(elements []float64) float64 {
 	var sum float64
@@ -403,28 +703,33 @@ func average(elements []float64) float64 {
 }
 
 // filterDashboardByConfig filters the dashboard features by the given config.
-func filterDashboardByConfig(oldDashboard dashboard, config requests.DashboardConfig) (
-	dashboard,
+func filterDashboardByConfig(oldDashboard Dashboard, config requests.DashboardConfig) (
+	Dashboard,
 	error,
 ) {
 	if config.Country != oldDashboard.Country {
-		return dashboard{}, fmt.Errorf(constants.ErrDashboardCountryNotMatch)
+		return Dashboard{}, fmt.Errorf(constants.ErrDashboardCountryNotMatch)
 	}
 	// Returns a new dashboard with the features filtered by the config
-	newDashboard := dashboard{
-		Country:       oldDashboard.Country,
-		IsoCode:       oldDashboard.IsoCode,
-		LastRetrieval: oldDashboard.LastRetrieval,
+	newDashboard := Dashboard{
+		Country:        oldDashboard.Country,
+		IsoCode:        oldDashboard.IsoCode,
+		LastRetrieval:  oldDashboard.LastRetrieval,
+		Timezone:       oldDashboard.Timezone,
+		LocalRetrieval: oldDashboard.LocalRetrieval,
 	}
 
-	if config.Features.Temperature {
-		newDashboard.Features.Temperature = oldDashboard.Features.Temperature
-	}
-	if config.Features.Precipitation {
-		newDashboard.Features.Precipitation = oldDashboard.Features.Precipitation
+	newDashboard.Features = newDashboard.Features.withWeather(
+		filterWeatherByConfig(oldDashboard.Features.weather(), config.Features),
+	)
+	newDashboard.Features.Forecast = filterForecastByConfig(oldDashboard.Features.Forecast, config.Features)
+	if hasWeatherFeatures(config.Features) {
+		newDashboard.Features.WeatherLocation = oldDashboard.Features.WeatherLocation
+		newDashboard.Features.WeatherPoints = filterPointsByConfig(oldDashboard.Features.WeatherPoints, config.Features)
 	}
 	if config.Features.Capital {
 		newDashboard.Features.Capital = oldDashboard.Features.Capital
+		newDashboard.Features.Capitals = oldDashboard.Features.Capitals
 	}
 	if config.Features.Coordinates {
 		newDashboard.Features.Coordinates = oldDashboard.Features.Coordinates
@@ -435,6 +740,31 @@ func filterDashboardByConfig(oldDashboard dashboard, config requests.DashboardCo
 	if config.Features.Area {
 		newDashboard.Features.Area = oldDashboard.Features.Area
 	}
+	if config.Features.Languages {
+		newDashboard.Features.Languages = oldDashboard.Features.Languages
+	}
+	if config.Features.Borders {
+		newDashboard.Features.Borders = oldDashboard.Features.Borders
+	}
+	if config.Features.Region {
+		newDashboard.Features.Region = oldDashboard.Features.Region
+		newDashboard.Features.Subregion = oldDashboard.Features.Subregion
+	}
+	if config.Features.Flag {
+		newDashboard.Features.Flag = oldDashboard.Features.Flag
+	}
+	if config.Features.Timezones {
+		newDashboard.Features.Timezones = oldDashboard.Features.Timezones
+	}
+	if config.Features.CallingCodes {
+		newDashboard.Features.CallingCodes = oldDashboard.Features.CallingCodes
+	}
+	if config.Features.DrivingSide {
+		newDashboard.Features.DrivingSide = oldDashboard.Features.DrivingSide
+	}
+	if config.Features.Gini {
+		newDashboard.Features.Gini = oldDashboard.Features.Gini
+	}
 
 	// Translocate the target currencies
 	newDashboard.Features.TargetCurrencies = make(map[string]float64)
@@ -442,7 +772,20 @@ func filterDashboardByConfig(oldDashboard dashboard, config requests.DashboardCo
 		newDashboard.Features.TargetCurrencies[key] = value
 	}
 
+	// Translocate the rates derived from the target currencies
+	newDashboard.Features.InverseRates = oldDashboard.Features.InverseRates
+	newDashboard.Features.CrossRates = oldDashboard.Features.CrossRates
+
+	// Translocate the exchange rates from every currency
+	if len(oldDashboard.Features.ExchangeRates) > 0 {
+		newDashboard.Features.ExchangeRates = make(map[string]map[string]float64)
+		for key, value := range oldDashboard.Features.ExchangeRates {
+			newDashboard.Features.ExchangeRates[key] = value
+		}
+	}
+
 	newDashboard.Features.Currency = oldDashboard.Features.Currency
+	newDashboard.Features.Currencies = oldDashboard.Features.Currencies
 
 	return newDashboard, nil
 }
@@ -1,10 +1,16 @@
 package dashboards
 
 import (
+	"assignment-2/internal/cache"
+	"assignment-2/internal/constants"
 	"assignment-2/internal/http/datatransfers/inhouse"
 	"assignment-2/internal/http/datatransfers/requests"
 	"assignment-2/internal/http/datatransfers/responses"
 	"assignment-2/internal/mock"
+	"assignment-2/internal/providers"
+	"assignment-2/internal/utils"
+	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
@@ -30,17 +36,26 @@ func TestHandlerWithID(t *testing.T) {
 	tests := []struct {
 		name       string
 		method     string
+		target     string
 		statusCode int
 	}{
 		{
 			name:       "NegativeTestHandlerWithID",
 			method:     http.MethodOptions,
+			target:     "/",
 			statusCode: http.StatusNotImplemented,
 		},
 		{
-			name:       "PositiveTestHandlerWithID",
+			name:       "NoIDTestHandlerWithID",
 			method:     http.MethodGet,
-			statusCode: http.StatusInternalServerError,
+			target:     "/",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "InvalidAmountTestHandlerWithID",
+			method:     http.MethodGet,
+			target:     "/?id=1&amount=-1500",
+			statusCode: http.StatusBadRequest,
 		},
 	}
 
@@ -49,7 +64,7 @@ func TestHandlerWithID(t *testing.T) {
 		t.Run(
 			tt.name, func(t *testing.T) {
 				// Create a mock request
-				req := httptest.NewRequest(tt.method, "/", nil)
+				req := httptest.NewRequest(tt.method, tt.target, nil)
 
 				// Create a mock response recorder
 				w := httptest.NewRecorder()
@@ -70,6 +85,26 @@ func TestHandlerWithID(t *testing.T) {
 	}
 }
 
+func Test_dashboardErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  string
+		want int
+	}{
+		{err: constants.ErrIDInvalid, want: http.StatusBadRequest},
+		{err: constants.ErrDashboardUnits, want: http.StatusBadRequest},
+		{err: constants.ErrDashboardAmount, want: http.StatusBadRequest},
+		{err: constants.ErrDBDocNotFound, want: http.StatusNotFound},
+		{err: constants.ErrDashboardRegionNotFound, want: http.StatusNotFound},
+		{err: constants.ErrDashboardTimeout, want: http.StatusGatewayTimeout},
+		{err: constants.ErrDBGetDoc, want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := dashboardErrorStatus(fmt.Errorf(tt.err)); got != tt.want {
+			t.Errorf("dashboardErrorStatus(%q) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
 func Test_average(t *testing.T) {
 	type args struct {
 		elements []float64
@@ -101,19 +136,19 @@ func Test_average(t *testing.T) {
 
 func Test_filterDashboardByConfig(t *testing.T) {
 	type args struct {
-		oldDashboard dashboard
+		oldDashboard Dashboard
 		config       requests.DashboardConfig
 	}
 	tests := []struct {
 		name    string
 		args    args
-		want    dashboard
+		want    Dashboard
 		wantErr bool
 	}{
 		{
 			name: "Test_filterDashboardByConfigAllFalse",
 			args: args{
-				oldDashboard: dashboard{},
+				oldDashboard: Dashboard{},
 				config: requests.DashboardConfig{
 					ID:      "",
 					Country: "",
@@ -130,16 +165,16 @@ func Test_filterDashboardByConfig(t *testing.T) {
 					LastChange: time.Time{},
 				},
 			},
-			want:    dashboard{},
+			want:    Dashboard{},
 			wantErr: false,
 		},
 		{
 			name: "Test_filterDashboardByConfigAllTrue",
 			args: args{
-				oldDashboard: dashboard{
+				oldDashboard: Dashboard{
 					Country: "Test",
 					IsoCode: "Test",
-					Features: dashboardFeatures{
+					Features: DashboardFeatures{
 						Temperature:      new(float64),
 						Precipitation:    new(float64),
 						Capital:          new(string),
@@ -167,10 +202,10 @@ func Test_filterDashboardByConfig(t *testing.T) {
 					LastChange: time.Time{},
 				},
 			},
-			want: dashboard{
+			want: Dashboard{
 				Country: "Test",
 				IsoCode: "Test",
-				Features: dashboardFeatures{
+				Features: DashboardFeatures{
 					Temperature:      new(float64),
 					Precipitation:    new(float64),
 					Capital:          new(string),
@@ -304,7 +339,7 @@ func Test_getCountryData(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    args
-		want    dashboardFeatures
+		want    DashboardFeatures
 		wantErr bool
 	}{
 		{
@@ -312,7 +347,7 @@ func Test_getCountryData(t *testing.T) {
 			args: args{
 				isoCode: "NO",
 			},
-			want: dashboardFeatures{
+			want: DashboardFeatures{
 				Capital:    new(string),
 				Population: new(int),
 				Area:       new(float64),
@@ -328,7 +363,7 @@ func Test_getCountryData(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
-				got, err := getCountryData(tt.args.isoCode)
+				got, err := getCountryData(context.Background(), tt.args.isoCode)
 				if (err != nil) != tt.wantErr {
 					t.Errorf("getCountryData() error = %v, wantErr %v", err, tt.wantErr)
 					return
@@ -391,7 +426,7 @@ func Test_getCurrencyData(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    args
-		want    dashboardFeatures
+		want    DashboardFeatures
 		wantErr bool
 	}{
 		{
@@ -404,7 +439,7 @@ func Test_getCurrencyData(t *testing.T) {
 					Code:   "NOK",
 				},
 			},
-			want: dashboardFeatures{
+			want: DashboardFeatures{
 				TargetCurrencies: map[string]float64{
 					"USD": 0.093687, // Magic number from json file
 					"EUR": 0.086289, // Magic number from json file
@@ -417,7 +452,7 @@ func Test_getCurrencyData(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
-				got, err := getCurrencyData(tt.args.targetCurrencies, tt.args.exchangeCurrency)
+				got, err := getCurrencyData(context.Background(), tt.args.targetCurrencies, tt.args.exchangeCurrency)
 				if (err != nil) != tt.wantErr {
 					t.Errorf("getCurrencyData() error = %v, wantErr %v", err, tt.wantErr)
 					return
@@ -455,6 +490,98 @@ func Test_getCurrencyData(t *testing.T) {
 	}
 }
 
+func Test_withPrimary(t *testing.T) {
+	zimbabwe := DashboardFeatures{
+		Capitals: []string{"Harare"},
+		Currencies: []responses.Currency{
+			{Name: "Botswana pula", Code: "BWP"},
+			{Name: "United States dollar", Code: "USD"},
+			{Name: "Zimbabwean dollar", Code: "ZWL"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		features     DashboardFeatures
+		config       requests.ConfigFeatures
+		wantCapital  string
+		wantCurrency string
+	}{
+		{
+			name:         "First capital and currency by default",
+			features:     zimbabwe,
+			wantCapital:  "Harare",
+			wantCurrency: "BWP",
+		},
+		{
+			name:         "Chosen currency",
+			features:     zimbabwe,
+			config:       requests.ConfigFeatures{PrimaryCurrency: "zwl"},
+			wantCapital:  "Harare",
+			wantCurrency: "ZWL",
+		},
+		{
+			name:         "Chosen capital and currency the country does not have",
+			features:     zimbabwe,
+			config:       requests.ConfigFeatures{PrimaryCapital: "Oslo", PrimaryCurrency: "NOK"},
+			wantCapital:  "Harare",
+			wantCurrency: "BWP",
+		},
+		{
+			name:        "Chosen capital",
+			features:    DashboardFeatures{Capitals: []string{"Pretoria", "Bloemfontein", "Cape Town"}},
+			config:      requests.ConfigFeatures{PrimaryCapital: "Cape Town"},
+			wantCapital: "Cape Town",
+		},
+		{
+			name:     "No capital and currency",
+			features: DashboardFeatures{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := withPrimary(tt.features, tt.config)
+
+				var capital string
+				if got.Capital != nil {
+					capital = *got.Capital
+				}
+				if capital != tt.wantCapital {
+					t.Errorf("withPrimary() capital = %q, want %q", capital, tt.wantCapital)
+				}
+				if got.Currency.Code != tt.wantCurrency {
+					t.Errorf("withPrimary() currency = %q, want %q", got.Currency.Code, tt.wantCurrency)
+				}
+			},
+		)
+	}
+}
+
+func Test_getExchangeRates(t *testing.T) {
+	currencies := []responses.Currency{{Code: "BWP"}, {Code: "ZWL"}}
+
+	got, err := getExchangeRates(context.Background(), []string{"EUR"}, currencies, currencies[1])
+	if err != nil {
+		t.Fatalf("getExchangeRates() error = %v", err)
+	}
+	// The stub responds with the rates of NOK for every currency
+	if got.TargetCurrencies["EUR"] != 0.086289 {
+		t.Errorf("getExchangeRates() target currencies = %v, want the rates of the primary currency", got.TargetCurrencies)
+	}
+	if len(got.ExchangeRates) != 2 || got.ExchangeRates["BWP"]["EUR"] != 0.086289 || got.ExchangeRates["ZWL"]["EUR"] != 0.086289 {
+		t.Errorf("getExchangeRates() exchange rates = %v, want the rates of every currency", got.ExchangeRates)
+	}
+	if got.InverseRates["EUR"] != 1/0.086289 || got.CrossRates["EUR"]["EUR"] != 1 {
+		t.Errorf("getExchangeRates() derived rates = %v, %v, want those of the primary currency", got.InverseRates, got.CrossRates)
+	}
+
+	_, err = getExchangeRates(context.Background(), []string{"EUR"}, nil, responses.Currency{})
+	if err == nil || err.Error() != constants.ErrDashboardNoCurrency {
+		t.Errorf("getExchangeRates() error = %v, want %v", err, constants.ErrDashboardNoCurrency)
+	}
+}
+
 func Test_getMeteoData(t *testing.T) {
 	type args struct {
 		coordinates *inhouse.Coordinates
@@ -462,7 +589,7 @@ func Test_getMeteoData(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    args
-		want    dashboardFeatures
+		want    DashboardFeatures
 		wantErr bool
 	}{
 		{
@@ -474,7 +601,7 @@ func Test_getMeteoData(t *testing.T) {
 					Longitude: 11,
 				},
 			},
-			want: dashboardFeatures{
+			want: DashboardFeatures{
 				Temperature:   new(float64),
 				Precipitation: new(float64),
 			},
@@ -483,7 +610,7 @@ func Test_getMeteoData(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
-				got, err := getMeteoData(tt.args.coordinates)
+				got, err := getMeteoData(context.Background(), tt.args.coordinates, 1)
 				if (err != nil) != tt.wantErr {
 					t.Errorf("getMeteoData() error = %v, wantErr %v", err, tt.wantErr)
 					return
@@ -501,5 +628,347 @@ func Test_getMeteoData(t *testing.T) {
 	}
 }
 
+func Test_getMeteoDataWithoutForecast(t *testing.T) {
+	meteo, meteoApi := meteoCache, utils.CurrentMeteoApi
+	t.Cleanup(
+		func() {
+			meteoCache, utils.CurrentMeteoApi = meteo, meteoApi
+		},
+	)
+	meteoCache = cache.New[providers.Forecast](meteoSource, time.Hour)
+	utils.CurrentMeteoApi = newJSONServer(t, `{"timezone": "UTC", "hourly": {"time": []}}`).URL + "/"
+
+	// A forecast without any hours is an error, so the dashboard is partial instead of silently without weather
+	_, err := getMeteoData(context.Background(), &inhouse.Coordinates{Latitude: 60, Longitude: 11}, 1)
+	if err == nil || err.Error() != constants.ErrDashboardGetWeatherData {
+		t.Errorf("getMeteoData() error = %v, want %v", err, constants.ErrDashboardGetWeatherData)
+	}
+}
+
 // Since all other functions are tested, we skip handler function,
 // as the untested code relies on the external functions
+
+// newSlowServer starts a server that responds with the given JSON body after the given delay.
+func newSlowServer(t *testing.T, delay time.Duration, body string) *httptest.Server {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(delay)
+				_, _ = w.Write([]byte(body))
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newJSONServer starts a server that responds to every request with the given JSON body.
+func newJSONServer(t *testing.T, body string) *httptest.Server {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(body))
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newFailingServer starts a server that responds to every request with an internal server error.
+func newFailingServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "upstream unavailable", http.StatusInternalServerError)
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_buildDashboard(t *testing.T) {
+	// Use empty caches, so the external services are called
+	countries, meteo, currencies := countryCache, meteoCache, currencyCache
+	restCountriesApi, meteoApi, currencyApi := utils.CurrentRestCountriesApi, utils.CurrentMeteoApi, utils.CurrentCurrencyApi
+	t.Cleanup(
+		func() {
+			countryCache, meteoCache, currencyCache = countries, meteo, currencies
+			utils.CurrentRestCountriesApi, utils.CurrentMeteoApi, utils.CurrentCurrencyApi = restCountriesApi, meteoApi, currencyApi
+		},
+	)
+
+	slowApi := newSlowServer(t, 300*time.Millisecond, "{}").URL + "/"
+	slowMeteoApi := newSlowServer(
+		t, 300*time.Millisecond, `{"timezone": "Europe/Oslo", "hourly": {"time": ["2024-04-17T12:00"]}}`,
+	).URL + "/"
+	timingOutApi := newSlowServer(t, time.Second, "{}").URL + "/"
+	failingApi := newFailingServer(t).URL + "/"
+	// Antarctica has no capital and no currency
+	antarcticaApi := newJSONServer(t, `{"name": {"common": "Antarctica"}, "cca2": "AQ", "latlng": [-90, 0]}`).URL + "/"
+	unreachableApi := "http://localhost:0/"
+	t.Setenv("DASHBOARD_TIMEOUT", "500ms")
+
+	allFeatures := requests.ConfigFeatures{
+		Temperature:      true,
+		Precipitation:    true,
+		Capital:          true,
+		TargetCurrencies: []string{"EUR"},
+	}
+
+	tests := []struct {
+		name             string
+		restCountriesApi string
+		meteoApi         string
+		currencyApi      string
+		features         requests.ConfigFeatures
+		wantErr          string
+		wantErrors       map[string]DashboardError
+		wantTimezone     string
+		wantLocation     *requests.WeatherLocation
+	}{
+		{
+			// Called one after another, the weather and currency services would take longer than the timeout
+			name:             "Weather and currency are fetched in parallel",
+			restCountriesApi: restCountriesApi,
+			meteoApi:         slowMeteoApi,
+			currencyApi:      slowApi,
+			features:         requests.ConfigFeatures{Temperature: true, TargetCurrencies: []string{"EUR"}},
+			wantTimezone:     "Europe/Oslo",
+		},
+		{
+			name:             "Services of disabled features are not called",
+			restCountriesApi: restCountriesApi,
+			meteoApi:         unreachableApi,
+			currencyApi:      unreachableApi,
+			features:         requests.ConfigFeatures{Capital: true, Area: true},
+			wantTimezone:     "UTC+01:00",
+		},
+		{
+			name:             "Slow country service times out",
+			restCountriesApi: timingOutApi,
+			meteoApi:         unreachableApi,
+			currencyApi:      unreachableApi,
+			features:         requests.ConfigFeatures{Capital: true},
+			wantErr:          constants.ErrDashboardTimeout,
+		},
+		{
+			name:             "Failing country service fails the dashboard",
+			restCountriesApi: failingApi,
+			meteoApi:         meteoApi,
+			currencyApi:      currencyApi,
+			features:         allFeatures,
+			wantErr:          constants.ErrDashboardGetCountryData,
+		},
+		{
+			name:             "Failing weather service gives a partial dashboard",
+			restCountriesApi: restCountriesApi,
+			meteoApi:         failingApi,
+			currencyApi:      currencyApi,
+			features:         allFeatures,
+			wantErrors: map[string]DashboardError{
+				meteoSource: {
+					Features: []string{"temperature", "precipitation"},
+					Message:  constants.ErrDashboardGetWeatherData,
+				},
+			},
+			wantTimezone: "UTC+01:00",
+		},
+		{
+			name:             "Failing currency service gives a partial dashboard",
+			restCountriesApi: restCountriesApi,
+			meteoApi:         meteoApi,
+			currencyApi:      failingApi,
+			features:         allFeatures,
+			wantErrors: map[string]DashboardError{
+				currencySource: {
+					Features: []string{"targetCurrencies", "inverseRates", "crossRates"},
+					Message:  constants.ErrDashboardGetCurrencyData,
+				},
+			},
+			wantTimezone: "Europe/Oslo",
+		},
+		{
+			name:             "Weather at the capital",
+			restCountriesApi: restCountriesApi,
+			meteoApi:         meteoApi,
+			currencyApi:      unreachableApi,
+			features: requests.ConfigFeatures{
+				Temperature:     true,
+				WeatherLocation: requests.WeatherLocation{Source: requests.WeatherAtCapital},
+			},
+			wantTimezone: "Europe/Oslo",
+			wantLocation: &requests.WeatherLocation{
+				Source: requests.WeatherAtCapital,
+				Points: []inhouse.Coordinates{{Latitude: 59.92, Longitude: 10.75}},
+			},
+		},
+		{
+			name:             "Weather at custom points reported separately",
+			restCountriesApi: restCountriesApi,
+			meteoApi:         meteoApi,
+			currencyApi:      unreachableApi,
+			features: requests.ConfigFeatures{
+				Temperature: true,
+				WeatherLocation: requests.WeatherLocation{
+					Source:   requests.WeatherAtPoints,
+					Points:   []inhouse.Coordinates{{Latitude: 60.39, Longitude: 5.32}, {Latitude: 63.43, Longitude: 10.39}},
+					Separate: true,
+				},
+			},
+			wantTimezone: "Europe/Oslo",
+			wantLocation: &requests.WeatherLocation{
+				Source:   requests.WeatherAtPoints,
+				Points:   []inhouse.Coordinates{{Latitude: 60.39, Longitude: 5.32}, {Latitude: 63.43, Longitude: 10.39}},
+				Separate: true,
+			},
+		},
+		{
+			name:             "Country without capital and currency",
+			restCountriesApi: antarcticaApi,
+			meteoApi:         unreachableApi,
+			currencyApi:      unreachableApi,
+			features:         requests.ConfigFeatures{Capital: true, Area: true, TargetCurrencies: []string{"EUR"}},
+			wantErrors: map[string]DashboardError{
+				currencySource: {
+					Features: []string{"targetCurrencies", "inverseRates", "crossRates"},
+					Message:  constants.ErrDashboardGetCurrencyData,
+				},
+			},
+		},
+		{
+			name:             "Slow weather service gives a partial dashboard",
+			restCountriesApi: restCountriesApi,
+			meteoApi:         timingOutApi,
+			currencyApi:      currencyApi,
+			features:         requests.ConfigFeatures{Precipitation: true, Capital: true},
+			wantErrors: map[string]DashboardError{
+				meteoSource: {
+					Features: []string{"precipitation"},
+					Message:  constants.ErrDashboardTimeout,
+				},
+			},
+			wantTimezone: "UTC+01:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				countryCache = cache.New[providers.Country](countrySource, time.Hour)
+				meteoCache = cache.New[providers.Forecast](meteoSource, time.Hour)
+				currencyCache = cache.New[providers.ExchangeRates](currencySource, time.Hour)
+				utils.CurrentRestCountriesApi, utils.CurrentMeteoApi, utils.CurrentCurrencyApi = tt.restCountriesApi, tt.meteoApi, tt.currencyApi
+
+				got, err := buildDashboard(
+					context.Background(), requests.DashboardConfig{
+						Country:  "Norway",
+						IsoCode:  "NO",
+						Features: tt.features,
+					},
+				)
+				if tt.wantErr != "" {
+					if err == nil || err.Error() != tt.wantErr {
+						t.Errorf("buildDashboard() error = %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("buildDashboard() error = %v, want no error", err)
+				}
+
+				if !reflect.DeepEqual(got.Errors, tt.wantErrors) {
+					t.Errorf("buildDashboard() errors = %v, want %v", got.Errors, tt.wantErrors)
+				}
+				if got.IsPartial() != (tt.wantErrors != nil) {
+					t.Errorf("IsPartial() = %v, want %v", got.IsPartial(), tt.wantErrors != nil)
+				}
+
+				// The features of the services that did not fail are still supplied
+				if tt.features.Capital && got.Features.Capital == nil && tt.restCountriesApi != antarcticaApi {
+					t.Errorf("buildDashboard() capital = nil, want the capital")
+				}
+				_, meteoFailed := tt.wantErrors[meteoSource]
+				if tt.features.Precipitation && (got.Features.Precipitation == nil) != meteoFailed {
+					t.Errorf("buildDashboard() precipitation = %v, want it set if the weather service did not fail", got.Features.Precipitation)
+				}
+				_, currencyFailed := tt.wantErrors[currencySource]
+				if len(tt.features.TargetCurrencies) > 0 && (len(got.Features.TargetCurrencies) == 0) != currencyFailed {
+					t.Errorf("buildDashboard() target currencies = %v, want them set if the currency service did not fail", got.Features.TargetCurrencies)
+				}
+
+				// The dashboard states where the weather is measured, and the weather at every point if separate
+				if tt.wantLocation != nil {
+					if !reflect.DeepEqual(got.Features.WeatherLocation, tt.wantLocation) {
+						t.Errorf("buildDashboard() weather location = %+v, want %+v", got.Features.WeatherLocation, tt.wantLocation)
+					}
+					if wantPoints := len(tt.wantLocation.Points); tt.wantLocation.Separate && len(got.Features.WeatherPoints) != wantPoints {
+						t.Errorf("buildDashboard() weather points = %+v, want %v points", got.Features.WeatherPoints, wantPoints)
+					}
+				}
+
+				// The local time is that of the weather location, or else of the country
+				if got.Timezone != tt.wantTimezone {
+					t.Errorf("buildDashboard() timezone = %v, want %v", got.Timezone, tt.wantTimezone)
+				}
+				if tt.wantTimezone == "" {
+					if got.LocalRetrieval != nil {
+						t.Errorf("buildDashboard() local retrieval = %v, want none for an unknown timezone", got.LocalRetrieval)
+					}
+				} else if got.LocalRetrieval == nil || !got.LocalRetrieval.Equal(got.LastRetrieval) ||
+					got.LocalRetrieval.Location().String() != tt.wantTimezone {
+					t.Errorf("buildDashboard() local retrieval = %v, want %v in %v", got.LocalRetrieval, got.LastRetrieval, tt.wantTimezone)
+				}
+			},
+		)
+	}
+}
+
+func Test_writeDashboard(t *testing.T) {
+	tests := []struct {
+		name         string
+		dashboard    Dashboard
+		wantPartial  string
+		wantLanguage string
+	}{
+		{
+			name:        "Complete dashboard",
+			dashboard:   Dashboard{Country: "Norway", IsoCode: "NO"},
+			wantPartial: "",
+		},
+		{
+			name: "Partial dashboard",
+			dashboard: Dashboard{
+				Country: "Norway",
+				IsoCode: "NO",
+				Errors: map[string]DashboardError{
+					meteoSource: {Features: []string{"temperature"}, Message: constants.ErrDashboardGetWeatherData},
+				},
+			},
+			wantPartial: "true",
+		},
+		{
+			name:         "Localized dashboard",
+			dashboard:    Dashboard{Country: "Norwegen", IsoCode: "NO", Language: "de"},
+			wantLanguage: "de",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rr := httptest.NewRecorder()
+				writeDashboard(rr, tt.dashboard)
+
+				if rr.Code != http.StatusOK {
+					t.Errorf("writeDashboard() status = %v, want %v", rr.Code, http.StatusOK)
+				}
+				if got := rr.Header().Get(constants.DashboardPartialHeader); got != tt.wantPartial {
+					t.Errorf("writeDashboard() %s header = %q, want %q", constants.DashboardPartialHeader, got, tt.wantPartial)
+				}
+				if got := rr.Header().Get("Content-Language"); got != tt.wantLanguage {
+					t.Errorf("writeDashboard() Content-Language header = %q, want %q", got, tt.wantLanguage)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,351 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	utils2 "assignment-2/internal/utils"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DashboardDelta is the change of the numeric features of a dashboard since its previous snapshot, by the path of the
+// feature, such as "temperature", "targetCurrencies.EUR" or "aggregates.population". Features that are not in both
+// dashboards are left out.
+type DashboardDelta struct {
+	Since   time.Time          `json:"since"`
+	Changes map[string]float64 `json:"changes"`
+}
+
+// Implemented methods for the history endpoint
+var implementedMethodsHistory = []string{
+	http.MethodGet,
+}
+
+// Endpoint for the history of dashboards
+var dashboardsHistoryEndpoint = inhouse.Endpoint{
+	Path:    constants.DashboardsPath + "{id}/history",
+	Methods: implementedMethodsHistory,
+	Description: "Endpoint for the history of dashboards. Returns the stored snapshots of the dashboard, optionally " +
+		"between the from and to times and limited to the comma-separated features.",
+}
+
+// Name of the Firestore field of a snapshot that snapshots are ordered and selected by
+const snapshotTimeField = "LastRetrieval"
+
+// Layout of the dates accepted as from and to, besides RFC 3339 times
+const historyDateLayout = "2006-01-02"
+
+// HistoryHandler handles the /dashboard/v1/dashboards/{id}/history path.
+// It currently only supports GET requests
+func HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", "application/json")
+	// Switch on the HTTP request method
+	switch r.Method {
+	case http.MethodGet:
+		handleDashboardsHistoryRequest(w, r)
+
+	default:
+		// If the method is not implemented, return an error with the allowed methods
+		http.Error(
+			w, fmt.Sprintf(
+				"REST Method '%s' not supported. Currently only '%v' are supported.", r.Method,
+				implementedMethodsHistory,
+			), http.StatusNotImplemented,
+		)
+		return
+	}
+}
+
+// handleDashboardsHistoryRequest handles the GET request for the /dashboard/v1/dashboards/{id}/history path.
+// It is used to retrieve the snapshots of a dashboard, oldest first.
+func handleDashboardsHistoryRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := utils2.GetIDFromRequest(r)
+	if err != nil {
+		http.Error(w, constants.ErrIDInvalid, http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseHistoryRange(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	features := parseList(r.URL.Query().Get("features"))
+
+	snapshots, err := db.GetSubDocumentsBetween[inhouse.DashboardSnapshot](
+		id,
+		db.DashboardCollection,
+		db.SnapshotCollection,
+		snapshotTimeField,
+		from,
+		to,
+	)
+	if err != nil {
+		log.Println(constants.ErrDashboardHistory + err.Error())
+		switch err.Error() {
+		case constants.ErrIDInvalid:
+			http.Error(w, constants.ErrIDInvalid, http.StatusBadRequest)
+		case constants.ErrDBDocNotFound:
+			http.Error(w, constants.ErrDBDocNotFound, http.StatusNotFound)
+		default:
+			http.Error(w, constants.ErrDashboardHistory, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// The snapshots are stored as they were returned, so they are returned as stored, but for the features
+	history := make([]map[string]any, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		var dashboard map[string]any
+		if err := json.Unmarshal(snapshot.Dashboard, &dashboard); err != nil {
+			log.Println(constants.ErrJsonUnmarshal + err.Error())
+			http.Error(w, constants.ErrDashboardHistory, http.StatusInternalServerError)
+			return
+		}
+		history = append(history, filterSnapshot(dashboard, features))
+	}
+
+	marshaled, err := json.MarshalIndent(history, "", "\t")
+	if err != nil {
+		log.Println(constants.ErrJsonMarshal + err.Error())
+		http.Error(w, constants.ErrJsonMarshal, http.StatusInternalServerError)
+		return
+	}
+
+	_, err = w.Write(marshaled)
+	if err != nil {
+		log.Println(constants.ErrWriteResponse + err.Error())
+		http.Error(w, constants.ErrWriteResponse, http.StatusInternalServerError)
+		return
+	}
+}
+
+// parseHistoryRange parses the from and to parameters of a history request. Each is an RFC 3339 time or a date, where
+// a date is the start of the day as from and the end of the day as to, in UTC. Without from the history starts with
+// the oldest snapshot, and without to it ends now.
+func parseHistoryRange(query url.Values) (time.Time, time.Time, error) {
+	from, err := parseHistoryTime(query.Get("from"), false)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseHistoryTime(query.Get("to"), true)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf(constants.ErrDashboardHistoryRange)
+	}
+	return from, to, nil
+}
+
+// parseHistoryTime parses an RFC 3339 time or a date, which is the end of the day if endOfDay is set. An empty value
+// is the zero time.
+func parseHistoryTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(historyDateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(constants.ErrDashboardHistoryTime)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// parseList parses a comma-separated query parameter, such as the features of a history request, ignoring blanks. An
+// empty list of features selects every feature.
+func parseList(value string) []string {
+	var elements []string
+	for _, element := range strings.Split(value, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
+
+// filterSnapshot limits the features of a snapshot to the given ones, if any: those of the dashboard and its members,
+// the aggregates but the number of countries, and the changes of the delta. The dashboard is modified in place.
+func filterSnapshot(dashboard map[string]any, features []string) map[string]any {
+	if len(features) == 0 {
+		return dashboard
+	}
+
+	filterKeys(dashboard["features"], features)
+	if aggregates, ok := dashboard["aggregates"].(map[string]any); ok {
+		for key := range aggregates {
+			if key != "countries" && !slices.Contains(features, key) {
+				delete(aggregates, key)
+			}
+		}
+	}
+	if delta, ok := dashboard["delta"].(map[string]any); ok {
+		if changes, ok := delta["changes"].(map[string]any); ok {
+			for key := range changes {
+				// The path of a change starts with its feature, or with aggregates and the feature
+				path := strings.Split(strings.TrimPrefix(key, "aggregates."), ".")
+				if !slices.Contains(features, path[0]) {
+					delete(changes, key)
+				}
+			}
+		}
+	}
+	if members, ok := dashboard["members"].([]any); ok {
+		for _, member := range members {
+			if member, ok := member.(map[string]any); ok {
+				filterKeys(member["features"], features)
+			}
+		}
+	}
+	return dashboard
+}
+
+// filterKeys deletes the keys of a JSON object that are not among the given ones.
+func filterKeys(object any, keys []string) {
+	if object, ok := object.(map[string]any); ok {
+		for key := range object {
+			if !slices.Contains(keys, key) {
+				delete(object, key)
+			}
+		}
+	}
+}
+
+// recordSnapshot sets the delta of the populated dashboard of the registration with the given ID against its latest
+// snapshot, stores the dashboard as a new snapshot, and deletes the snapshots beyond the retention. As the history is
+// not worth failing the dashboard for, errors are only logged.
+func recordSnapshot(id string, dashboard *Dashboard) {
+	current, err := json.Marshal(dashboard)
+	if err != nil {
+		log.Println(constants.ErrJsonMarshal + err.Error())
+		return
+	}
+
+	previous, err := db.GetLatestSubDocument[inhouse.DashboardSnapshot](
+		id,
+		db.DashboardCollection,
+		db.SnapshotCollection,
+		snapshotTimeField,
+	)
+	switch {
+	case err == nil:
+		dashboard.Delta, err = computeDelta(previous.Dashboard, current, previous.LastRetrieval)
+		if err != nil {
+			log.Println(constants.ErrJsonUnmarshal + err.Error())
+		}
+	case err.Error() != constants.ErrDBDocNotFound:
+		log.Println(constants.ErrDashboardHistory + err.Error())
+	}
+
+	// Store the dashboard with its delta, as it is returned
+	if dashboard.Delta != nil {
+		current, err = json.Marshal(dashboard)
+		if err != nil {
+			log.Println(constants.ErrJsonMarshal + err.Error())
+			return
+		}
+	}
+	err = db.AddSubDocument[inhouse.DashboardSnapshot](
+		inhouse.DashboardSnapshot{LastRetrieval: dashboard.LastRetrieval, Dashboard: current},
+		id,
+		db.DashboardCollection,
+		db.SnapshotCollection,
+	)
+	if err != nil {
+		log.Println(constants.ErrDashboardSnapshot + err.Error())
+		return
+	}
+
+	var before time.Time
+	if retention := utils2.GetSnapshotRetention(); retention > 0 {
+		before = time.Now().Add(-retention)
+	}
+	err = db.PruneSubDocuments(
+		id,
+		db.DashboardCollection,
+		db.SnapshotCollection,
+		snapshotTimeField,
+		before,
+		utils2.GetSnapshotLimit(),
+	)
+	if err != nil {
+		log.Println(constants.ErrDBDeleteDoc + err.Error())
+	}
+}
+
+// computeDelta returns the change of the numeric features between two dashboards marshalled to JSON, the previous of
+// which was retrieved at the given time. Dashboards in different units are not compared, so there is no delta.
+func computeDelta(previous []byte, current []byte, since time.Time) (*DashboardDelta, error) {
+	previousValues, previousUnits, err := numericFeatures(previous)
+	if err != nil {
+		return nil, err
+	}
+	currentValues, currentUnits, err := numericFeatures(current)
+	if err != nil {
+		return nil, err
+	}
+	if previousUnits != currentUnits {
+		return nil, nil
+	}
+
+	delta := &DashboardDelta{Since: since, Changes: make(map[string]float64)}
+	for path, value := range currentValues {
+		if previousValue, ok := previousValues[path]; ok {
+			delta.Changes[path] = value - previousValue
+		}
+	}
+	return delta, nil
+}
+
+// numericFeatures returns the numeric features and aggregates of a dashboard marshalled to JSON, by their path, and the
+// units they are in, which are metric if not stated. Lists, such as the forecast, and the members of a regional
+// dashboard are left out.
+func numericFeatures(dashboard []byte) (map[string]float64, DashboardUnits, error) {
+	var fields struct {
+		Features   map[string]any  `json:"features"`
+		Aggregates map[string]any  `json:"aggregates"`
+		Units      *DashboardUnits `json:"units"`
+	}
+	if err := json.Unmarshal(dashboard, &fields); err != nil {
+		return nil, DashboardUnits{}, err
+	}
+
+	values := make(map[string]float64)
+	flattenNumbers("", fields.Features, values)
+	flattenNumbers("aggregates", fields.Aggregates, values)
+	if fields.Units == nil {
+		return values, resolveUnits(requests.Units{}), nil
+	}
+	return values, *fields.Units, nil
+}
+
+// flattenNumbers adds the numbers of a JSON value to values by their dotted path, starting with the given prefix.
+func flattenNumbers(prefix string, value any, values map[string]float64) {
+	switch value := value.(type) {
+	case float64:
+		values[prefix] = value
+	case map[string]any:
+		for key, element := range value {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenNumbers(key, element, values)
+		}
+	}
+}
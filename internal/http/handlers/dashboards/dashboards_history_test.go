@@ -0,0 +1,284 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		statusCode int
+		wantBody   string
+	}{
+		{
+			name:       "NegativeTestHistoryHandler",
+			method:     http.MethodPost,
+			target:     "/?id=1",
+			statusCode: http.StatusNotImplemented,
+		},
+		{
+			name:       "NoIDTestHistoryHandler",
+			method:     http.MethodGet,
+			target:     "/",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrIDInvalid,
+		},
+		{
+			name:       "InvalidTimeTestHistoryHandler",
+			method:     http.MethodGet,
+			target:     "/?id=1&from=yesterday",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardHistoryTime,
+		},
+		{
+			name:       "InvalidRangeTestHistoryHandler",
+			method:     http.MethodGet,
+			target:     "/?id=1&from=2024-04-02&to=2024-04-01",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardHistoryRange,
+		},
+	}
+
+	// Run the tests
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Create a mock request
+				req := httptest.NewRequest(tt.method, tt.target, nil)
+
+				// Create a mock response recorder
+				w := httptest.NewRecorder()
+
+				// Call the handler
+				HistoryHandler(w, req)
+
+				// Check if the status code matches expected
+				if w.Code != tt.statusCode {
+					log.Println("Testing: ", tt.name)
+					t.Errorf(
+						"handler returned wrong status code: got %v want %v",
+						w.Code, tt.statusCode,
+					)
+				}
+				if !strings.Contains(w.Body.String(), tt.wantBody) {
+					t.Errorf("handler returned body %q, want %q", w.Body.String(), tt.wantBody)
+				}
+			},
+		)
+	}
+}
+
+func Test_parseHistoryRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    url.Values
+		wantFrom time.Time
+		wantTo   time.Time
+		wantErr  string
+	}{
+		{
+			name:     "Dates cover whole days",
+			query:    url.Values{"from": {"2024-04-01"}, "to": {"2024-04-02"}},
+			wantFrom: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 4, 2, 23, 59, 59, 999999999, time.UTC),
+		},
+		{
+			name:     "Times are used as given",
+			query:    url.Values{"from": {"2024-04-01T12:00:00Z"}, "to": {"2024-04-01T13:00:00Z"}},
+			wantFrom: time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 4, 1, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "A single day",
+			query:    url.Values{"from": {"2024-04-01"}, "to": {"2024-04-01"}},
+			wantFrom: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 4, 1, 23, 59, 59, 999999999, time.UTC),
+		},
+		{
+			name:    "Invalid to",
+			query:   url.Values{"to": {"01.04.2024"}},
+			wantErr: constants.ErrDashboardHistoryTime,
+		},
+		{
+			name:    "From after to",
+			query:   url.Values{"from": {"2024-04-01T13:00:00Z"}, "to": {"2024-04-01T12:00:00Z"}},
+			wantErr: constants.ErrDashboardHistoryRange,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				from, to, err := parseHistoryRange(tt.query)
+				if tt.wantErr != "" {
+					if err == nil || err.Error() != tt.wantErr {
+						t.Errorf("parseHistoryRange() error = %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("parseHistoryRange() error = %v", err)
+				}
+				if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+					t.Errorf("parseHistoryRange() = %v, %v, want %v, %v", from, to, tt.wantFrom, tt.wantTo)
+				}
+			},
+		)
+	}
+
+	// Without from and to, the history is everything until now
+	from, to, err := parseHistoryRange(url.Values{})
+	if err != nil || !from.IsZero() || time.Since(to) > time.Minute {
+		t.Errorf("parseHistoryRange() = %v, %v, %v, want the zero time until now", from, to, err)
+	}
+}
+
+func Test_computeDelta(t *testing.T) {
+	since := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		previous string
+		current  string
+		want     map[string]float64
+	}{
+		{
+			name:     "Changes of features in both dashboards",
+			previous: `{"features": {"temperature": 4.5, "population": 100, "targetCurrencies": {"EUR": 0.1, "SEK": 1}}}`,
+			current: `{"features": {"temperature": 6, "population": 100, "area": 5,
+				"targetCurrencies": {"EUR": 0.2, "USD": 0.1}}}`,
+			want: map[string]float64{"temperature": 1.5, "population": 0, "targetCurrencies.EUR": 0.1},
+		},
+		{
+			name:     "Changes of aggregates",
+			previous: `{"features": {}, "aggregates": {"countries": 3, "population": 10}}`,
+			current:  `{"features": {}, "aggregates": {"countries": 2, "population": 7}}`,
+			want:     map[string]float64{"aggregates.countries": -1, "aggregates.population": -3},
+		},
+		{
+			name:     "Metric by default",
+			previous: `{"features": {"temperature": 4}}`,
+			current: `{"features": {"temperature": 5}, "units": {"temperature": "celsius", "precipitation": "mm",
+				"windSpeed": "km/h", "area": "km2"}}`,
+			want: map[string]float64{"temperature": 1},
+		},
+		{
+			name:     "Lists and text are not compared",
+			previous: `{"features": {"capital": "Oslo", "forecast": [{"temperature": 1}], "flag": {"emoji": "x"}}}`,
+			current:  `{"features": {"capital": "Bergen", "forecast": [{"temperature": 2}], "flag": {"emoji": "y"}}}`,
+			want:     map[string]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := computeDelta([]byte(tt.previous), []byte(tt.current), since)
+				if err != nil {
+					t.Fatalf("computeDelta() error = %v", err)
+				}
+				if !got.Since.Equal(since) {
+					t.Errorf("computeDelta() since = %v, want %v", got.Since, since)
+				}
+				if len(got.Changes) != len(tt.want) {
+					t.Fatalf("computeDelta() changes = %v, want %v", got.Changes, tt.want)
+				}
+				for path, want := range tt.want {
+					if change, ok := got.Changes[path]; !ok || change-want > 1e-9 || want-change > 1e-9 {
+						t.Errorf("computeDelta() changes = %v, want %v", got.Changes, tt.want)
+					}
+				}
+			},
+		)
+	}
+}
+
+func Test_computeDelta_units(t *testing.T) {
+	previous := `{"features": {"temperature": 4}}`
+	current := `{"features": {"temperature": 40}, "units": {"temperature": "fahrenheit", "precipitation": "in",
+		"windSpeed": "mph", "area": "mi2"}}`
+
+	// Dashboards in different units are not compared
+	got, err := computeDelta([]byte(previous), []byte(current), time.Now())
+	if err != nil || got != nil {
+		t.Errorf("computeDelta() = %+v, %v, want no delta", got, err)
+	}
+}
+
+func Test_filterSnapshot(t *testing.T) {
+	snapshot := func() map[string]any {
+		return map[string]any{
+			"country":  "Norway",
+			"features": map[string]any{"temperature": 4.0, "population": 5.0, "capital": "Oslo"},
+			"aggregates": map[string]any{
+				"countries":   2.0,
+				"population":  10.0,
+				"temperature": 5.0,
+			},
+			"delta": map[string]any{
+				"changes": map[string]any{
+					"temperature":           1.0,
+					"targetCurrencies.EUR":  0.1,
+					"aggregates.population": 2.0,
+				},
+			},
+			"members": []any{
+				map[string]any{"features": map[string]any{"temperature": 3.0, "area": 1.0}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		features []string
+		want     map[string]any
+	}{
+		{
+			name:     "Without features the snapshot is kept whole",
+			features: nil,
+			want:     snapshot(),
+		},
+		{
+			name:     "Only the given features are kept",
+			features: []string{"temperature", "targetCurrencies"},
+			want: map[string]any{
+				"country":    "Norway",
+				"features":   map[string]any{"temperature": 4.0},
+				"aggregates": map[string]any{"countries": 2.0, "temperature": 5.0},
+				"delta": map[string]any{
+					"changes": map[string]any{"temperature": 1.0, "targetCurrencies.EUR": 0.1},
+				},
+				"members": []any{
+					map[string]any{"features": map[string]any{"temperature": 3.0}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := filterSnapshot(snapshot(), tt.features); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("filterSnapshot() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_parseList(t *testing.T) {
+	got := parseList(" temperature, ,population,")
+	if want := []string{"temperature", "population"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseList() = %v, want %v", got, want)
+	}
+	if got := parseList(""); got != nil {
+		t.Errorf("parseList() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,44 @@
+package dashboards
+
+import (
+	"context"
+)
+
+// localizeDashboard returns the dashboard named after its country in the given language, and its members after theirs,
+// in English where the country is not translated to the language. A regional dashboard keeps its registered name, as
+// regions are not translated. A dashboard without a language is returned as it is. Capitals and currencies are kept in
+// English: the countries service only translates the names of countries, and golang.org/x/text/currency has no names
+// of currencies, only their symbols.
+func localizeDashboard(ctx context.Context, dashboard Dashboard, language string) Dashboard {
+	if language == "" {
+		return dashboard
+	}
+	dashboard.Language = language
+
+	if dashboard.Aggregates == nil {
+		dashboard.Country = countryName(ctx, dashboard.IsoCode, language, dashboard.Country)
+		return dashboard
+	}
+
+	members := make([]Dashboard, len(dashboard.Members))
+	for i, member := range dashboard.Members {
+		member.Country = countryName(ctx, member.IsoCode, language, member.Country)
+		members[i] = member
+	}
+	dashboard.Members = members
+	return dashboard
+}
+
+// countryName returns the name of the country with the given ISO code in the given language, or in English if it is
+// not translated to it. The country data has been cached when the dashboard was populated. If it is no longer, and
+// cannot be fetched again, the given name is kept.
+func countryName(ctx context.Context, isoCode string, language string, name string) string {
+	country, err := getCountryData(ctx, isoCode)
+	if err != nil {
+		return name
+	}
+	if translation, ok := country.Translations[language]; ok {
+		return translation
+	}
+	return country.Name
+}
@@ -0,0 +1,72 @@
+package dashboards
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func Test_localizeDashboard(t *testing.T) {
+	tests := []struct {
+		name         string
+		dashboard    Dashboard
+		language     string
+		wantCountry  string
+		wantMembers  []string
+		wantLanguage string
+	}{
+		{
+			name:        "Without a language the registered name is kept",
+			dashboard:   Dashboard{Country: "My Norway", IsoCode: "NO"},
+			language:    "",
+			wantCountry: "My Norway",
+		},
+		{
+			name:         "Translated country",
+			dashboard:    Dashboard{Country: "My Norway", IsoCode: "NO"},
+			language:     "de",
+			wantCountry:  "Norwegen",
+			wantLanguage: "de",
+		},
+		{
+			name:         "English fallback",
+			dashboard:    Dashboard{Country: "My Norway", IsoCode: "no"},
+			language:     "ko",
+			wantCountry:  "Norway",
+			wantLanguage: "ko",
+		},
+		{
+			name: "Members of a regional dashboard",
+			dashboard: Dashboard{
+				Country:    "Scandinavia",
+				Members:    []Dashboard{{Country: "Norway", IsoCode: "NO"}},
+				Aggregates: &RegionAggregates{Countries: 1},
+			},
+			language:     "fr",
+			wantCountry:  "Scandinavia",
+			wantMembers:  []string{"Norvège"},
+			wantLanguage: "fr",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := localizeDashboard(context.Background(), tt.dashboard, tt.language)
+				if got.Country != tt.wantCountry || got.Language != tt.wantLanguage {
+					t.Errorf(
+						"localizeDashboard() = %q in %q, want %q in %q",
+						got.Country, got.Language, tt.wantCountry, tt.wantLanguage,
+					)
+				}
+
+				var members []string
+				for _, member := range got.Members {
+					members = append(members, member.Country)
+				}
+				if !reflect.DeepEqual(members, tt.wantMembers) {
+					t.Errorf("localizeDashboard() members = %v, want %v", members, tt.wantMembers)
+				}
+			},
+		)
+	}
+}
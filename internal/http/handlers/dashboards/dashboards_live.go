@@ -0,0 +1,334 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/http/handlers/events"
+	utils2 "assignment-2/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Implemented methods for the live endpoint
+var implementedMethodsLive = []string{
+	http.MethodGet,
+}
+
+// Endpoint for live-updating dashboards
+var dashboardsLiveEndpoint = inhouse.Endpoint{
+	Path:    constants.DashboardsPath + "{id}/live",
+	Methods: implementedMethodsLive,
+	Description: "Endpoint for live-updating dashboards. Upgrades to a WebSocket connection, and pushes the " +
+		"populated dashboard whenever it is refreshed or the registration changes.",
+}
+
+// Timeouts for the WebSocket connection
+const (
+	liveWriteTimeout = 10 * time.Second
+	livePongTimeout  = 60 * time.Second
+	livePingInterval = livePongTimeout * 9 / 10
+)
+
+// upgrader upgrades HTTP connections to WebSocket connections, only allowing same-origin browser connections.
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// liveDashboard is a dashboard that is refreshed on the server, and pushed to every subscribed connection.
+// Subscribers to the same dashboard share the upstream requests.
+type liveDashboard struct {
+	id          string
+	mu          sync.Mutex
+	subscribers map[chan Dashboard]struct{}
+	latest      *Dashboard
+	stop        chan struct{}
+}
+
+// liveDashboards holds the live dashboards with at least one subscriber, by registration ID.
+var (
+	liveDashboards   = map[string]*liveDashboard{}
+	liveDashboardsMu sync.Mutex
+)
+
+// LiveHandler handles the /dashboard/v1/dashboards/{id}/live path.
+// It currently only supports GET requests, which are upgraded to WebSocket connections.
+func LiveHandler(w http.ResponseWriter, r *http.Request) {
+	// Switch on the HTTP request method
+	switch r.Method {
+	case http.MethodGet:
+		handleDashboardsLiveRequest(w, r)
+
+	default:
+		// If the method is not implemented, return an error with the allowed methods
+		http.Error(
+			w, fmt.Sprintf(
+				"REST Method '%s' not supported. Currently only '%v' are supported.", r.Method,
+				implementedMethodsLive,
+			), http.StatusNotImplemented,
+		)
+		return
+	}
+}
+
+// handleDashboardsLiveRequest upgrades the request to a WebSocket connection, and pushes the populated dashboard to
+// the client until either side closes the connection.
+func handleDashboardsLiveRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := utils2.GetIDFromRequest(r)
+	if err != nil {
+		http.Error(w, constants.ErrIDInvalid, http.StatusBadRequest)
+		return
+	}
+
+	// Check that the registration exists before upgrading the connection
+	_, err = db.GetDocument[requests.DashboardConfig](id, db.DashboardCollection)
+	if err != nil {
+		switch err.Error() {
+		case constants.ErrIDInvalid:
+			http.Error(w, constants.ErrIDInvalid, http.StatusBadRequest)
+		case constants.ErrDBDocNotFound:
+			http.Error(w, constants.ErrDBDocNotFound, http.StatusNoContent)
+		default:
+			http.Error(w, constants.ErrDBGetDoc, http.StatusInternalServerError)
+		}
+		log.Println(constants.ErrDBGetDoc + err.Error())
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already written an error response
+		log.Println(constants.ErrDashboardLiveUpgrade, err.Error())
+		return
+	}
+	defer conn.Close()
+
+	updates, latest, unsubscribe := SubscribeLive(id)
+	defer unsubscribe()
+
+	// Read from the connection to handle pongs, and to notice when the client closes it
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		_ = conn.SetReadDeadline(time.Now().Add(livePongTimeout))
+		conn.SetPongHandler(
+			func(string) error {
+				return conn.SetReadDeadline(time.Now().Add(livePongTimeout))
+			},
+		)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Send the current dashboard right away if it has already been populated for another subscriber
+	if latest != nil {
+		if err := writeLiveDashboard(conn, *latest); err != nil {
+			log.Println(constants.ErrDashboardLiveWrite, err.Error())
+			return
+		}
+	}
+
+	ping := time.NewTicker(livePingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case update, ok := <-updates:
+			if !ok {
+				// The registration has been deleted
+				_ = writeLiveMessage(
+					conn, websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, constants.ErrDashboardRegistrationDeleted),
+				)
+				return
+			}
+			if err := writeLiveDashboard(conn, update); err != nil {
+				log.Println(constants.ErrDashboardLiveWrite, err.Error())
+				return
+			}
+		case <-ping.C:
+			if err := writeLiveMessage(conn, websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeLiveDashboard writes the dashboard to the connection as a JSON text message.
+func writeLiveDashboard(conn *websocket.Conn, dashboard Dashboard) error {
+	payload, err := json.Marshal(dashboard)
+	if err != nil {
+		return err
+	}
+	return writeLiveMessage(conn, websocket.TextMessage, payload)
+}
+
+// writeLiveMessage writes a message to the connection with a write deadline.
+func writeLiveMessage(conn *websocket.Conn, messageType int, data []byte) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout)); err != nil {
+		return err
+	}
+	return conn.WriteMessage(messageType, data)
+}
+
+// SubscribeLive subscribes to updates of the dashboard with the given ID, starting the refreshing of the dashboard if
+// this is the first subscriber. Subscribers to the same dashboard share the upstream requests. Returns the channel of
+// updates, which is closed when the registration is deleted, the latest dashboard if there is one, and a function to
+// unsubscribe.
+func SubscribeLive(id string) (<-chan Dashboard, *Dashboard, func()) {
+	updates, latest := subscribeLive(id)
+	return updates, latest, func() {
+		unsubscribeLive(id, updates)
+	}
+}
+
+// subscribeLive subscribes to updates of the dashboard with the given ID, starting the refreshing of the dashboard if
+// this is the first subscriber. Returns the channel of updates, and the latest dashboard if there is one.
+func subscribeLive(id string) (chan Dashboard, *Dashboard) {
+	liveDashboardsMu.Lock()
+	defer liveDashboardsMu.Unlock()
+
+	live, ok := liveDashboards[id]
+	if !ok {
+		live = &liveDashboard{
+			id:          id,
+			subscribers: map[chan Dashboard]struct{}{},
+			stop:        make(chan struct{}),
+		}
+		liveDashboards[id] = live
+		go live.run()
+	}
+
+	// Buffer one update, a slow subscriber only needs the newest dashboard
+	updates := make(chan Dashboard, 1)
+
+	live.mu.Lock()
+	defer live.mu.Unlock()
+	live.subscribers[updates] = struct{}{}
+
+	return updates, live.latest
+}
+
+// unsubscribeLive removes the subscriber, and stops refreshing the dashboard if it was the last subscriber.
+func unsubscribeLive(id string, updates chan Dashboard) {
+	liveDashboardsMu.Lock()
+	defer liveDashboardsMu.Unlock()
+
+	live, ok := liveDashboards[id]
+	if !ok {
+		return
+	}
+
+	live.mu.Lock()
+	delete(live.subscribers, updates)
+	empty := len(live.subscribers) == 0
+	live.mu.Unlock()
+
+	if empty {
+		delete(liveDashboards, id)
+		close(live.stop)
+	}
+}
+
+// run refreshes the dashboard at the configured interval, and right away when the registration changes, until the
+// last subscriber leaves or the registration is deleted.
+func (live *liveDashboard) run() {
+	subscription, _ := events.Subscribe(
+		events.Filter{Events: []string{requests.EventChange, requests.EventDelete}},
+		0,
+	)
+	defer events.Unsubscribe(subscription)
+
+	ticker := time.NewTicker(utils2.GetLiveRefreshInterval())
+	defer ticker.Stop()
+
+	if !live.refresh() {
+		return
+	}
+
+	for {
+		select {
+		case <-live.stop:
+			return
+		case <-ticker.C:
+			if !live.refresh() {
+				return
+			}
+		case event := <-subscription.Events:
+			if event.RegistrationID != live.id {
+				continue
+			}
+			if event.Event == requests.EventDelete {
+				live.closeSubscribers()
+				return
+			}
+			if !live.refresh() {
+				return
+			}
+		}
+	}
+}
+
+// refresh populates the dashboard and pushes it to the subscribers. If it fails, the subscribers keep the last
+// dashboard they received. Returns false if the registration no longer exists.
+func (live *liveDashboard) refresh() bool {
+	dashboardConfig, err := db.GetDocument[requests.DashboardConfig](live.id, db.DashboardCollection)
+	if err != nil {
+		log.Println(constants.ErrDBGetDoc + err.Error())
+		if err.Error() == constants.ErrDBDocNotFound {
+			live.closeSubscribers()
+			return false
+		}
+		return true
+	}
+
+	populated, err := buildDashboard(context.Background(), dashboardConfig)
+	if err != nil {
+		log.Println(err.Error())
+		return true
+	}
+
+	live.mu.Lock()
+	defer live.mu.Unlock()
+
+	live.latest = &populated
+	for updates := range live.subscribers {
+		// Replace an update the subscriber has not read yet with the newest one
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- populated
+	}
+
+	return true
+}
+
+// closeSubscribers removes the live dashboard and closes the channel of every subscriber.
+func (live *liveDashboard) closeSubscribers() {
+	liveDashboardsMu.Lock()
+	if liveDashboards[live.id] == live {
+		delete(liveDashboards, live.id)
+	}
+	liveDashboardsMu.Unlock()
+
+	live.mu.Lock()
+	defer live.mu.Unlock()
+	for updates := range live.subscribers {
+		close(updates)
+	}
+	live.subscribers = map[chan Dashboard]struct{}{}
+}
@@ -0,0 +1,124 @@
+package dashboards
+
+import (
+	"assignment-2/internal/db"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/http/handlers/events"
+	"assignment-2/internal/utils"
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLiveHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		statusCode int
+	}{
+		{
+			name:       "NegativeTestLiveHandler",
+			method:     http.MethodPost,
+			statusCode: http.StatusNotImplemented,
+		},
+		{
+			name:       "NoIDTestLiveHandler",
+			method:     http.MethodGet,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	// Run the tests
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Create a mock request
+				req := httptest.NewRequest(tt.method, "/", nil)
+
+				// Create a mock response recorder
+				w := httptest.NewRecorder()
+
+				// Call the handler
+				LiveHandler(w, req)
+
+				// Check if the status code matches expected
+				if w.Code != tt.statusCode {
+					log.Println("Testing: ", tt.name)
+					t.Errorf(
+						"handler returned wrong status code: got %v want %v",
+						w.Code, tt.statusCode,
+					)
+				}
+			},
+		)
+	}
+}
+
+func Test_handleDashboardsLiveRequest(t *testing.T) {
+	registration := requests.DashboardConfig{
+		ID:      utils.GenerateRandomID(),
+		Country: "Norway",
+		IsoCode: "NO",
+		Features: requests.ConfigFeatures{
+			Temperature: true,
+			Capital:     true,
+		},
+	}
+	err := db.AddDocument[requests.DashboardConfig](registration, db.DashboardCollection)
+	if err != nil {
+		t.Fatalf("could not add registration: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(LiveHandler))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/?id=" + registration.ID
+
+	// Two subscribers to the same dashboard share one live dashboard
+	first, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer first.Close()
+	second, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	defer second.Close()
+
+	for _, conn := range []*websocket.Conn{first, second} {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("could not read dashboard: %v", err)
+		}
+
+		var received Dashboard
+		if err := json.Unmarshal(message, &received); err != nil {
+			t.Errorf("could not decode dashboard: %v", err)
+		}
+		if received.Country != registration.Country || received.Features.Capital == nil {
+			t.Errorf("received dashboard %v, want populated dashboard for %v", received, registration.Country)
+		}
+	}
+
+	liveDashboardsMu.Lock()
+	subscribers := len(liveDashboards[registration.ID].subscribers)
+	liveDashboardsMu.Unlock()
+	if subscribers != 2 {
+		t.Errorf("live dashboard has %v subscribers, want 2", subscribers)
+	}
+
+	// Deleting the registration closes the connections
+	events.Publish(requests.EventDelete, registration.IsoCode, registration.ID)
+	_ = first.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = first.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("read after delete returned %v, want normal closure", err)
+	}
+}
@@ -0,0 +1,475 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/http/datatransfers/responses"
+	"context"
+	"dario.cat/mergo"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Loader lazily populates the dashboard of a registration. Each external service is called at most once, and only
+// when a feature it supplies is requested. Features that are not enabled in the registration are returned as nil
+// without calling any external service. The external services share the deadline of the context the loader is created
+// with, so a loader must not outlive the request it is created for. The quantities are in the registered units.
+type Loader struct {
+	ctx           context.Context
+	config        requests.DashboardConfig
+	lastRetrieval time.Time
+	converter     unitConverter
+
+	countryOnce sync.Once
+	country     DashboardFeatures
+	countryErr  error
+
+	meteoOnce sync.Once
+	meteo     DashboardFeatures
+	meteoErr  error
+
+	currencyOnce sync.Once
+	currency     DashboardFeatures
+	currencyErr  error
+
+	regionOnce sync.Once
+	region     Dashboard
+	regionErr  error
+
+	computedOnce sync.Once
+	computed     map[string]float64
+	computedErr  error
+}
+
+// NewLoader creates a loader for the dashboard of the given registration, for the request with the given context.
+func NewLoader(ctx context.Context, config requests.DashboardConfig) *Loader {
+	return &Loader{
+		ctx:           ctx,
+		config:        config,
+		lastRetrieval: time.Now(),
+		converter:     newUnitConverter(config),
+	}
+}
+
+// Config returns the registration the dashboard is populated for.
+func (l *Loader) Config() requests.DashboardConfig {
+	return l.config
+}
+
+// LastRetrieval returns the time the dashboard was requested.
+func (l *Loader) LastRetrieval() time.Time {
+	return l.lastRetrieval
+}
+
+// Units returns the units the quantities of the dashboard are in.
+func (l *Loader) Units() DashboardUnits {
+	return l.converter.units
+}
+
+// LocalRetrieval returns the name of the timezone of the dashboard, and the time the dashboard was requested in it, or
+// no time if the timezone is unknown. The timezone is that of the weather location if a weather feature is enabled, or
+// else that of the country.
+func (l *Loader) LocalRetrieval() (string, *time.Time) {
+	if l.config.IsRegional() {
+		return "", nil
+	}
+	var location *time.Location
+	if country, err := l.countryData(); err == nil {
+		location = country.Location
+	}
+	if hasWeatherFeatures(l.config.Features) {
+		if meteo, err := l.meteoData(); err == nil && meteo.Location != nil {
+			location = meteo.Location
+		}
+	}
+	return localTime(location, l.lastRetrieval)
+}
+
+// Weather returns the weather features of today, of which the features that are not enabled are nil.
+func (l *Loader) Weather() (WeatherFeatures, error) {
+	if !hasWeatherFeatures(l.config.Features) {
+		return WeatherFeatures{}, nil
+	}
+	meteo, err := l.meteoData()
+	return l.converter.weather(filterWeatherByConfig(meteo.weather(), l.config.Features)), err
+}
+
+// Forecast returns the weather features of every day of the forecast, or nil if the forecast is not enabled.
+func (l *Loader) Forecast() ([]ForecastDay, error) {
+	if !hasWeatherFeatures(l.config.Features) || l.config.Features.ForecastDays == 0 {
+		return nil, nil
+	}
+	meteo, err := l.meteoData()
+	if err != nil {
+		return nil, err
+	}
+	return l.converter.forecast(filterForecastByConfig(meteo.Forecast, l.config.Features)), nil
+}
+
+// WeatherLocation returns where the weather is measured, or nil if no weather feature is enabled.
+func (l *Loader) WeatherLocation() (*requests.WeatherLocation, error) {
+	if !hasWeatherFeatures(l.config.Features) {
+		return nil, nil
+	}
+	meteo, err := l.meteoData()
+	return meteo.WeatherLocation, err
+}
+
+// WeatherPoints returns the weather at each point the weather is measured at, or nil if it is not reported separately.
+func (l *Loader) WeatherPoints() ([]PointWeather, error) {
+	if !hasWeatherFeatures(l.config.Features) || !l.config.Features.WeatherLocation.Separate {
+		return nil, nil
+	}
+	meteo, err := l.meteoData()
+	if err != nil {
+		return nil, err
+	}
+	return l.converter.points(filterPointsByConfig(meteo.WeatherPoints, l.config.Features)), nil
+}
+
+// Capital returns the primary capital, or nil if the feature is not enabled.
+func (l *Loader) Capital() (*string, error) {
+	if !l.config.Features.Capital {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.Capital, err
+}
+
+// Capitals returns all capitals, or nil if the capital is not enabled.
+func (l *Loader) Capitals() ([]string, error) {
+	if !l.config.Features.Capital {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.Capitals, err
+}
+
+// Coordinates returns the coordinates of the country, or nil if the feature is not enabled.
+func (l *Loader) Coordinates() (*inhouse.Coordinates, error) {
+	if !l.config.Features.Coordinates {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.Coordinates, err
+}
+
+// Population returns the population, or nil if the feature is not enabled.
+func (l *Loader) Population() (*int, error) {
+	if !l.config.Features.Population {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.Population, err
+}
+
+// Area returns the area, or nil if the feature is not enabled.
+func (l *Loader) Area() (*float64, error) {
+	if !l.config.Features.Area {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return l.converter.area(country.Area), err
+}
+
+// Languages returns the names of the official languages, or nil if the feature is not enabled.
+func (l *Loader) Languages() ([]string, error) {
+	if !l.config.Features.Languages {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.Languages, err
+}
+
+// Borders returns the names of the bordering countries, or nil if the feature is not enabled.
+func (l *Loader) Borders() ([]string, error) {
+	if !l.config.Features.Borders {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.Borders, err
+}
+
+// Region returns the region, or nil if the feature is not enabled.
+func (l *Loader) Region() (*string, error) {
+	if !l.config.Features.Region {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.Region, err
+}
+
+// Subregion returns the subregion, or nil if the region is not enabled.
+func (l *Loader) Subregion() (*string, error) {
+	if !l.config.Features.Region {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.Subregion, err
+}
+
+// Flag returns the flag, or nil if the feature is not enabled.
+func (l *Loader) Flag() (*inhouse.Flag, error) {
+	if !l.config.Features.Flag {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.Flag, err
+}
+
+// Timezones returns the timezones, or nil if the feature is not enabled.
+func (l *Loader) Timezones() ([]string, error) {
+	if !l.config.Features.Timezones {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.Timezones, err
+}
+
+// CallingCodes returns the calling codes, or nil if the feature is not enabled.
+func (l *Loader) CallingCodes() ([]string, error) {
+	if !l.config.Features.CallingCodes {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.CallingCodes, err
+}
+
+// DrivingSide returns the side of the road driven on, or nil if the feature is not enabled.
+func (l *Loader) DrivingSide() (*string, error) {
+	if !l.config.Features.DrivingSide {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.DrivingSide, err
+}
+
+// Gini returns the latest Gini index, or nil if the feature is not enabled.
+func (l *Loader) Gini() (*inhouse.Gini, error) {
+	if !l.config.Features.Gini {
+		return nil, nil
+	}
+	country, err := l.countryData()
+	return country.Gini, err
+}
+
+// Currency returns the primary currency of the country.
+func (l *Loader) Currency() (responses.Currency, error) {
+	country, err := l.countryData()
+	return country.Currency, err
+}
+
+// Currencies returns all currencies of the country.
+func (l *Loader) Currencies() ([]responses.Currency, error) {
+	country, err := l.countryData()
+	return country.Currencies, err
+}
+
+// ExchangeRates returns the exchange rates from every currency of the country to the target currencies, by currency
+// code, or nil if the country has a single currency.
+func (l *Loader) ExchangeRates() (map[string]map[string]float64, error) {
+	if len(l.config.Features.TargetCurrencies) == 0 {
+		return nil, nil
+	}
+	currency, err := l.currencyData()
+	return currency.ExchangeRates, err
+}
+
+// TargetCurrencies returns the exchange rates from the primary currency of the country to the target currencies.
+func (l *Loader) TargetCurrencies() (map[string]float64, error) {
+	if len(l.config.Features.TargetCurrencies) == 0 {
+		return map[string]float64{}, nil
+	}
+	currency, err := l.currencyData()
+	return currency.TargetCurrencies, err
+}
+
+// Computed returns the values of the computed features of the registration by name, or nil if it has none. As their
+// expressions may use any enabled feature, they need the data of every service supplying an enabled feature, which is
+// shared with the other features. A failed weather or currency service leaves out the computed features using it.
+func (l *Loader) Computed() (map[string]float64, error) {
+	if len(l.config.Computed) == 0 || l.config.IsRegional() {
+		return nil, nil
+	}
+	l.computedOnce.Do(
+		func() {
+			l.computed, l.computedErr = l.computedData()
+		},
+	)
+	return l.computed, l.computedErr
+}
+
+// computedData computes the computed features from the dashboard of the enabled features, from the data loaded for
+// the other features.
+func (l *Loader) computedData() (map[string]float64, error) {
+	features, err := l.countryData()
+	if err != nil {
+		return nil, err
+	}
+
+	// Leave out the features of the services that failed, as a partial dashboard does
+	var services []DashboardFeatures
+	if hasWeatherFeatures(l.config.Features) {
+		if meteo, err := l.meteoData(); err == nil {
+			services = append(services, meteo)
+		}
+	}
+	if len(l.config.Features.TargetCurrencies) > 0 {
+		if currency, err := l.currencyData(); err == nil {
+			services = append(services, currency)
+		}
+	}
+	for _, serviceFeatures := range services {
+		err = mergo.Merge(&features, serviceFeatures, mergo.WithOverride, mergo.WithoutDereference)
+		if err != nil {
+			log.Println(constants.ErrDashboardMergingData + err.Error())
+			return nil, fmt.Errorf(constants.ErrDashboardMergingData)
+		}
+	}
+
+	dashboard, err := filterDashboardByConfig(
+		Dashboard{Country: l.config.Country, IsoCode: l.config.IsoCode, Features: features},
+		l.config,
+	)
+	if err != nil {
+		log.Println(constants.ErrDashboardFilterByRegistration + err.Error())
+		return nil, fmt.Errorf(constants.ErrDashboardFilterByRegistration)
+	}
+	return computeFeatures(l.ctx, l.config, l.converter.dashboard(dashboard)).Features.Computed, nil
+}
+
+// InverseRates returns the exchange rates from the target currencies to the primary currency of the country.
+func (l *Loader) InverseRates() (map[string]float64, error) {
+	if len(l.config.Features.TargetCurrencies) == 0 {
+		return map[string]float64{}, nil
+	}
+	currency, err := l.currencyData()
+	return currency.InverseRates, err
+}
+
+// CrossRates returns the exchange rates between the target currencies, by the currency converted from.
+func (l *Loader) CrossRates() (map[string]map[string]float64, error) {
+	if len(l.config.Features.TargetCurrencies) == 0 {
+		return nil, nil
+	}
+	currency, err := l.currencyData()
+	return currency.CrossRates, err
+}
+
+// Members returns loaders for the dashboards of the countries of a regional dashboard, or nil if the dashboard is of a
+// single country. The countries have already been populated, so the loaders use the cached data.
+func (l *Loader) Members() ([]*Loader, error) {
+	if !l.config.IsRegional() {
+		return nil, nil
+	}
+	region, err := l.regionData()
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*Loader, 0, len(region.Members))
+	for _, member := range region.Members {
+		members = append(
+			members, NewLoader(
+				l.ctx, requests.DashboardConfig{
+					Country:   member.Country,
+					IsoCode:   member.IsoCode,
+					Features:  l.config.Features,
+					Units:     l.config.Units,
+					Precision: l.config.Precision,
+				},
+			),
+		)
+	}
+	return members, nil
+}
+
+// Aggregates returns the aggregates over the countries of a regional dashboard, or nil if the dashboard is of a single
+// country.
+func (l *Loader) Aggregates() (*RegionAggregates, error) {
+	if !l.config.IsRegional() {
+		return nil, nil
+	}
+	region, err := l.regionData()
+	if err != nil {
+		return nil, err
+	}
+	return l.converter.quantities(region).Aggregates, nil
+}
+
+// regionData populates the regional dashboard the first time it is needed.
+func (l *Loader) regionData() (Dashboard, error) {
+	l.regionOnce.Do(
+		func() {
+			l.region, l.regionErr = buildRegionalDashboard(l.ctx, l.config)
+		},
+	)
+	return l.region, l.regionErr
+}
+
+// countryData gets the country data the first time it is needed.
+func (l *Loader) countryData() (DashboardFeatures, error) {
+	l.countryOnce.Do(
+		func() {
+			l.country, l.countryErr = getCountryData(l.ctx, l.config.IsoCode)
+			if l.countryErr != nil {
+				log.Println(constants.ErrDashboardGetCountryData + l.countryErr.Error())
+				l.countryErr = dashboardError(l.countryErr, constants.ErrDashboardGetCountryData)
+				return
+			}
+			l.country = withPrimary(l.country, l.config.Features)
+		},
+	)
+	return l.country, l.countryErr
+}
+
+// meteoData gets the weather data the first time it is needed, which requires the country to know where to measure it.
+func (l *Loader) meteoData() (DashboardFeatures, error) {
+	l.meteoOnce.Do(
+		func() {
+			country, err := l.countryData()
+			if err != nil {
+				l.meteoErr = err
+				return
+			}
+			l.meteo, l.meteoErr = getWeatherData(
+				l.ctx,
+				l.config.Features.WeatherLocation,
+				country,
+				forecastDays(l.config.Features),
+			)
+			if l.meteoErr != nil {
+				log.Println(constants.ErrDashboardGetWeatherData + l.meteoErr.Error())
+				l.meteoErr = dashboardError(l.meteoErr, constants.ErrDashboardGetWeatherData)
+			}
+		},
+	)
+	return l.meteo, l.meteoErr
+}
+
+// currencyData gets the exchange rates the first time they are needed, which requires the currencies of the country.
+func (l *Loader) currencyData() (DashboardFeatures, error) {
+	l.currencyOnce.Do(
+		func() {
+			country, err := l.countryData()
+			if err != nil {
+				l.currencyErr = err
+				return
+			}
+			l.currency, l.currencyErr = getExchangeRates(
+				l.ctx,
+				l.config.Features.TargetCurrencies,
+				country.Currencies,
+				country.Currency,
+			)
+			if l.currencyErr != nil {
+				log.Println(constants.ErrDashboardGetCurrencyData + l.currencyErr.Error())
+				l.currencyErr = dashboardError(l.currencyErr, constants.ErrDashboardGetCurrencyData)
+			}
+		},
+	)
+	return l.currency, l.currencyErr
+}
@@ -0,0 +1,88 @@
+package dashboards
+
+import (
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/utils"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestLoader(t *testing.T) {
+	// Make the weather and currency services unreachable, so the test fails if the loader calls them
+	meteoApi, currencyApi := utils.CurrentMeteoApi, utils.CurrentCurrencyApi
+	utils.CurrentMeteoApi, utils.CurrentCurrencyApi = "http://localhost:0/", "http://localhost:0/"
+	defer func() {
+		utils.CurrentMeteoApi, utils.CurrentCurrencyApi = meteoApi, currencyApi
+	}()
+
+	loader := NewLoader(
+		context.Background(),
+		requests.DashboardConfig{
+			Country: "Norway",
+			IsoCode: "NO",
+			Features: requests.ConfigFeatures{
+				Capital:     true,
+				Temperature: false,
+				Borders:     true,
+				Region:      true,
+			},
+		},
+	)
+
+	capital, err := loader.Capital()
+	if err != nil || capital == nil || *capital != "Oslo" {
+		t.Errorf("Capital() = %v, %v, want Oslo", capital, err)
+	}
+
+	weather, err := loader.Weather()
+	if err != nil || weather.Temperature != nil {
+		t.Errorf("Weather() = %+v, %v, want no temperature for a disabled feature", weather, err)
+	}
+
+	// The bordering countries are resolved to their names by a second request to the countries service
+	borders, err := loader.Borders()
+	if err != nil || !reflect.DeepEqual(borders, []string{"Finland", "Russia", "Sweden"}) {
+		t.Errorf("Borders() = %v, %v, want Finland, Russia and Sweden", borders, err)
+	}
+
+	subregion, err := loader.Subregion()
+	if err != nil || subregion == nil || *subregion != "Northern Europe" {
+		t.Errorf("Subregion() = %v, %v, want Northern Europe", subregion, err)
+	}
+
+	gini, err := loader.Gini()
+	if err != nil || gini != nil {
+		t.Errorf("Gini() = %v, %v, want no Gini index for a disabled feature", gini, err)
+	}
+
+	rates, err := loader.TargetCurrencies()
+	if err != nil || len(rates) != 0 {
+		t.Errorf("TargetCurrencies() = %v, %v, want no rates without target currencies", rates, err)
+	}
+
+	// Without weather features, the timezone is that of the country
+	timezone, localRetrieval := loader.LocalRetrieval()
+	if timezone != "UTC+01:00" || localRetrieval == nil || !localRetrieval.Equal(loader.LastRetrieval()) {
+		t.Errorf("LocalRetrieval() = %v, %v, want the last retrieval in UTC+01:00", timezone, localRetrieval)
+	}
+
+	// Computed features are computed from the loaded country data, without calling the services of disabled features
+	loader = NewLoader(
+		context.Background(),
+		requests.DashboardConfig{
+			Country:  "Norway",
+			IsoCode:  "NO",
+			Features: requests.ConfigFeatures{Population: true},
+			Computed: []requests.ComputedFeature{{Name: "next", Expression: "population + 1"}},
+		},
+	)
+	population, err := loader.Population()
+	if err != nil || population == nil {
+		t.Fatalf("Population() = %v, %v, want a population", population, err)
+	}
+	computed, err := loader.Computed()
+	if want := map[string]float64{"next": float64(*population + 1)}; err != nil || !reflect.DeepEqual(computed, want) {
+		t.Errorf("Computed() = %v, %v, want %v", computed, err, want)
+	}
+}
@@ -0,0 +1,229 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/http/datatransfers/responses"
+	"assignment-2/internal/providers"
+	utils2 "assignment-2/internal/utils"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RegionAggregates are the aggregates over the countries of a regional dashboard. The population and area are totals,
+// the temperature is the average weighted by population, and the exchange rates are from the base currency to the
+// primary currencies of the countries. Aggregates of features that are not enabled are not set.
+type RegionAggregates struct {
+	Countries     int                `json:"countries"`
+	Population    *int               `json:"population,omitempty"`
+	Area          *float64           `json:"area,omitempty"`
+	Temperature   *float64           `json:"temperature,omitempty"`
+	BaseCurrency  string             `json:"baseCurrency,omitempty"`
+	ExchangeRates map[string]float64 `json:"exchangeRates,omitempty"`
+}
+
+// Name of the feature of a regional dashboard holding the dashboards of its countries, as used for the errors of
+// countries that could not be populated
+const membersFeature = "members"
+
+// buildRegionalDashboard populates the dashboards of the countries of the given regional registration, and the
+// aggregates over them. The countries are populated concurrently, but at most a configured number at a time, and each
+// has the deadline of a dashboard of its own. A country that cannot be populated is left out and recorded as an error
+// under its ISO code, so the dashboard is partial. Failing to populate every country is an error. The returned error
+// message is safe to show to the client.
+func buildRegionalDashboard(ctx context.Context, dashboardConfig requests.DashboardConfig) (Dashboard, error) {
+	isoCodes, err := getRegionMembers(ctx, dashboardConfig)
+	if err != nil {
+		log.Println(constants.ErrDashboardGetRegionData + err.Error())
+		// An unknown region is reported as such, as it is not a failure of the external service
+		if err.Error() == constants.ErrDashboardRegionNotFound {
+			return Dashboard{}, err
+		}
+		return Dashboard{}, dashboardError(err, constants.ErrDashboardGetRegionData)
+	}
+	if len(isoCodes) == 0 {
+		log.Println(constants.ErrDashboardGetRegionData + "no countries")
+		return Dashboard{}, fmt.Errorf(constants.ErrDashboardGetRegionData)
+	}
+
+	members := make([]Dashboard, len(isoCodes))
+	countries := make([]DashboardFeatures, len(isoCodes))
+	memberErrs := make([]error, len(isoCodes))
+	limit := make(chan struct{}, utils2.GetRegionConcurrency())
+	var wg sync.WaitGroup
+	for i := range isoCodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case limit <- struct{}{}:
+				defer func() { <-limit }()
+			case <-ctx.Done():
+				memberErrs[i] = dashboardError(ctx.Err(), constants.ErrDashboardGetCountryData)
+				return
+			}
+
+			memberConfig := requests.DashboardConfig{IsoCode: isoCodes[i], Features: dashboardConfig.Features}
+			members[i], memberErrs[i] = buildCountryDashboard(ctx, memberConfig)
+			if memberErrs[i] != nil {
+				return
+			}
+			// The country data has just been cached, and holds the population and area whether they are enabled or not
+			countries[i], memberErrs[i] = getCountryData(ctx, isoCodes[i])
+		}(i)
+	}
+	wg.Wait()
+
+	response := Dashboard{
+		Country: regionName(dashboardConfig),
+		Errors:  make(map[string]DashboardError),
+	}
+	var populatedCountries []DashboardFeatures
+	for i, isoCode := range isoCodes {
+		if memberErrs[i] != nil {
+			response.Errors[isoCode] = DashboardError{
+				Features: []string{membersFeature},
+				Message:  memberErrs[i].Error(),
+			}
+			continue
+		}
+		response.Members = append(response.Members, members[i])
+		populatedCountries = append(populatedCountries, countries[i])
+	}
+	if len(response.Members) == 0 {
+		return Dashboard{}, memberErrs[0]
+	}
+
+	aggregates := aggregateMembers(response.Members, populatedCountries, dashboardConfig.Features)
+	if dashboardConfig.Features.BaseCurrency != "" {
+		aggregates.BaseCurrency = strings.ToUpper(dashboardConfig.Features.BaseCurrency)
+		aggregates.ExchangeRates, err = getRegionExchangeRates(ctx, aggregates.BaseCurrency, response.Members)
+		if err != nil {
+			log.Println(constants.ErrDashboardGetCurrencyData + err.Error())
+			response.Errors[currencySource] = DashboardError{
+				Features: []string{"exchangeRates"},
+				Message:  dashboardError(err, constants.ErrDashboardGetCurrencyData).Error(),
+			}
+		}
+	}
+	response.Aggregates = &aggregates
+	response.LastRetrieval = time.Now()
+
+	if len(response.Errors) == 0 {
+		response.Errors = nil
+	}
+	return response, nil
+}
+
+// getRegionMembers returns the ISO codes of the countries of a regional registration: those given, in upper case and
+// without duplicates, or those of its region or subregion.
+func getRegionMembers(ctx context.Context, dashboardConfig requests.DashboardConfig) ([]string, error) {
+	if len(dashboardConfig.IsoCodes) > 0 {
+		var isoCodes []string
+		for _, isoCode := range dashboardConfig.IsoCodes {
+			isoCode = strings.ToUpper(isoCode)
+			if !slices.Contains(isoCodes, isoCode) {
+				isoCodes = append(isoCodes, isoCode)
+			}
+		}
+		return isoCodes, nil
+	}
+
+	provider := providers.Countries()
+	var countries []providers.Country
+	var err error
+	if dashboardConfig.Region != "" {
+		countries, err = provider.Region(ctx, dashboardConfig.Region)
+	} else {
+		countries, err = provider.Subregion(ctx, dashboardConfig.Subregion)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	isoCodes := make([]string, 0, len(countries))
+	for _, country := range countries {
+		isoCodes = append(isoCodes, country.IsoCode)
+	}
+	return isoCodes, nil
+}
+
+// regionName returns the name of a regional dashboard: the registered name, or else its region or subregion.
+func regionName(dashboardConfig requests.DashboardConfig) string {
+	switch {
+	case dashboardConfig.Country != "":
+		return dashboardConfig.Country
+	case dashboardConfig.Region != "":
+		return dashboardConfig.Region
+	default:
+		return dashboardConfig.Subregion
+	}
+}
+
+// aggregateMembers returns the aggregates over the dashboards of the countries of a region, with the given country
+// data of each. The temperature is weighted by the population of the countries whose temperature is known.
+func aggregateMembers(
+	members []Dashboard,
+	countries []DashboardFeatures,
+	features requests.ConfigFeatures,
+) RegionAggregates {
+	aggregates := RegionAggregates{Countries: len(members)}
+
+	var population int
+	var area, weightedTemperature, temperatureWeight float64
+	for i, member := range members {
+		if countries[i].Population != nil {
+			population += *countries[i].Population
+			if member.Features.Temperature != nil {
+				weight := float64(*countries[i].Population)
+				weightedTemperature += *member.Features.Temperature * weight
+				temperatureWeight += weight
+			}
+		}
+		if countries[i].Area != nil {
+			area += *countries[i].Area
+		}
+	}
+
+	if features.Population {
+		aggregates.Population = &population
+	}
+	if features.Area {
+		aggregates.Area = &area
+	}
+	if features.Temperature && temperatureWeight > 0 {
+		temperature := weightedTemperature / temperatureWeight
+		aggregates.Temperature = &temperature
+	}
+
+	return aggregates
+}
+
+// getRegionExchangeRates gets the exchange rates from the given base currency to the primary currencies of the
+// dashboards of the countries of a region.
+func getRegionExchangeRates(ctx context.Context, base string, members []Dashboard) (map[string]float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, utils2.GetDashboardTimeout())
+	defer cancel()
+
+	var targetCurrencies []string
+	for _, member := range members {
+		code := member.Features.Currency.Code
+		if code != "" && !slices.Contains(targetCurrencies, code) {
+			targetCurrencies = append(targetCurrencies, code)
+		}
+	}
+	if len(targetCurrencies) == 0 {
+		return nil, fmt.Errorf(constants.ErrDashboardNoCurrency)
+	}
+
+	currencyFeatures, err := getCurrencyData(ctx, targetCurrencies, responses.Currency{Code: base})
+	if err != nil {
+		return nil, err
+	}
+	return currencyFeatures.TargetCurrencies, nil
+}
@@ -0,0 +1,193 @@
+package dashboards
+
+import (
+	"assignment-2/internal/cache"
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/providers"
+	"assignment-2/internal/utils"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_aggregateMembers(t *testing.T) {
+	population := func(value int) *int { return &value }
+	members := []Dashboard{
+		{IsoCode: "NO", Features: DashboardFeatures{Temperature: float(4)}},
+		{IsoCode: "SE", Features: DashboardFeatures{Temperature: float(10)}},
+		// The temperature of Iceland is unknown, so it does not weigh in
+		{IsoCode: "IS"},
+	}
+	countries := []DashboardFeatures{
+		{Population: population(5), Area: float(300)},
+		{Population: population(10), Area: float(400)},
+		{Population: population(1), Area: float(100)},
+	}
+
+	tests := []struct {
+		name     string
+		features requests.ConfigFeatures
+		want     RegionAggregates
+	}{
+		{
+			name:     "Aggregates of enabled features",
+			features: requests.ConfigFeatures{Temperature: true, Population: true, Area: true},
+			want: RegionAggregates{
+				Countries:   3,
+				Population:  population(16),
+				Area:        float(800),
+				Temperature: float(8),
+			},
+		},
+		{
+			name:     "Aggregates of disabled features are not set",
+			features: requests.ConfigFeatures{},
+			want:     RegionAggregates{Countries: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := aggregateMembers(members, countries, tt.features)
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("aggregateMembers() = %+v, want %+v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_buildRegionalDashboard(t *testing.T) {
+	// Use empty caches, so the external services are called
+	countries, meteo, currencies := countryCache, meteoCache, currencyCache
+	restCountriesApi := utils.CurrentRestCountriesApi
+	t.Cleanup(
+		func() {
+			countryCache, meteoCache, currencyCache = countries, meteo, currencies
+			utils.CurrentRestCountriesApi = restCountriesApi
+		},
+	)
+
+	// Only Norway and Sweden are known to this countries service
+	knownCountriesApi := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/NO"):
+					_, _ = w.Write([]byte(`{"name": {"common": "Norway"}, "cca2": "NO", "population": 5}`))
+				case strings.HasSuffix(r.URL.Path, "/SE"):
+					_, _ = w.Write([]byte(`{"name": {"common": "Sweden"}, "cca2": "SE", "population": 10}`))
+				default:
+					http.Error(w, `{"status": 404, "message": "Not Found"}`, http.StatusNotFound)
+				}
+			},
+		),
+	)
+	t.Cleanup(knownCountriesApi.Close)
+
+	tests := []struct {
+		name             string
+		restCountriesApi string
+		config           requests.DashboardConfig
+		wantErr          string
+		wantMembers      []string
+		wantErrors       []string
+		wantPopulation   int
+	}{
+		{
+			name:             "Countries of a subregion",
+			restCountriesApi: restCountriesApi,
+			config: requests.DashboardConfig{
+				Subregion: "Northern Europe",
+				Features:  requests.ConfigFeatures{Population: true},
+			},
+			wantMembers: []string{"FI", "NO", "SE"},
+			// Every country of the stub is Norway
+			wantPopulation: 3 * 5379475,
+		},
+		{
+			name:             "Duplicate ISO codes are populated once",
+			restCountriesApi: knownCountriesApi.URL + "/",
+			config: requests.DashboardConfig{
+				Country:  "Scandinavia",
+				IsoCodes: []string{"no", "SE", "NO"},
+				Features: requests.ConfigFeatures{Population: true},
+			},
+			wantMembers:    []string{"NO", "SE"},
+			wantPopulation: 15,
+		},
+		{
+			name:             "Unknown country gives a partial dashboard",
+			restCountriesApi: knownCountriesApi.URL + "/",
+			config: requests.DashboardConfig{
+				IsoCodes: []string{"NO", "XX"},
+				Features: requests.ConfigFeatures{Population: true},
+			},
+			wantMembers:    []string{"NO"},
+			wantErrors:     []string{"XX"},
+			wantPopulation: 5,
+		},
+		{
+			name:             "Unknown countries fail the dashboard",
+			restCountriesApi: knownCountriesApi.URL + "/",
+			config:           requests.DashboardConfig{IsoCodes: []string{"XX", "YY"}},
+			wantErr:          constants.ErrDashboardGetCountryData,
+		},
+		{
+			name:             "Unknown region fails the dashboard",
+			restCountriesApi: knownCountriesApi.URL + "/",
+			config:           requests.DashboardConfig{Region: "Atlantis"},
+			wantErr:          constants.ErrDashboardRegionNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				countryCache = cache.New[providers.Country](countrySource, time.Hour)
+				meteoCache = cache.New[providers.Forecast](meteoSource, time.Hour)
+				currencyCache = cache.New[providers.ExchangeRates](currencySource, time.Hour)
+				utils.CurrentRestCountriesApi = tt.restCountriesApi
+
+				got, err := buildDashboard(context.Background(), tt.config)
+				if tt.wantErr != "" {
+					if err == nil || err.Error() != tt.wantErr {
+						t.Errorf("buildDashboard() error = %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("buildDashboard() error = %v", err)
+				}
+
+				var members []string
+				for _, member := range got.Members {
+					members = append(members, member.IsoCode)
+				}
+				if !reflect.DeepEqual(members, tt.wantMembers) {
+					t.Errorf("buildDashboard() members = %v, want %v", members, tt.wantMembers)
+				}
+
+				var failed []string
+				for isoCode := range got.Errors {
+					failed = append(failed, isoCode)
+				}
+				if !reflect.DeepEqual(failed, tt.wantErrors) {
+					t.Errorf("buildDashboard() errors = %v, want %v", got.Errors, tt.wantErrors)
+				}
+				if got.IsPartial() != (len(tt.wantErrors) > 0) {
+					t.Errorf("IsPartial() = %v, want %v", got.IsPartial(), len(tt.wantErrors) > 0)
+				}
+
+				if got.Aggregates == nil || got.Aggregates.Population == nil ||
+					*got.Aggregates.Population != tt.wantPopulation {
+					t.Errorf("buildDashboard() aggregates = %+v, want population %v", got.Aggregates, tt.wantPopulation)
+				}
+			},
+		)
+	}
+}
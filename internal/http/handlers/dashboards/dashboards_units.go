@@ -0,0 +1,174 @@
+package dashboards
+
+import (
+	"assignment-2/internal/http/datatransfers/requests"
+	"math"
+	"slices"
+)
+
+// DashboardUnits are the units the quantities of a dashboard are in. The wind speed unit is also that of wind gusts.
+type DashboardUnits struct {
+	Temperature   string `json:"temperature"`
+	Precipitation string `json:"precipitation"`
+	WindSpeed     string `json:"windSpeed"`
+	Area          string `json:"area"`
+}
+
+// Factors converting the metric units of the external services to each unit of a quantity
+var (
+	precipitationFactors = map[string]float64{
+		requests.UnitMillimetres: 1,
+		requests.UnitInches:      1 / 25.4,
+	}
+	windSpeedFactors = map[string]float64{
+		requests.UnitKilometresPerHour: 1,
+		requests.UnitMetresPerSecond:   1 / 3.6,
+		requests.UnitKnots:             1 / 1.852,
+		requests.UnitMilesPerHour:      1 / 1.609344,
+	}
+	areaFactors = map[string]float64{
+		requests.UnitSquareKilometres: 1,
+		requests.UnitSquareMiles:      1 / 2.589988110336,
+	}
+)
+
+// resolveUnits returns the unit of each quantity of a unit system: the metric or the imperial units, or those given
+// for a custom system, which are metric if not given.
+func resolveUnits(units requests.Units) DashboardUnits {
+	quantities := [][]string{
+		requests.TemperatureUnits, requests.PrecipitationUnits, requests.WindSpeedUnits, requests.AreaUnits,
+	}
+	resolved := make([]string, len(quantities))
+	given := []string{units.Temperature, units.Precipitation, units.WindSpeed, units.Area}
+	for i, known := range quantities {
+		switch {
+		case units.System == requests.UnitsImperial:
+			resolved[i] = known[len(known)-1]
+		case units.System == requests.UnitsCustom && slices.Contains(known, given[i]):
+			resolved[i] = given[i]
+		default:
+			resolved[i] = known[0]
+		}
+	}
+	return DashboardUnits{
+		Temperature:   resolved[0],
+		Precipitation: resolved[1],
+		WindSpeed:     resolved[2],
+		Area:          resolved[3],
+	}
+}
+
+// unitConverter converts the quantities of a dashboard from the metric units of the external services to the units of
+// a registration, and rounds them to its precision, if set. The weather features without a unit are also rounded.
+// Exchange rates, coordinates and the Gini index are left as they are.
+type unitConverter struct {
+	units     DashboardUnits
+	precision *int
+}
+
+// newUnitConverter creates a converter to the units and precision of the given registration.
+func newUnitConverter(dashboardConfig requests.DashboardConfig) unitConverter {
+	return unitConverter{units: resolveUnits(dashboardConfig.Units), precision: dashboardConfig.Precision}
+}
+
+// dashboard returns the dashboard with its quantities converted, and those of its members, stating its units.
+func (c unitConverter) dashboard(dashboard Dashboard) Dashboard {
+	dashboard = c.quantities(dashboard)
+	dashboard.Units = &c.units
+	return dashboard
+}
+
+// quantities returns the dashboard with its quantities converted, and those of its members.
+func (c unitConverter) quantities(dashboard Dashboard) Dashboard {
+	dashboard.Features = dashboard.Features.withWeather(c.weather(dashboard.Features.weather()))
+	dashboard.Features.Area = c.area(dashboard.Features.Area)
+	dashboard.Features.Forecast = c.forecast(dashboard.Features.Forecast)
+	dashboard.Features.WeatherPoints = c.points(dashboard.Features.WeatherPoints)
+
+	if dashboard.Aggregates != nil {
+		aggregates := *dashboard.Aggregates
+		aggregates.Area = c.area(aggregates.Area)
+		aggregates.Temperature = c.temperature(aggregates.Temperature)
+		dashboard.Aggregates = &aggregates
+	}
+
+	if dashboard.Members != nil {
+		members := make([]Dashboard, len(dashboard.Members))
+		for i, member := range dashboard.Members {
+			members[i] = c.quantities(member)
+		}
+		dashboard.Members = members
+	}
+	return dashboard
+}
+
+// weather returns the weather features converted.
+func (c unitConverter) weather(weather WeatherFeatures) WeatherFeatures {
+	return WeatherFeatures{
+		Temperature:    c.temperature(weather.Temperature),
+		Precipitation:  c.scale(weather.Precipitation, precipitationFactors[c.units.Precipitation]),
+		TemperatureMin: c.temperature(weather.TemperatureMin),
+		TemperatureMax: c.temperature(weather.TemperatureMax),
+		WindSpeed:      c.scale(weather.WindSpeed, windSpeedFactors[c.units.WindSpeed]),
+		WindGusts:      c.scale(weather.WindGusts, windSpeedFactors[c.units.WindSpeed]),
+		Humidity:       c.scale(weather.Humidity, 1),
+		CloudCover:     c.scale(weather.CloudCover, 1),
+		UvIndex:        c.scale(weather.UvIndex, 1),
+	}
+}
+
+// forecast returns the days of a forecast with their weather features converted.
+func (c unitConverter) forecast(forecast []ForecastDay) []ForecastDay {
+	if forecast == nil {
+		return nil
+	}
+	converted := make([]ForecastDay, len(forecast))
+	for i, day := range forecast {
+		converted[i] = ForecastDay{Date: day.Date, WeatherFeatures: c.weather(day.WeatherFeatures)}
+	}
+	return converted
+}
+
+// points returns the weather at each point with its features and forecast converted.
+func (c unitConverter) points(points []PointWeather) []PointWeather {
+	if points == nil {
+		return nil
+	}
+	converted := make([]PointWeather, len(points))
+	for i, point := range points {
+		converted[i] = PointWeather{
+			Coordinates:     point.Coordinates,
+			WeatherFeatures: c.weather(point.WeatherFeatures),
+			Forecast:        c.forecast(point.Forecast),
+		}
+	}
+	return converted
+}
+
+// area returns an area in square kilometres converted.
+func (c unitConverter) area(area *float64) *float64 {
+	return c.scale(area, areaFactors[c.units.Area])
+}
+
+// temperature returns a temperature in degrees Celsius converted.
+func (c unitConverter) temperature(temperature *float64) *float64 {
+	if temperature == nil || c.units.Temperature != requests.UnitFahrenheit {
+		return c.scale(temperature, 1)
+	}
+	fahrenheit := *temperature*9/5 + 32
+	return c.scale(&fahrenheit, 1)
+}
+
+// scale returns a new value of the given value multiplied by the factor and rounded, or nil if there is no value. The
+// value is never modified, as it may be shared with the caches.
+func (c unitConverter) scale(value *float64, factor float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	scaled := *value * factor
+	if c.precision != nil {
+		pow := math.Pow10(*c.precision)
+		scaled = math.Round(scaled*pow) / pow
+	}
+	return &scaled
+}
@@ -0,0 +1,182 @@
+package dashboards
+
+import (
+	"assignment-2/internal/http/datatransfers/requests"
+	"reflect"
+	"testing"
+)
+
+func Test_resolveUnits(t *testing.T) {
+	metric := DashboardUnits{Temperature: "celsius", Precipitation: "mm", WindSpeed: "km/h", Area: "km2"}
+
+	tests := []struct {
+		name  string
+		units requests.Units
+		want  DashboardUnits
+	}{
+		{
+			name:  "Metric by default",
+			units: requests.Units{},
+			want:  metric,
+		},
+		{
+			name:  "Imperial",
+			units: requests.Units{System: requests.UnitsImperial},
+			want:  DashboardUnits{Temperature: "fahrenheit", Precipitation: "in", WindSpeed: "mph", Area: "mi2"},
+		},
+		{
+			name:  "Custom is metric but for the given units",
+			units: requests.Units{System: requests.UnitsCustom, Temperature: "fahrenheit", WindSpeed: "m/s"},
+			want:  DashboardUnits{Temperature: "fahrenheit", Precipitation: "mm", WindSpeed: "m/s", Area: "km2"},
+		},
+		{
+			name:  "Units are only used by the custom system",
+			units: requests.Units{System: requests.UnitsMetric, Temperature: "fahrenheit"},
+			want:  metric,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := resolveUnits(tt.units); got != tt.want {
+					t.Errorf("resolveUnits() = %+v, want %+v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_unitConverter_dashboard(t *testing.T) {
+	precision := func(value int) *int { return &value }
+	population := 5
+	dashboard := func() Dashboard {
+		return Dashboard{
+			Country: "Norway",
+			Features: DashboardFeatures{
+				Temperature:      float(10),
+				Precipitation:    float(25.4),
+				WindSpeed:        float(36),
+				Humidity:         float(81.26),
+				Area:             float(2.589988110336),
+				Population:       &population,
+				TargetCurrencies: map[string]float64{"EUR": 0.08512},
+				Forecast: []ForecastDay{
+					{Date: "2024-04-01", WeatherFeatures: WeatherFeatures{TemperatureMax: float(-40)}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config requests.DashboardConfig
+		want   Dashboard
+	}{
+		{
+			name:   "Metric without precision is unchanged",
+			config: requests.DashboardConfig{},
+			want: func() Dashboard {
+				want := dashboard()
+				want.Units = &DashboardUnits{
+					Temperature: "celsius", Precipitation: "mm", WindSpeed: "km/h", Area: "km2",
+				}
+				return want
+			}(),
+		},
+		{
+			name: "Imperial rounded to one decimal",
+			config: requests.DashboardConfig{
+				Units:     requests.Units{System: requests.UnitsImperial},
+				Precision: precision(1),
+			},
+			want: Dashboard{
+				Country: "Norway",
+				Features: DashboardFeatures{
+					Temperature:      float(50),
+					Precipitation:    float(1),
+					WindSpeed:        float(22.4),
+					Humidity:         float(81.3),
+					Area:             float(1),
+					Population:       &population,
+					TargetCurrencies: map[string]float64{"EUR": 0.08512},
+					Forecast: []ForecastDay{
+						{Date: "2024-04-01", WeatherFeatures: WeatherFeatures{TemperatureMax: float(-40)}},
+					},
+				},
+				Units: &DashboardUnits{
+					Temperature: "fahrenheit", Precipitation: "in", WindSpeed: "mph", Area: "mi2",
+				},
+			},
+		},
+		{
+			name: "Custom units rounded to whole numbers",
+			config: requests.DashboardConfig{
+				Units:     requests.Units{System: requests.UnitsCustom, WindSpeed: "m/s"},
+				Precision: precision(0),
+			},
+			want: Dashboard{
+				Country: "Norway",
+				Features: DashboardFeatures{
+					Temperature:      float(10),
+					Precipitation:    float(25),
+					WindSpeed:        float(10),
+					Humidity:         float(81),
+					Area:             float(3),
+					Population:       &population,
+					TargetCurrencies: map[string]float64{"EUR": 0.08512},
+					Forecast: []ForecastDay{
+						{Date: "2024-04-01", WeatherFeatures: WeatherFeatures{TemperatureMax: float(-40)}},
+					},
+				},
+				Units: &DashboardUnits{
+					Temperature: "celsius", Precipitation: "mm", WindSpeed: "m/s", Area: "km2",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				original := dashboard()
+				got := newUnitConverter(tt.config).dashboard(original)
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("dashboard() = %+v, want %+v", got, tt.want)
+				}
+				// The values may be shared with the caches, so they must not be modified
+				if !reflect.DeepEqual(original, dashboard()) {
+					t.Errorf("dashboard() modified the original dashboard to %+v", original)
+				}
+			},
+		)
+	}
+}
+
+func Test_unitConverter_regional(t *testing.T) {
+	population := 10
+	dashboard := Dashboard{
+		Members: []Dashboard{
+			{IsoCode: "NO", Features: DashboardFeatures{Temperature: float(0)}},
+		},
+		Aggregates: &RegionAggregates{
+			Countries:   1,
+			Population:  &population,
+			Area:        float(2.589988110336),
+			Temperature: float(100),
+		},
+	}
+
+	precision := 6
+	got := newUnitConverter(
+		requests.DashboardConfig{Units: requests.Units{System: requests.UnitsImperial}, Precision: &precision},
+	).dashboard(dashboard)
+
+	if got.Members[0].Units != nil || *got.Members[0].Features.Temperature != 32 {
+		t.Errorf("dashboard() members = %+v, want 32 degrees without units of their own", got.Members)
+	}
+	if *got.Aggregates.Temperature != 212 || *got.Aggregates.Area != 1 || *got.Aggregates.Population != 10 {
+		t.Errorf("dashboard() aggregates = %+v, want 212 degrees, 1 square mile and 10 people", got.Aggregates)
+	}
+	if *dashboard.Aggregates.Temperature != 100 {
+		t.Errorf("dashboard() modified the original aggregates to %+v", dashboard.Aggregates)
+	}
+}
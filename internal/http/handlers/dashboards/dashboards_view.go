@@ -0,0 +1,357 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	utils2 "assignment-2/internal/utils"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// viewFiles are the templates and stylesheet of the HTML view and of badges, which are served without any external
+// assets
+//
+//go:embed view
+var viewFiles embed.FS
+
+// viewTemplate renders the HTML view of a dashboard
+var viewTemplate = template.Must(template.ParseFS(viewFiles, "view/dashboard.html"))
+
+// viewStyle is the stylesheet of the HTML view, inlined into the page
+var viewStyle = template.CSS(mustReadViewFile("view/dashboard.css"))
+
+// Size of the sparklines, and the largest number of snapshots they show
+const (
+	sparklineWidth  = 100
+	sparklineHeight = 20
+	sparklineLength = 50
+)
+
+// Implemented methods for the view endpoint
+var implementedMethodsView = []string{
+	http.MethodGet,
+}
+
+// Endpoint for the HTML view of dashboards
+var dashboardsViewEndpoint = inhouse.Endpoint{
+	Path:    constants.DashboardsPath + "{id}/view",
+	Methods: implementedMethodsView,
+	Description: "Endpoint for the HTML view of dashboards. Renders the populated dashboard as a page with a table of " +
+		"its features and sparklines of their history, which refreshes itself every refresh seconds.",
+}
+
+// dashboardView is the data the HTML view of a dashboard is rendered from.
+type dashboardView struct {
+	Dashboard Dashboard
+	Language  string
+	Retrieved string
+	Units     string
+	Refresh   int
+	Errors    []string
+	Sections  []viewSection
+	Style     template.CSS
+}
+
+// viewSection is a table of the HTML view, of the features of a country or the aggregates of a region.
+type viewSection struct {
+	Title string
+	Rows  []viewRow
+}
+
+// viewRow is a feature of the HTML view, with the sparkline of its history if it has one.
+type viewRow struct {
+	Feature   string
+	Value     string
+	Sparkline *sparkline
+}
+
+// sparkline is an SVG polyline of the history of a numeric feature, from its lowest to its highest value.
+type sparkline struct {
+	Width, Height int
+	Points        string
+	Min, Max      string
+}
+
+// ViewHandler handles the /dashboard/v1/dashboards/{id}/view path.
+// It currently only supports GET requests
+func ViewHandler(w http.ResponseWriter, r *http.Request) {
+	// Switch on the HTTP request method
+	switch r.Method {
+	case http.MethodGet:
+		handleDashboardsViewRequest(w, r)
+
+	default:
+		// If the method is not implemented, return an error with the allowed methods
+		http.Error(
+			w, fmt.Sprintf(
+				"REST Method '%s' not supported. Currently only '%v' are supported.", r.Method,
+				implementedMethodsView,
+			), http.StatusNotImplemented,
+		)
+		return
+	}
+}
+
+// handleDashboardsViewRequest handles the GET request for the /dashboard/v1/dashboards/{id}/view path.
+// It is used to retrieve the populated dashboard as an HTML page, with the same parameters as the JSON dashboard. As
+// the page may be left open and refresh itself indefinitely, it records no snapshots and triggers no events.
+func handleDashboardsViewRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := utils2.GetIDFromRequest(r)
+	if err != nil {
+		http.Error(w, constants.ErrIDInvalid, http.StatusBadRequest)
+		return
+	}
+
+	refresh, err := parseRefresh(r.URL.Query().Get("refresh"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	amount, err := parseAmount(r.URL.Query().Get("amount"))
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Populate the dashboard without recording a snapshot or triggering the INVOKE event, as the page refreshes itself
+	options := Options{Units: r.URL.Query().Get("units"), Language: utils2.GetLanguageFromRequest(r), Amount: amount}
+	_, dashboard, err := populateDashboard(r.Context(), id, options)
+	if err != nil {
+		http.Error(w, err.Error(), dashboardErrorStatus(err))
+		return
+	}
+	dashboard = localizeDashboard(r.Context(), dashboard, options.Language)
+	dashboard = withAmount(dashboard, options.Amount)
+
+	var page bytes.Buffer
+	err = viewTemplate.Execute(&page, newDashboardView(dashboard, dashboardHistory(id, dashboard), refresh))
+	if err != nil {
+		log.Println(constants.ErrDashboardView + err.Error())
+		http.Error(w, constants.ErrDashboardView, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", "text/html; charset=utf-8")
+	if dashboard.IsPartial() {
+		w.Header().Set(constants.DashboardPartialHeader, "true")
+	}
+	if dashboard.Language != "" {
+		w.Header().Set("Content-Language", dashboard.Language)
+	}
+
+	_, err = w.Write(page.Bytes())
+	if err != nil {
+		log.Println(constants.ErrWriteResponse + err.Error())
+		http.Error(w, constants.ErrWriteResponse, http.StatusInternalServerError)
+		return
+	}
+}
+
+// parseRefresh parses the refresh parameter of a view request, the seconds between refreshes of the page, where 0
+// disables them. Without it, the page refreshes as often as live dashboards.
+func parseRefresh(value string) (int, error) {
+	if value == "" {
+		return int(utils2.GetLiveRefreshInterval().Seconds()), nil
+	}
+	refresh, err := strconv.Atoi(value)
+	if err != nil || refresh != 0 && (refresh < constants.MinViewRefresh || refresh > constants.MaxViewRefresh) {
+		return 0, fmt.Errorf(constants.ErrDashboardViewRefresh)
+	}
+	return refresh, nil
+}
+
+// dashboardHistory returns the values of the numeric features of the latest snapshots of the dashboard with the given
+// ID, oldest first, by their path. Snapshots in other units than the dashboard are left out. As the history is not
+// worth failing the view for, errors are only logged.
+func dashboardHistory(id string, dashboard Dashboard) map[string][]float64 {
+	snapshots, err := db.GetLatestSubDocuments[inhouse.DashboardSnapshot](
+		id,
+		db.DashboardCollection,
+		db.SnapshotCollection,
+		snapshotTimeField,
+		sparklineLength,
+	)
+	if err != nil {
+		log.Println(constants.ErrDashboardHistory + err.Error())
+		return nil
+	}
+	slices.Reverse(snapshots)
+
+	units := dashboard.Units
+	history := make(map[string][]float64)
+	for _, snapshot := range snapshots {
+		values, snapshotUnits, err := numericFeatures(snapshot.Dashboard)
+		if err != nil {
+			log.Println(constants.ErrJsonUnmarshal + err.Error())
+			continue
+		}
+		if units != nil && snapshotUnits != *units {
+			continue
+		}
+		for path, value := range values {
+			history[path] = append(history[path], value)
+		}
+	}
+	return history
+}
+
+// newDashboardView returns the data of the HTML view of the dashboard, with sparklines of the given history of its
+// numeric features, refreshing every given number of seconds. A regional dashboard has a table of its aggregates, and
+// one of the features of each member, which have no history of their own.
+func newDashboardView(dashboard Dashboard, history map[string][]float64, refresh int) dashboardView {
+	view := dashboardView{
+		Dashboard: dashboard,
+		Language:  dashboard.Language,
+		Retrieved: dashboard.LastRetrieval.Format(time.RFC1123),
+		Refresh:   refresh,
+		Style:     viewStyle,
+	}
+	if view.Language == "" {
+		view.Language = "en"
+	}
+	if dashboard.Units != nil {
+		view.Units = strings.Join(
+			[]string{
+				dashboard.Units.Temperature,
+				dashboard.Units.Precipitation,
+				dashboard.Units.WindSpeed,
+				dashboard.Units.Area,
+			}, ", ",
+		)
+	}
+	for service, dashboardError := range dashboard.Errors {
+		view.Errors = append(
+			view.Errors,
+			fmt.Sprintf("%s: %s (%s)", service, dashboardError.Message, strings.Join(dashboardError.Features, ", ")),
+		)
+	}
+	slices.Sort(view.Errors)
+
+	if dashboard.Aggregates == nil {
+		view.Sections = []viewSection{{Title: "Features", Rows: viewRows(dashboard.Features, "", history)}}
+		return view
+	}
+
+	view.Sections = []viewSection{{Title: "Aggregates", Rows: viewRows(dashboard.Aggregates, "aggregates", history)}}
+	for _, member := range dashboard.Members {
+		view.Sections = append(view.Sections, viewSection{Title: member.Country, Rows: viewRows(member.Features, "", nil)})
+	}
+	return view
+}
+
+// viewRows returns the rows of the features of a dashboard, or of its aggregates, sorted by their path, with the
+// sparklines of those in the given history under the given prefix. Lists of objects, such as the forecast, are left
+// out.
+func viewRows(features any, prefix string, history map[string][]float64) []viewRow {
+	marshaled, err := json.Marshal(features)
+	if err != nil {
+		log.Println(constants.ErrJsonMarshal + err.Error())
+		return nil
+	}
+	var object map[string]any
+	if err := json.Unmarshal(marshaled, &object); err != nil {
+		log.Println(constants.ErrJsonUnmarshal + err.Error())
+		return nil
+	}
+
+	values := make(map[string]string)
+	flattenValues("", object, values)
+
+	rows := make([]viewRow, 0, len(values))
+	for path, value := range values {
+		historyPath := path
+		if prefix != "" {
+			historyPath = prefix + "." + path
+		}
+		rows = append(rows, viewRow{Feature: path, Value: value, Sparkline: newSparkline(history[historyPath])})
+	}
+	slices.SortFunc(rows, func(a, b viewRow) int { return strings.Compare(a.Feature, b.Feature) })
+	return rows
+}
+
+// flattenValues adds the numbers, strings and lists of them of a JSON value to values by their dotted path, starting
+// with the given prefix, formatted for the HTML view. Empty strings and lists of objects are left out.
+func flattenValues(prefix string, value any, values map[string]string) {
+	switch value := value.(type) {
+	case float64:
+		values[prefix] = strconv.FormatFloat(value, 'f', -1, 64)
+	case string:
+		if value != "" {
+			values[prefix] = value
+		}
+	case map[string]any:
+		for key, element := range value {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenValues(key, element, values)
+		}
+	case []any:
+		elements := make([]string, 0, len(value))
+		for _, element := range value {
+			switch element := element.(type) {
+			case float64:
+				elements = append(elements, strconv.FormatFloat(element, 'f', -1, 64))
+			case string:
+				elements = append(elements, element)
+			default:
+				return
+			}
+		}
+		if len(elements) > 0 {
+			values[prefix] = strings.Join(elements, ", ")
+		}
+	}
+}
+
+// newSparkline returns the sparkline of the given values, or nil if there are fewer than two. The lowest value is at
+// the bottom and the highest at the top, and equal values are a line through the middle.
+func newSparkline(values []float64) *sparkline {
+	if len(values) < 2 {
+		return nil
+	}
+
+	lowest, highest := slices.Min(values), slices.Max(values)
+	points := make([]string, len(values))
+	for i, value := range values {
+		x := float64(i) * sparklineWidth / float64(len(values)-1)
+		y := float64(sparklineHeight) / 2
+		if highest > lowest {
+			y = sparklineHeight - (value-lowest)/(highest-lowest)*sparklineHeight
+		}
+		points[i] = strconv.FormatFloat(round2(x), 'f', -1, 64) + "," + strconv.FormatFloat(round2(y), 'f', -1, 64)
+	}
+	return &sparkline{
+		Width:  sparklineWidth,
+		Height: sparklineHeight,
+		Points: strings.Join(points, " "),
+		Min:    strconv.FormatFloat(lowest, 'f', -1, 64),
+		Max:    strconv.FormatFloat(highest, 'f', -1, 64),
+	}
+}
+
+// round2 rounds the coordinate of a point of a sparkline to two decimals, which is finer than any screen shows it.
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// mustReadViewFile reads an embedded file of the HTML view, which is there unless the build is broken.
+func mustReadViewFile(name string) string {
+	content, err := viewFiles.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return string(content)
+}
@@ -0,0 +1,241 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/utils"
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestViewHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		statusCode int
+		wantBody   string
+	}{
+		{
+			name:       "NegativeTestViewHandler",
+			method:     http.MethodPost,
+			target:     "/?id=1",
+			statusCode: http.StatusNotImplemented,
+		},
+		{
+			name:       "NoIDTestViewHandler",
+			method:     http.MethodGet,
+			target:     "/",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrIDInvalid,
+		},
+		{
+			name:       "InvalidRefreshTestViewHandler",
+			method:     http.MethodGet,
+			target:     "/?id=1&refresh=5",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardViewRefresh,
+		},
+		{
+			name:       "InvalidAmountTestViewHandler",
+			method:     http.MethodGet,
+			target:     "/?id=1&amount=-1",
+			statusCode: http.StatusBadRequest,
+			wantBody:   constants.ErrDashboardAmount,
+		},
+	}
+
+	// Run the tests
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Create a mock request
+				req := httptest.NewRequest(tt.method, tt.target, nil)
+
+				// Create a mock response recorder
+				w := httptest.NewRecorder()
+
+				// Call the handler
+				ViewHandler(w, req)
+
+				// Check if the status code matches expected
+				if w.Code != tt.statusCode {
+					log.Println("Testing: ", tt.name)
+					t.Errorf(
+						"handler returned wrong status code: got %v want %v",
+						w.Code, tt.statusCode,
+					)
+				}
+				if !strings.Contains(w.Body.String(), tt.wantBody) {
+					t.Errorf("handler returned body %q, want %q", w.Body.String(), tt.wantBody)
+				}
+			},
+		)
+	}
+}
+
+func Test_parseRefresh(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "Default", value: "", want: int(utils.DefaultLiveRefreshInterval.Seconds())},
+		{name: "Disabled", value: "0", want: 0},
+		{name: "Shortest", value: "10", want: 10},
+		{name: "Longest", value: "86400", want: 86400},
+		{name: "Too short", value: "9", wantErr: true},
+		{name: "Too long", value: "86401", wantErr: true},
+		{name: "Not a number", value: "1m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := parseRefresh(tt.value)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("parseRefresh() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("parseRefresh() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_newSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   *sparkline
+	}{
+		{
+			name:   "Too few values",
+			values: []float64{4},
+		},
+		{
+			name:   "Rising and falling",
+			values: []float64{2, 6, 4},
+			want: &sparkline{
+				Width: sparklineWidth, Height: sparklineHeight, Points: "0,20 50,0 100,10", Min: "2", Max: "6",
+			},
+		},
+		{
+			name:   "Constant",
+			values: []float64{1.5, 1.5},
+			want: &sparkline{
+				Width: sparklineWidth, Height: sparklineHeight, Points: "0,10 100,10", Min: "1.5", Max: "1.5",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := newSparkline(tt.values); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("newSparkline() = %+v, want %+v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_viewRows(t *testing.T) {
+	features := DashboardFeatures{
+		Temperature: float(4.2),
+		Capital:     nonEmpty("Oslo"),
+		Population:  intPointer(5379475),
+		Languages:   []string{"Norwegian Nynorsk", "Norwegian Bokmål"},
+		Forecast:    []ForecastDay{{Date: "2024-04-18", WeatherFeatures: WeatherFeatures{Temperature: float(3)}}},
+		Coordinates: &inhouse.Coordinates{Latitude: 62, Longitude: 10},
+	}
+	history := map[string][]float64{"temperature": {3.8, 4.2}, "area": {1, 2}}
+
+	var got []string
+	for _, row := range viewRows(features, "", history) {
+		got = append(got, row.Feature+"="+row.Value)
+		if (row.Sparkline != nil) != (row.Feature == "temperature") {
+			t.Errorf("viewRows() sparkline of %s = %+v", row.Feature, row.Sparkline)
+		}
+	}
+	want := []string{
+		"capital=Oslo",
+		"coordinates.latitude=62",
+		"coordinates.longitude=10",
+		"languages=Norwegian Nynorsk, Norwegian Bokmål",
+		"population=5379475",
+		"temperature=4.2",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("viewRows() = %v, want %v", got, want)
+	}
+
+	// The history of aggregates is under their prefix
+	rows := viewRows(&RegionAggregates{Countries: 2, Area: float(3)}, "aggregates", map[string][]float64{
+		"aggregates.area": {1, 3},
+	})
+	for _, row := range rows {
+		if (row.Sparkline != nil) != (row.Feature == "area") {
+			t.Errorf("viewRows() sparkline of %s = %+v", row.Feature, row.Sparkline)
+		}
+	}
+}
+
+func Test_renderView(t *testing.T) {
+	retrieval := time.Date(2024, 4, 18, 16, 37, 42, 0, time.UTC)
+	units := resolveUnits(requests.Units{})
+	dashboard := Dashboard{
+		Country:       "Norvège",
+		IsoCode:       "NO",
+		Features:      DashboardFeatures{Temperature: float(4.2), Capital: nonEmpty("<Oslo>")},
+		Errors:        map[string]DashboardError{meteoSource: {Features: []string{"precipitation"}, Message: "down"}},
+		LastRetrieval: retrieval,
+		Units:         &units,
+		Language:      "fr",
+	}
+	history := map[string][]float64{"temperature": {3.8, 4.2}}
+
+	var page bytes.Buffer
+	if err := viewTemplate.Execute(&page, newDashboardView(dashboard, history, 60)); err != nil {
+		t.Fatalf("viewTemplate.Execute() error = %v", err)
+	}
+	body := page.String()
+
+	for _, want := range []string{
+		`<html lang="fr">`,
+		`<meta http-equiv="refresh" content="60">`,
+		`<h1>Norvège <span class="iso">NO</span></h1>`,
+		"Thu, 18 Apr 2024 16:37:42 UTC",
+		"celsius, mm, km/h, km2",
+		"meteo: down (precipitation)",
+		`<polyline points="0,20 100,0"/>`,
+		"&lt;Oslo&gt;",
+		".sparkline {",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("rendered view does not contain %q:\n%s", want, body)
+		}
+	}
+	// The page has no external assets
+	for _, unwanted := range []string{"<script", "<link", "http://", "https://"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("rendered view contains %q", unwanted)
+		}
+	}
+
+	// Without a refresh, the page does not refresh itself
+	page.Reset()
+	if err := viewTemplate.Execute(&page, newDashboardView(dashboard, nil, 0)); err != nil {
+		t.Fatalf("viewTemplate.Execute() error = %v", err)
+	}
+	if strings.Contains(page.String(), "http-equiv") {
+		t.Errorf("rendered view refreshes without a refresh")
+	}
+}
@@ -0,0 +1,381 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/providers"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WeatherFeatures is the struct for the weather features of a day. Temperature, precipitation, humidity and cloud
+// cover are the means of the hourly values, and wind speed, wind gusts and UV index are the maxima.
+type WeatherFeatures struct {
+	Temperature    *float64 `json:"temperature,omitempty"`
+	Precipitation  *float64 `json:"precipitation,omitempty"`
+	TemperatureMin *float64 `json:"temperatureMin,omitempty"`
+	TemperatureMax *float64 `json:"temperatureMax,omitempty"`
+	WindSpeed      *float64 `json:"windSpeed,omitempty"`
+	WindGusts      *float64 `json:"windGusts,omitempty"`
+	Humidity       *float64 `json:"humidity,omitempty"`
+	CloudCover     *float64 `json:"cloudCover,omitempty"`
+	UvIndex        *float64 `json:"uvIndex,omitempty"`
+}
+
+// ForecastDay is the struct for the weather features of a day of the forecast
+type ForecastDay struct {
+	Date string `json:"date"`
+	WeatherFeatures
+}
+
+// PointWeather is the struct for the weather at one of the points the weather of the dashboard is measured at
+type PointWeather struct {
+	Coordinates inhouse.Coordinates `json:"coordinates"`
+	WeatherFeatures
+	Forecast []ForecastDay `json:"forecast,omitempty"`
+}
+
+// getWeatherData gets the weather at the registered location for the given number of days, starting today. The
+// weather at several points is averaged, and also reported for each point if the location is separate. The features
+// state the location the weather is measured at.
+func getWeatherData(
+	ctx context.Context,
+	location requests.WeatherLocation,
+	country DashboardFeatures,
+	days int,
+) (DashboardFeatures, error) {
+	location, err := resolveWeatherLocation(location, country)
+	if err != nil {
+		return DashboardFeatures{}, err
+	}
+
+	// Get the weather at every point concurrently
+	pointFeatures := make([]DashboardFeatures, len(location.Points))
+	pointErrs := make([]error, len(location.Points))
+	var wg sync.WaitGroup
+	for i := range location.Points {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pointFeatures[i], pointErrs[i] = getMeteoData(ctx, &location.Points[i], days)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err = range pointErrs {
+		if err != nil {
+			return DashboardFeatures{}, err
+		}
+	}
+
+	features := averagePoints(pointFeatures)
+	features.WeatherLocation = &location
+	if location.Separate {
+		for i, point := range pointFeatures {
+			features.WeatherPoints = append(
+				features.WeatherPoints, PointWeather{
+					Coordinates:     location.Points[i],
+					WeatherFeatures: point.weather(),
+					Forecast:        point.Forecast,
+				},
+			)
+		}
+	}
+
+	return features, nil
+}
+
+// resolveWeatherLocation returns the registered location with its source and the points the weather is measured at.
+// The weather is measured at the centroid of the country by default.
+func resolveWeatherLocation(
+	location requests.WeatherLocation,
+	country DashboardFeatures,
+) (requests.WeatherLocation, error) {
+	resolved := requests.WeatherLocation{Source: location.Source, Separate: location.Separate}
+	switch location.Source {
+	case requests.WeatherAtCapital:
+		if country.CapitalCoordinates == nil {
+			return requests.WeatherLocation{}, fmt.Errorf(constants.ErrDashboardNoCapitalCoordinates)
+		}
+		resolved.Points = []inhouse.Coordinates{*country.CapitalCoordinates}
+	case requests.WeatherAtPoints:
+		resolved.Points = append(resolved.Points, location.Points...)
+	default:
+		if country.Coordinates == nil {
+			return requests.WeatherLocation{}, fmt.Errorf(constants.ErrDashboardNoCoordinates)
+		}
+		resolved.Source = requests.WeatherAtCentroid
+		resolved.Points = []inhouse.Coordinates{*country.Coordinates}
+	}
+	return resolved, nil
+}
+
+// averagePoints returns the weather features averaged over the weather at several points, by day of the forecast of
+// the first point. The timezone is that of the first point.
+func averagePoints(points []DashboardFeatures) DashboardFeatures {
+	if len(points) == 1 {
+		return points[0]
+	}
+
+	var forecastDays []ForecastDay
+	for _, day := range points[0].Forecast {
+		var weathers []WeatherFeatures
+		for _, point := range points {
+			for _, pointDay := range point.Forecast {
+				if pointDay.Date == day.Date {
+					weathers = append(weathers, pointDay.WeatherFeatures)
+				}
+			}
+		}
+		forecastDays = append(forecastDays, ForecastDay{Date: day.Date, WeatherFeatures: averageWeather(weathers)})
+	}
+	if len(forecastDays) == 0 {
+		return DashboardFeatures{}
+	}
+
+	return DashboardFeatures{
+		Forecast: forecastDays,
+		Location: points[0].Location,
+	}.withWeather(forecastDays[0].WeatherFeatures)
+}
+
+// averageWeather returns the means of the given weather features, rounded down to 5 decimal points. Features that none
+// of the weather features have are nil.
+func averageWeather(weathers []WeatherFeatures) WeatherFeatures {
+	mean := func(value func(WeatherFeatures) *float64) *float64 {
+		var values []float64
+		for _, weather := range weathers {
+			if v := value(weather); v != nil {
+				values = append(values, *v)
+			}
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		averaged := float64(int(average(values)*100000)) / 100000
+		return &averaged
+	}
+
+	return WeatherFeatures{
+		Temperature:    mean(func(w WeatherFeatures) *float64 { return w.Temperature }),
+		Precipitation:  mean(func(w WeatherFeatures) *float64 { return w.Precipitation }),
+		TemperatureMin: mean(func(w WeatherFeatures) *float64 { return w.TemperatureMin }),
+		TemperatureMax: mean(func(w WeatherFeatures) *float64 { return w.TemperatureMax }),
+		WindSpeed:      mean(func(w WeatherFeatures) *float64 { return w.WindSpeed }),
+		WindGusts:      mean(func(w WeatherFeatures) *float64 { return w.WindGusts }),
+		Humidity:       mean(func(w WeatherFeatures) *float64 { return w.Humidity }),
+		CloudCover:     mean(func(w WeatherFeatures) *float64 { return w.CloudCover }),
+		UvIndex:        mean(func(w WeatherFeatures) *float64 { return w.UvIndex }),
+	}
+}
+
+// weather returns the weather features of today.
+func (f DashboardFeatures) weather() WeatherFeatures {
+	return WeatherFeatures{
+		Temperature:    f.Temperature,
+		Precipitation:  f.Precipitation,
+		TemperatureMin: f.TemperatureMin,
+		TemperatureMax: f.TemperatureMax,
+		WindSpeed:      f.WindSpeed,
+		WindGusts:      f.WindGusts,
+		Humidity:       f.Humidity,
+		CloudCover:     f.CloudCover,
+		UvIndex:        f.UvIndex,
+	}
+}
+
+// withWeather returns the features with the given weather features of today.
+func (f DashboardFeatures) withWeather(weather WeatherFeatures) DashboardFeatures {
+	f.Temperature = weather.Temperature
+	f.Precipitation = weather.Precipitation
+	f.TemperatureMin = weather.TemperatureMin
+	f.TemperatureMax = weather.TemperatureMax
+	f.WindSpeed = weather.WindSpeed
+	f.WindGusts = weather.WindGusts
+	f.Humidity = weather.Humidity
+	f.CloudCover = weather.CloudCover
+	f.UvIndex = weather.UvIndex
+	return f
+}
+
+// hasWeatherFeatures returns whether any feature supplied by the weather service is enabled.
+func hasWeatherFeatures(features requests.ConfigFeatures) bool {
+	return len(enabledMeteoFeatures(features)) > 0
+}
+
+// forecastDays returns the number of days to get the weather for, which is at least today.
+func forecastDays(features requests.ConfigFeatures) int {
+	return max(features.ForecastDays, 1)
+}
+
+// enabledMeteoFeatures returns the names of the enabled features supplied by the weather service. The forecast is
+// only supplied along with a weather feature.
+func enabledMeteoFeatures(features requests.ConfigFeatures) []string {
+	var enabled []string
+	for _, feature := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"temperature", features.Temperature},
+		{"precipitation", features.Precipitation},
+		{"temperatureMin", features.TemperatureMin},
+		{"temperatureMax", features.TemperatureMax},
+		{"windSpeed", features.WindSpeed},
+		{"windGusts", features.WindGusts},
+		{"humidity", features.Humidity},
+		{"cloudCover", features.CloudCover},
+		{"uvIndex", features.UvIndex},
+	} {
+		if feature.enabled {
+			enabled = append(enabled, feature.name)
+		}
+	}
+
+	if len(enabled) > 0 && features.ForecastDays > 0 {
+		enabled = append(enabled, "forecast")
+	}
+	if len(enabled) > 0 && features.WeatherLocation.Separate {
+		enabled = append(enabled, "weatherPoints")
+	}
+	return enabled
+}
+
+// filterWeatherByConfig filters the weather features by the given config.
+func filterWeatherByConfig(weather WeatherFeatures, features requests.ConfigFeatures) WeatherFeatures {
+	var filtered WeatherFeatures
+	if features.Temperature {
+		filtered.Temperature = weather.Temperature
+	}
+	if features.Precipitation {
+		filtered.Precipitation = weather.Precipitation
+	}
+	if features.TemperatureMin {
+		filtered.TemperatureMin = weather.TemperatureMin
+	}
+	if features.TemperatureMax {
+		filtered.TemperatureMax = weather.TemperatureMax
+	}
+	if features.WindSpeed {
+		filtered.WindSpeed = weather.WindSpeed
+	}
+	if features.WindGusts {
+		filtered.WindGusts = weather.WindGusts
+	}
+	if features.Humidity {
+		filtered.Humidity = weather.Humidity
+	}
+	if features.CloudCover {
+		filtered.CloudCover = weather.CloudCover
+	}
+	if features.UvIndex {
+		filtered.UvIndex = weather.UvIndex
+	}
+	return filtered
+}
+
+// filterForecastByConfig filters the weather features of every day of the forecast by the given config, or returns nil
+// if the forecast is not enabled.
+func filterForecastByConfig(forecast []ForecastDay, features requests.ConfigFeatures) []ForecastDay {
+	if features.ForecastDays == 0 {
+		return nil
+	}
+
+	var filtered []ForecastDay
+	for _, day := range forecast {
+		filtered = append(
+			filtered, ForecastDay{
+				Date:            day.Date,
+				WeatherFeatures: filterWeatherByConfig(day.WeatherFeatures, features),
+			},
+		)
+	}
+	return filtered
+}
+
+// filterPointsByConfig filters the weather at every point by the given config, or returns nil if the weather is not
+// reported separately for every point.
+func filterPointsByConfig(points []PointWeather, features requests.ConfigFeatures) []PointWeather {
+	if !features.WeatherLocation.Separate {
+		return nil
+	}
+
+	var filtered []PointWeather
+	for _, point := range points {
+		filtered = append(
+			filtered, PointWeather{
+				Coordinates:     point.Coordinates,
+				WeatherFeatures: filterWeatherByConfig(point.WeatherFeatures, features),
+				Forecast:        filterForecastByConfig(point.Forecast, features),
+			},
+		)
+	}
+	return filtered
+}
+
+// summarizeDays summarizes the hourly forecast by day, in the order of the forecast.
+func summarizeDays(forecast providers.Forecast) []ForecastDay {
+	var days []ForecastDay
+	var hoursOfDays [][]int
+	for hour, t := range forecast.Time {
+		date := t.Format(time.DateOnly)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, ForecastDay{Date: date})
+			hoursOfDays = append(hoursOfDays, nil)
+		}
+		hoursOfDays[len(hoursOfDays)-1] = append(hoursOfDays[len(hoursOfDays)-1], hour)
+	}
+
+	for i, hours := range hoursOfDays {
+		days[i].WeatherFeatures = WeatherFeatures{
+			Temperature:    summarize(forecast.Temperature, hours, average),
+			Precipitation:  summarize(forecast.Precipitation, hours, average),
+			TemperatureMin: summarize(forecast.Temperature, hours, minimum),
+			TemperatureMax: summarize(forecast.Temperature, hours, maximum),
+			WindSpeed:      summarize(forecast.WindSpeed, hours, maximum),
+			WindGusts:      summarize(forecast.WindGusts, hours, maximum),
+			Humidity:       summarize(forecast.Humidity, hours, average),
+			CloudCover:     summarize(forecast.CloudCover, hours, average),
+			UvIndex:        summarize(forecast.UvIndex, hours, maximum),
+		}
+	}
+
+	return days
+}
+
+// summarize returns the summary of the values at the given hours, rounded down to 5 decimal points, or nil if there
+// are no values at the hours. Hours without a value are left out, instead of being summarized as 0.
+func summarize(values []*float64, hours []int, summary func([]float64) float64) *float64 {
+	var hourly []float64
+	for _, hour := range hours {
+		if hour < len(values) && values[hour] != nil {
+			hourly = append(hourly, *values[hour])
+		}
+	}
+	if len(hourly) == 0 {
+		return nil
+	}
+
+	summarized := float64(int(summary(hourly)*100000)) / 100000
+	return &summarized
+}
+
+// minimum returns the smallest of a non-empty slice of float64 elements.
+func minimum(elements []float64) float64 {
+	smallest := elements[0]
+	for _, element := range elements[1:] {
+		smallest = min(smallest, element)
+	}
+	return smallest
+}
+
+// maximum returns the largest of a non-empty slice of float64 elements.
+func maximum(elements []float64) float64 {
+	largest := elements[0]
+	for _, element := range elements[1:] {
+		largest = max(largest, element)
+	}
+	return largest
+}
@@ -0,0 +1,250 @@
+package dashboards
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/providers"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// float returns a pointer to the given float64.
+func float(value float64) *float64 {
+	return &value
+}
+
+func Test_summarizeDays(t *testing.T) {
+	forecast := providers.Forecast{
+		Time: []time.Time{
+			time.Date(2024, 4, 17, 11, 0, 0, 0, time.UTC),
+			time.Date(2024, 4, 17, 12, 0, 0, 0, time.UTC),
+			time.Date(2024, 4, 18, 11, 0, 0, 0, time.UTC),
+			time.Date(2024, 4, 18, 12, 0, 0, 0, time.UTC),
+		},
+		Temperature:   []*float64{float(1), float(3), float(-2), float(0)},
+		Precipitation: []*float64{float(0), float(0.5), float(1), float(2)},
+		Humidity:      []*float64{float(60), float(70), float(90), float(95)},
+		CloudCover:    []*float64{float(0), float(50), float(100), float(100)},
+		WindSpeed:     []*float64{float(3.5), float(4), float(10), float(8)},
+		WindGusts:     []*float64{float(7), float(9), float(20), float(18)},
+		// Hours without a value are left out, and a day without any has no value
+		UvIndex: []*float64{float(2), nil, nil, nil},
+	}
+
+	want := []ForecastDay{
+		{
+			Date: "2024-04-17",
+			WeatherFeatures: WeatherFeatures{
+				Temperature:    float(2),
+				Precipitation:  float(0.25),
+				TemperatureMin: float(1),
+				TemperatureMax: float(3),
+				WindSpeed:      float(4),
+				WindGusts:      float(9),
+				Humidity:       float(65),
+				CloudCover:     float(25),
+				UvIndex:        float(2),
+			},
+		},
+		{
+			Date: "2024-04-18",
+			WeatherFeatures: WeatherFeatures{
+				Temperature:    float(-1),
+				Precipitation:  float(1.5),
+				TemperatureMin: float(-2),
+				TemperatureMax: float(0),
+				WindSpeed:      float(10),
+				WindGusts:      float(20),
+				Humidity:       float(92.5),
+				CloudCover:     float(100),
+			},
+		},
+	}
+
+	if got := summarizeDays(forecast); !reflect.DeepEqual(got, want) {
+		t.Errorf("summarizeDays() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_filterWeatherByConfig(t *testing.T) {
+	weather := WeatherFeatures{
+		Temperature:    float(2),
+		Precipitation:  float(0.25),
+		TemperatureMin: float(1),
+		TemperatureMax: float(3),
+		WindSpeed:      float(4),
+		WindGusts:      float(9),
+		Humidity:       float(65),
+		CloudCover:     float(25),
+		UvIndex:        float(1.5),
+	}
+
+	tests := []struct {
+		name     string
+		features requests.ConfigFeatures
+		want     WeatherFeatures
+	}{
+		{
+			name:     "No weather features",
+			features: requests.ConfigFeatures{Capital: true},
+			want:     WeatherFeatures{},
+		},
+		{
+			name:     "Some weather features",
+			features: requests.ConfigFeatures{TemperatureMax: true, WindGusts: true, UvIndex: true},
+			want:     WeatherFeatures{TemperatureMax: float(3), WindGusts: float(9), UvIndex: float(1.5)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := filterWeatherByConfig(weather, tt.features); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("filterWeatherByConfig() = %+v, want %+v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_enabledMeteoFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		features requests.ConfigFeatures
+		want     []string
+	}{
+		{
+			name:     "Forecast without weather features",
+			features: requests.ConfigFeatures{Capital: true, ForecastDays: 3},
+			want:     nil,
+		},
+		{
+			name:     "Weather features without forecast",
+			features: requests.ConfigFeatures{Temperature: true, Humidity: true},
+			want:     []string{"temperature", "humidity"},
+		},
+		{
+			name:     "Weather features with forecast",
+			features: requests.ConfigFeatures{WindSpeed: true, ForecastDays: 3},
+			want:     []string{"windSpeed", "forecast"},
+		},
+		{
+			name: "Weather features reported for every point",
+			features: requests.ConfigFeatures{
+				UvIndex:         true,
+				WeatherLocation: requests.WeatherLocation{Source: requests.WeatherAtPoints, Separate: true},
+			},
+			want: []string{"uvIndex", "weatherPoints"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := enabledMeteoFeatures(tt.features); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("enabledMeteoFeatures() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_resolveWeatherLocation(t *testing.T) {
+	country := DashboardFeatures{
+		Coordinates:        &inhouse.Coordinates{Latitude: 62, Longitude: 10},
+		CapitalCoordinates: &inhouse.Coordinates{Latitude: 59.92, Longitude: 10.75},
+	}
+	points := []inhouse.Coordinates{{Latitude: 60.39, Longitude: 5.32}, {Latitude: 63.43, Longitude: 10.39}}
+
+	tests := []struct {
+		name     string
+		location requests.WeatherLocation
+		country  DashboardFeatures
+		want     requests.WeatherLocation
+		wantErr  string
+	}{
+		{
+			name:     "Centroid by default",
+			location: requests.WeatherLocation{},
+			country:  country,
+			want: requests.WeatherLocation{
+				Source: requests.WeatherAtCentroid,
+				Points: []inhouse.Coordinates{*country.Coordinates},
+			},
+		},
+		{
+			name:     "Capital",
+			location: requests.WeatherLocation{Source: requests.WeatherAtCapital},
+			country:  country,
+			want: requests.WeatherLocation{
+				Source: requests.WeatherAtCapital,
+				Points: []inhouse.Coordinates{*country.CapitalCoordinates},
+			},
+		},
+		{
+			name:     "Custom points",
+			location: requests.WeatherLocation{Source: requests.WeatherAtPoints, Points: points, Separate: true},
+			country:  country,
+			want:     requests.WeatherLocation{Source: requests.WeatherAtPoints, Points: points, Separate: true},
+		},
+		{
+			name:     "Capital without coordinates",
+			location: requests.WeatherLocation{Source: requests.WeatherAtCapital},
+			country:  DashboardFeatures{Coordinates: country.Coordinates},
+			wantErr:  constants.ErrDashboardNoCapitalCoordinates,
+		},
+		{
+			name:     "Centroid without coordinates",
+			location: requests.WeatherLocation{Source: requests.WeatherAtCentroid},
+			country:  DashboardFeatures{},
+			wantErr:  constants.ErrDashboardNoCoordinates,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := resolveWeatherLocation(tt.location, tt.country)
+				if tt.wantErr != "" {
+					if err == nil || err.Error() != tt.wantErr {
+						t.Errorf("resolveWeatherLocation() error = %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("resolveWeatherLocation() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("resolveWeatherLocation() = %+v, want %+v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func Test_averagePoints(t *testing.T) {
+	oslo := DashboardFeatures{
+		Forecast: []ForecastDay{
+			{Date: "2024-04-17", WeatherFeatures: WeatherFeatures{Temperature: float(10), Humidity: float(60)}},
+			{Date: "2024-04-18", WeatherFeatures: WeatherFeatures{Temperature: float(12), Humidity: float(70)}},
+		},
+	}
+	bergen := DashboardFeatures{
+		Forecast: []ForecastDay{
+			{Date: "2024-04-17", WeatherFeatures: WeatherFeatures{Temperature: float(7), Humidity: float(90)}},
+			{Date: "2024-04-18", WeatherFeatures: WeatherFeatures{Temperature: float(8)}},
+		},
+	}
+
+	want := DashboardFeatures{
+		Temperature: float(8.5),
+		Humidity:    float(75),
+		Forecast: []ForecastDay{
+			{Date: "2024-04-17", WeatherFeatures: WeatherFeatures{Temperature: float(8.5), Humidity: float(75)}},
+			{Date: "2024-04-18", WeatherFeatures: WeatherFeatures{Temperature: float(10), Humidity: float(70)}},
+		},
+	}
+
+	if got := averagePoints([]DashboardFeatures{oslo, bergen}); !reflect.DeepEqual(got, want) {
+		t.Errorf("averagePoints() = %+v, want %+v", got, want)
+	}
+}
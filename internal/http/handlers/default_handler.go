@@ -4,6 +4,8 @@ import (
 	"assignment-2/internal/constants"
 	"assignment-2/internal/http/datatransfers/inhouse"
 	"assignment-2/internal/http/handlers/dashboards"
+	"assignment-2/internal/http/handlers/events"
+	"assignment-2/internal/http/handlers/graphql"
 	"assignment-2/internal/http/handlers/notifications"
 	"assignment-2/internal/http/handlers/registrations"
 	"assignment-2/internal/http/handlers/status"
@@ -36,11 +38,15 @@ func Init() {
 	endpointsFromDashboards := dashboards.GetEndpointStructs()
 	endpointsFromNotifications := notifications.GetEndpointStructs()
 	endpointsFromStatus := status.GetEndpointStructs()
+	endpointsFromEvents := events.GetEndpointStructs()
+	endpointsFromGraphQL := graphql.GetEndpointStructs()
 
 	SiteMap.Endpoints = append(SiteMap.Endpoints, endpointsFromRegistrations...)
 	SiteMap.Endpoints = append(SiteMap.Endpoints, endpointsFromDashboards...)
 	SiteMap.Endpoints = append(SiteMap.Endpoints, endpointsFromNotifications...)
 	SiteMap.Endpoints = append(SiteMap.Endpoints, endpointsFromStatus...)
+	SiteMap.Endpoints = append(SiteMap.Endpoints, endpointsFromEvents...)
+	SiteMap.Endpoints = append(SiteMap.Endpoints, endpointsFromGraphQL...)
 }
 
 // DefaultHandler
@@ -0,0 +1,154 @@
+package events
+
+import (
+	"assignment-2/internal/utils"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a registration or notification event, as sent on the event stream.
+type Event struct {
+	ID             uint64    `json:"id"`
+	Event          string    `json:"event"`
+	Country        string    `json:"country"`
+	RegistrationID string    `json:"registrationId,omitempty"`
+	Time           time.Time `json:"time"`
+}
+
+// Filter decides which events a subscriber receives. Empty fields match everything.
+type Filter struct {
+	Country string
+	Events  []string
+}
+
+// matches returns true if the event passes the filter.
+func (f Filter) matches(event Event) bool {
+	if f.Country != "" && !strings.EqualFold(f.Country, event.Country) {
+		return false
+	}
+	if len(f.Events) == 0 {
+		return true
+	}
+	for _, e := range f.Events {
+		if e == event.Event {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription is a stream of events matching a filter. It must be closed with Unsubscribe.
+type Subscription struct {
+	Events chan Event
+	filter Filter
+}
+
+// subscriptionBuffer is the number of events that can be queued for a subscriber before events are dropped.
+const subscriptionBuffer = 32
+
+// broker keeps a bounded buffer of recent events, and fans new events out to the subscribers.
+type broker struct {
+	mu            sync.Mutex
+	lastID        uint64
+	recent        []Event
+	size          int
+	subscriptions map[*Subscription]struct{}
+}
+
+// defaultBroker is the broker used by the event stream and the handlers publishing events.
+var defaultBroker = newBroker(utils.GetEventsBufferSize())
+
+// newBroker creates a broker that keeps the given number of recent events.
+func newBroker(size int) *broker {
+	return &broker{
+		size:          size,
+		subscriptions: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish
+// Publishes an event to all subscribers of the event stream. The country is the ISO code of the registration.
+func Publish(event string, country string, registrationID string) {
+	defaultBroker.publish(event, country, registrationID)
+}
+
+// Subscribe
+// Subscribes to events matching the filter. Buffered events newer than lastEventID are returned, so a client can
+// resume a stream. Use lastEventID 0 to only receive new events.
+func Subscribe(filter Filter, lastEventID uint64) (*Subscription, []Event) {
+	return defaultBroker.subscribe(filter, lastEventID)
+}
+
+// Unsubscribe
+// Stops the subscription from receiving events.
+func Unsubscribe(subscription *Subscription) {
+	defaultBroker.unsubscribe(subscription)
+}
+
+// publish stores the event in the buffer of recent events and sends it to the matching subscribers.
+func (b *broker) publish(event string, country string, registrationID string) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	newEvent := Event{
+		ID:             b.lastID,
+		Event:          event,
+		Country:        strings.ToUpper(country),
+		RegistrationID: registrationID,
+		Time:           time.Now(),
+	}
+
+	// Drop the oldest event when the buffer is full
+	b.recent = append(b.recent, newEvent)
+	if len(b.recent) > b.size {
+		b.recent = b.recent[len(b.recent)-b.size:]
+	}
+
+	for subscription := range b.subscriptions {
+		if !subscription.filter.matches(newEvent) {
+			continue
+		}
+		// Never block the publisher on a slow subscriber
+		select {
+		case subscription.Events <- newEvent:
+		default:
+			log.Printf("event stream subscriber is too slow, dropped event %d\n", newEvent.ID)
+		}
+	}
+
+	return newEvent
+}
+
+// subscribe registers a subscription, and returns the buffered events after lastEventID matching the filter.
+func (b *broker) subscribe(filter Filter, lastEventID uint64) (*Subscription, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []Event
+	if lastEventID > 0 {
+		for _, event := range b.recent {
+			if event.ID > lastEventID && filter.matches(event) {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	subscription := &Subscription{
+		Events: make(chan Event, subscriptionBuffer),
+		filter: filter,
+	}
+	b.subscriptions[subscription] = struct{}{}
+
+	return subscription, missed
+}
+
+// unsubscribe removes the subscription.
+func (b *broker) unsubscribe(subscription *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscriptions, subscription)
+}
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Implemented methods for the endpoint
+var implementedMethods = []string{
+	http.MethodGet,
+}
+
+// Endpoint for streaming registration and notification events
+var eventsEndpoint = inhouse.Endpoint{
+	Path:    constants.EventsPath,
+	Methods: implementedMethods,
+	Description: "Endpoint for streaming REGISTER, CHANGE, DELETE and INVOKE events as server-sent events. " +
+		"Filter with the 'country' and 'event' query parameters, and resume with the Last-Event-ID header.",
+}
+
+// keepAliveInterval is how often a comment is sent on an idle stream, so proxies do not close the connection.
+const keepAliveInterval = 15 * time.Second
+
+// GetEndpointStructs returns the endpoint struct for the events endpoint.
+func GetEndpointStructs() []inhouse.Endpoint {
+	return []inhouse.Endpoint{eventsEndpoint}
+}
+
+// Handler handles the /dashboard/v1/events path.
+// It currently only supports GET requests
+func Handler(w http.ResponseWriter, r *http.Request) {
+	// Switch on the HTTP request method
+	switch r.Method {
+	case http.MethodGet:
+		handleEventsGetRequest(w, r)
+
+	default:
+		// If the method is not implemented, return an error with the allowed methods
+		http.Error(
+			w, fmt.Sprintf(
+				"REST Method '%s' not supported. Currently only '%v' are supported.", r.Method,
+				implementedMethods,
+			), http.StatusNotImplemented,
+		)
+		return
+	}
+}
+
+// handleEventsGetRequest handles the GET request for the /dashboard/v1/events path.
+// It streams events as they happen until the client disconnects.
+func handleEventsGetRequest(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, constants.ErrEventsStreamingUnsupported, http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := filterFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Browsers send the ID of the last received event when reconnecting
+	var lastEventID uint64
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		lastEventID, err = strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			http.Error(w, constants.ErrEventsInvalidLastEventID, http.StatusBadRequest)
+			return
+		}
+	}
+
+	subscription, missed := Subscribe(filter, lastEventID)
+	defer Unsubscribe(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// Send the events the client missed while disconnected
+	for _, event := range missed {
+		if err := writeEvent(w, event); err != nil {
+			log.Println(constants.ErrWriteResponse + err.Error())
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-subscription.Events:
+			if err := writeEvent(w, event); err != nil {
+				log.Println(constants.ErrWriteResponse + err.Error())
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// filterFromRequest creates an event filter from the 'country' and 'event' query parameters.
+// Events can be given as a comma separated list, or by repeating the parameter.
+func filterFromRequest(r *http.Request) (Filter, error) {
+	query := r.URL.Query()
+	filter := Filter{Country: strings.ToUpper(query.Get("country"))}
+
+	for _, value := range query["event"] {
+		for _, event := range strings.Split(value, ",") {
+			event = strings.ToUpper(strings.TrimSpace(event))
+			if event == "" {
+				continue
+			}
+			if !isImplementedEvent(event) {
+				return Filter{}, fmt.Errorf("%s: %s", constants.ErrNotificationsInvalidType, event)
+			}
+			filter.Events = append(filter.Events, event)
+		}
+	}
+
+	return filter, nil
+}
+
+// isImplementedEvent checks if the event is one of the implemented event types.
+func isImplementedEvent(event string) bool {
+	for _, implemented := range requests.ImplementedEvents {
+		if event == implemented {
+			return true
+		}
+	}
+	return false
+}
+
+// writeEvent writes the event in the server-sent events format.
+func writeEvent(w http.ResponseWriter, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Event, data)
+	return err
+}
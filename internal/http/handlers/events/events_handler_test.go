@@ -0,0 +1,193 @@
+package events
+
+import (
+	"assignment-2/internal/http/datatransfers/requests"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		header     map[string]string
+		statusCode int
+	}{
+		{
+			name:       "NegativeTestEventsHandler",
+			method:     http.MethodPost,
+			target:     "/",
+			statusCode: http.StatusNotImplemented,
+		},
+		{
+			name:       "InvalidEventTestEventsHandler",
+			method:     http.MethodGet,
+			target:     "/?event=INVALID_EVENT",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "InvalidLastEventIDTestEventsHandler",
+			method:     http.MethodGet,
+			target:     "/",
+			header:     map[string]string{"Last-Event-ID": "abc"},
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Create a mock request
+				req := httptest.NewRequest(tt.method, tt.target, nil)
+				for key, value := range tt.header {
+					req.Header.Set(key, value)
+				}
+
+				// Create a mock response recorder
+				w := httptest.NewRecorder()
+
+				// Call the handler
+				Handler(w, req)
+
+				// Check if the status code matches expected
+				if w.Code != tt.statusCode {
+					log.Println("Testing: ", tt.name)
+					t.Errorf(
+						"handler returned wrong status code: got %v want %v",
+						w.Code, tt.statusCode,
+					)
+				}
+			},
+		)
+	}
+}
+
+func Test_handleEventsGetRequest(t *testing.T) {
+	// Events published before the stream is opened, which should only be replayed when resuming
+	defaultBroker.publish(requests.EventRegister, "SE", "olderID")
+	missed := defaultBroker.publish(requests.EventRegister, "NO", "missedID")
+
+	tests := []struct {
+		name        string
+		target      string
+		lastEventID string
+		wanted      []string
+		unwanted    []string
+	}{
+		{
+			name:     "StreamNewEvents",
+			target:   "/",
+			wanted:   []string{"event: CHANGE", "event: INVOKE", "registrationId\":\"changedID\""},
+			unwanted: []string{"missedID"},
+		},
+		{
+			name:     "StreamFilteredByCountryAndEvent",
+			target:   "/?country=no&event=INVOKE",
+			wanted:   []string{"event: INVOKE"},
+			unwanted: []string{"event: CHANGE", "missedID"},
+		},
+		{
+			name:        "StreamResumedFromLastEventID",
+			target:      "/?country=NO",
+			lastEventID: strconv.FormatUint(missed.ID-1, 10),
+			wanted:      []string{"missedID", "event: INVOKE"},
+			unwanted:    []string{"olderID", "event: CHANGE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				req := httptest.NewRequest(http.MethodGet, tt.target, nil).WithContext(ctx)
+				if tt.lastEventID != "" {
+					req.Header.Set("Last-Event-ID", tt.lastEventID)
+				}
+				w := httptest.NewRecorder()
+
+				done := make(chan struct{})
+				subscriptionsBefore := subscriptionCount()
+				go func() {
+					handleEventsGetRequest(w, req)
+					close(done)
+				}()
+
+				// Wait for the handler to subscribe before publishing
+				for subscriptionCount() == subscriptionsBefore {
+					time.Sleep(time.Millisecond)
+				}
+
+				Publish(requests.EventChange, "SE", "changedID")
+				Publish(requests.EventInvoke, "NO", "invokedID")
+
+				// Give the handler time to write the events, then disconnect
+				time.Sleep(50 * time.Millisecond)
+				cancel()
+				<-done
+
+				body := w.Body.String()
+				if contentType := w.Header().Get("Content-Type"); contentType != "text/event-stream" {
+					t.Errorf("handleEventsGetRequest() content type = %v, want text/event-stream", contentType)
+				}
+				for _, want := range tt.wanted {
+					if !strings.Contains(body, want) {
+						t.Errorf("handleEventsGetRequest() body = %v, want it to contain %v", body, want)
+					}
+				}
+				for _, unwanted := range tt.unwanted {
+					if strings.Contains(body, unwanted) {
+						t.Errorf("handleEventsGetRequest() body = %v, want it to not contain %v", body, unwanted)
+					}
+				}
+			},
+		)
+	}
+}
+
+func Test_broker(t *testing.T) {
+	b := newBroker(2)
+	first := b.publish(requests.EventRegister, "no", "1")
+	b.publish(requests.EventChange, "NO", "2")
+	b.publish(requests.EventDelete, "SE", "3")
+
+	// Country codes are normalised to upper case
+	if first.Country != "NO" {
+		t.Errorf("publish() country = %v, want NO", first.Country)
+	}
+
+	// Only the two most recent events are kept
+	if len(b.recent) != 2 {
+		t.Errorf("publish() buffered %v events, want 2", len(b.recent))
+	}
+
+	subscription, missed := b.subscribe(Filter{Country: "NO"}, first.ID)
+	if len(missed) != 1 || missed[0].RegistrationID != "2" {
+		t.Errorf("subscribe() missed = %v, want only the CHANGE event for NO", missed)
+	}
+
+	b.publish(requests.EventInvoke, "SE", "4")
+	b.publish(requests.EventInvoke, "NO", "5")
+	received := <-subscription.Events
+	if received.RegistrationID != "5" {
+		t.Errorf("subscription received %v, want the INVOKE event for NO", received)
+	}
+
+	b.unsubscribe(subscription)
+	if len(b.subscriptions) != 0 {
+		t.Errorf("unsubscribe() left %v subscriptions, want 0", len(b.subscriptions))
+	}
+}
+
+// subscriptionCount returns the number of subscriptions on the default broker.
+func subscriptionCount() int {
+	defaultBroker.mu.Lock()
+	defer defaultBroker.mu.Unlock()
+	return len(defaultBroker.subscriptions)
+}
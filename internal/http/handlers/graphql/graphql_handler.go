@@ -0,0 +1,140 @@
+package graphql
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	gql "github.com/graphql-go/graphql"
+	"log"
+	"net/http"
+)
+
+// Implemented methods for the endpoint
+var implementedMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+}
+
+// Endpoint for querying registrations, dashboards, notifications and status with GraphQL
+var graphQLEndpoint = inhouse.Endpoint{
+	Path:    constants.GraphQLPath,
+	Methods: implementedMethods,
+	Description: "Endpoint for querying registrations, dashboards, notifications and status with GraphQL. " +
+		"Send the query in the 'query' and 'variables' query parameters, or as a JSON body with POST. " +
+		"Only the external services supplying the requested dashboard fields are called.",
+}
+
+// graphQLRequest is the body of a GraphQL POST request.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// GetEndpointStructs returns the endpoint struct for the GraphQL endpoint.
+func GetEndpointStructs() []inhouse.Endpoint {
+	return []inhouse.Endpoint{graphQLEndpoint}
+}
+
+// Handler handles the /dashboard/v1/graphql path.
+// It currently only supports GET and POST requests
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", "application/json")
+	// Switch on the HTTP request method
+	switch r.Method {
+	case http.MethodGet:
+		handleGraphQLGetRequest(w, r)
+	case http.MethodPost:
+		handleGraphQLPostRequest(w, r)
+
+	default:
+		// If the method is not implemented, return an error with the allowed methods
+		http.Error(
+			w, fmt.Sprintf(
+				"REST Method '%s' not supported. Currently only '%v' are supported.", r.Method,
+				implementedMethods,
+			), http.StatusNotImplemented,
+		)
+		return
+	}
+}
+
+// handleGraphQLGetRequest handles the GET request for the /dashboard/v1/graphql path.
+// The query and the JSON encoded variables are read from the query parameters.
+func handleGraphQLGetRequest(w http.ResponseWriter, r *http.Request) {
+	request := graphQLRequest{
+		Query:         r.URL.Query().Get("query"),
+		OperationName: r.URL.Query().Get("operationName"),
+	}
+
+	if variables := r.URL.Query().Get("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+			log.Println(constants.ErrGraphQLVariables, err.Error())
+			http.Error(w, constants.ErrGraphQLVariables, http.StatusBadRequest)
+			return
+		}
+	}
+
+	executeGraphQLRequest(w, r, request)
+}
+
+// handleGraphQLPostRequest handles the POST request for the /dashboard/v1/graphql path.
+// The query, variables and operation name are read from the JSON body.
+func handleGraphQLPostRequest(w http.ResponseWriter, r *http.Request) {
+	var request graphQLRequest
+	if err := utils.DecodeJSONBody(w, r, &request); err != nil {
+		log.Println(constants.ErrJsonDecode + err.Error())
+		http.Error(w, err.Message, err.Status)
+		return
+	}
+
+	executeGraphQLRequest(w, r, request)
+}
+
+// executeGraphQLRequest executes the request against the schema, and writes the result.
+// Errors in resolving fields are returned in the result, as is usual for GraphQL.
+func executeGraphQLRequest(w http.ResponseWriter, r *http.Request, request graphQLRequest) {
+	if request.Query == "" {
+		http.Error(w, constants.ErrGraphQLQueryMissing, http.StatusBadRequest)
+		return
+	}
+
+	if schemaErr != nil {
+		log.Println(constants.ErrGraphQLSchema, schemaErr.Error())
+		http.Error(w, constants.ErrGraphQLSchema, http.StatusInternalServerError)
+		return
+	}
+
+	// The dashboards in the query share a single deadline for the external services
+	ctx, cancel := context.WithTimeout(r.Context(), utils.GetDashboardTimeout())
+	defer cancel()
+
+	result := gql.Do(
+		gql.Params{
+			Schema:         schema,
+			RequestString:  request.Query,
+			VariableValues: request.Variables,
+			OperationName:  request.OperationName,
+			Context:        ctx,
+		},
+	)
+
+	// Marshal the result to JSON
+	marshaled, err := json.MarshalIndent(result, "", "\t")
+	if err != nil {
+		log.Println(constants.ErrJsonMarshal + err.Error())
+		http.Error(w, constants.ErrJsonMarshal, http.StatusInternalServerError)
+		return
+	}
+
+	// Write the result to the response
+	_, err = w.Write(marshaled)
+	if err != nil {
+		log.Println(constants.ErrWriteResponse + err.Error())
+		http.Error(w, constants.ErrWriteResponse, http.StatusInternalServerError)
+		return
+	}
+}
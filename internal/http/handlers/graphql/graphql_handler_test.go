@@ -0,0 +1,123 @@
+package graphql
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		body        string
+		contentType string
+		statusCode  int
+		wanted      string
+	}{
+		{
+			name:       "NegativeTestGraphQLHandler",
+			method:     http.MethodDelete,
+			target:     "/",
+			statusCode: http.StatusNotImplemented,
+		},
+		{
+			name:       "NoQueryTestGraphQLHandler",
+			method:     http.MethodGet,
+			target:     "/",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "InvalidVariablesTestGraphQLHandler",
+			method:     http.MethodGet,
+			target:     "/?query=" + url.QueryEscape("{ __typename }") + "&variables=" + url.QueryEscape("{"),
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "GetQueryTestGraphQLHandler",
+			method:     http.MethodGet,
+			target:     "/?query=" + url.QueryEscape("{ __typename }"),
+			statusCode: http.StatusOK,
+			wanted:     "\"__typename\": \"Query\"",
+		},
+		{
+			name:        "PostQueryTestGraphQLHandler",
+			method:      http.MethodPost,
+			target:      "/",
+			body:        `{"query": "query Name { __typename }", "operationName": "Name"}`,
+			contentType: "application/json",
+			statusCode:  http.StatusOK,
+			wanted:      "\"__typename\": \"Query\"",
+		},
+		{
+			name:        "UnknownFieldTestGraphQLHandler",
+			method:      http.MethodPost,
+			target:      "/",
+			body:        `{"query": "{ unknown }"}`,
+			contentType: "application/json",
+			statusCode:  http.StatusOK,
+			wanted:      "Cannot query field \\\"unknown\\\"",
+		},
+		{
+			name:       "NoContentTypeTestGraphQLHandler",
+			method:     http.MethodPost,
+			target:     "/",
+			body:       `{"query": "{ __typename }"}`,
+			statusCode: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Create a mock request
+				req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+				if tt.contentType != "" {
+					req.Header.Set("Content-Type", tt.contentType)
+				}
+
+				// Create a mock response recorder
+				w := httptest.NewRecorder()
+
+				// Call the handler
+				Handler(w, req)
+
+				// Check if the status code matches expected
+				if w.Code != tt.statusCode {
+					log.Println("Testing: ", tt.name)
+					t.Errorf(
+						"handler returned wrong status code: got %v want %v",
+						w.Code, tt.statusCode,
+					)
+				}
+
+				if tt.wanted != "" && !strings.Contains(w.Body.String(), tt.wanted) {
+					t.Errorf("handler returned body %v, want it to contain %v", w.Body.String(), tt.wanted)
+				}
+			},
+		)
+	}
+}
+
+func Test_toExchangeRates(t *testing.T) {
+	rates := toExchangeRates(map[string]float64{"USD": 0.1, "EUR": 0.09})
+	if len(rates) != 2 || rates[0].Code != "EUR" || rates[1].Code != "USD" {
+		t.Errorf("toExchangeRates() = %v, want rates sorted by code", rates)
+	}
+}
+
+func Test_toBaseExchangeRates(t *testing.T) {
+	rates := toBaseExchangeRates(
+		map[string]map[string]float64{
+			"ZWL": {"EUR": 0.0028},
+			"BWP": {"USD": 0.073, "EUR": 0.068},
+		},
+	)
+	if len(rates) != 2 || rates[0].Base != "BWP" || rates[1].Base != "ZWL" || rates[0].Rates[0].Code != "EUR" {
+		t.Errorf("toBaseExchangeRates() = %v, want rates sorted by base and code", rates)
+	}
+}
@@ -0,0 +1,853 @@
+package graphql
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/http/handlers/dashboards"
+	"assignment-2/internal/http/handlers/notifications"
+	"assignment-2/internal/http/handlers/status"
+	"context"
+	"fmt"
+	gql "github.com/graphql-go/graphql"
+	"log"
+	"sort"
+)
+
+// configFeaturesType is the GraphQL type of the features enabled in a registration.
+var configFeaturesType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "ConfigFeatures",
+		Fields: gql.Fields{
+			"temperature":      &gql.Field{Type: gql.Boolean},
+			"precipitation":    &gql.Field{Type: gql.Boolean},
+			"capital":          &gql.Field{Type: gql.Boolean},
+			"coordinates":      &gql.Field{Type: gql.Boolean},
+			"population":       &gql.Field{Type: gql.Boolean},
+			"area":             &gql.Field{Type: gql.Boolean},
+			"targetCurrencies": &gql.Field{Type: gql.NewList(gql.String)},
+			"temperatureMin":   &gql.Field{Type: gql.Boolean},
+			"temperatureMax":   &gql.Field{Type: gql.Boolean},
+			"windSpeed":        &gql.Field{Type: gql.Boolean},
+			"windGusts":        &gql.Field{Type: gql.Boolean},
+			"humidity":         &gql.Field{Type: gql.Boolean},
+			"cloudCover":       &gql.Field{Type: gql.Boolean},
+			"uvIndex":          &gql.Field{Type: gql.Boolean},
+			"forecastDays":     &gql.Field{Type: gql.Int},
+			"weatherLocation":  &gql.Field{Type: weatherLocationType},
+			"primaryCapital":   &gql.Field{Type: gql.String},
+			"primaryCurrency":  &gql.Field{Type: gql.String},
+			"languages":        &gql.Field{Type: gql.Boolean},
+			"borders":          &gql.Field{Type: gql.Boolean},
+			"region":           &gql.Field{Type: gql.Boolean},
+			"flag":             &gql.Field{Type: gql.Boolean},
+			"timezones":        &gql.Field{Type: gql.Boolean},
+			"callingCodes":     &gql.Field{Type: gql.Boolean},
+			"drivingSide":      &gql.Field{Type: gql.Boolean},
+			"gini":             &gql.Field{Type: gql.Boolean},
+			"baseCurrency":     &gql.Field{Type: gql.String},
+		},
+	},
+)
+
+// dashboardConfigType is the GraphQL type of a registration.
+var dashboardConfigType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "DashboardConfig",
+		Fields: gql.Fields{
+			"id":         &gql.Field{Type: gql.NewNonNull(gql.ID)},
+			"country":    &gql.Field{Type: gql.String},
+			"isoCode":    &gql.Field{Type: gql.String},
+			"region":     &gql.Field{Type: gql.String},
+			"subregion":  &gql.Field{Type: gql.String},
+			"isoCodes":   &gql.Field{Type: gql.NewList(gql.String)},
+			"features":   &gql.Field{Type: configFeaturesType},
+			"units":      &gql.Field{Type: unitsType},
+			"precision":  &gql.Field{Type: gql.Int},
+			"computed":   &gql.Field{Type: gql.NewList(computedFeatureType)},
+			"lastChange": &gql.Field{Type: gql.DateTime},
+		},
+	},
+)
+
+// unitsType is the GraphQL type of the unit system of a registration.
+var unitsType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "Units",
+		Fields: gql.Fields{
+			"system":        &gql.Field{Type: gql.String},
+			"temperature":   &gql.Field{Type: gql.String},
+			"precipitation": &gql.Field{Type: gql.String},
+			"windSpeed":     &gql.Field{Type: gql.String},
+			"area":          &gql.Field{Type: gql.String},
+		},
+	},
+)
+
+// computedFeatureType is the GraphQL type of a computed feature of a registration.
+var computedFeatureType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "ComputedFeature",
+		Fields: gql.Fields{
+			"name":       &gql.Field{Type: gql.String},
+			"builtin":    &gql.Field{Type: gql.String},
+			"expression": &gql.Field{Type: gql.String},
+			"currency":   &gql.Field{Type: gql.String},
+			"reference":  &gql.Field{Type: gql.String},
+		},
+	},
+)
+
+// dashboardUnitsType is the GraphQL type of the units the quantities of a dashboard are in.
+var dashboardUnitsType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "DashboardUnits",
+		Fields: gql.Fields{
+			"temperature":   &gql.Field{Type: gql.String},
+			"precipitation": &gql.Field{Type: gql.String},
+			"windSpeed":     &gql.Field{Type: gql.String},
+			"area":          &gql.Field{Type: gql.String},
+		},
+	},
+)
+
+// coordinatesType is the GraphQL type of coordinates.
+var coordinatesType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "Coordinates",
+		Fields: gql.Fields{
+			"latitude":  &gql.Field{Type: gql.Float},
+			"longitude": &gql.Field{Type: gql.Float},
+		},
+	},
+)
+
+// weatherLocationType is the GraphQL type of where the weather of a dashboard is measured.
+var weatherLocationType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "WeatherLocation",
+		Fields: gql.Fields{
+			"source":   &gql.Field{Type: gql.String},
+			"points":   &gql.Field{Type: gql.NewList(coordinatesType)},
+			"separate": &gql.Field{Type: gql.Boolean},
+		},
+	},
+)
+
+// flagType is the GraphQL type of the flag of a country.
+var flagType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "Flag",
+		Fields: gql.Fields{
+			"emoji": &gql.Field{Type: gql.String},
+			"png":   &gql.Field{Type: gql.String},
+			"svg":   &gql.Field{Type: gql.String},
+		},
+	},
+)
+
+// giniType is the GraphQL type of the Gini index of a country.
+var giniType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "Gini",
+		Fields: gql.Fields{
+			"year":  &gql.Field{Type: gql.Int},
+			"value": &gql.Field{Type: gql.Float},
+		},
+	},
+)
+
+// computedValue is the value of a computed feature, as GraphQL has no map type.
+type computedValue struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// computedValueType is the GraphQL type of the value of a computed feature.
+var computedValueType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "ComputedValue",
+		Fields: gql.Fields{
+			"name":  &gql.Field{Type: gql.String},
+			"value": &gql.Field{Type: gql.Float},
+		},
+	},
+)
+
+// regionAggregatesType is the GraphQL type of the aggregates over the countries of a regional dashboard.
+var regionAggregatesType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "RegionAggregates",
+		Fields: gql.Fields{
+			"countries":    &gql.Field{Type: gql.Int},
+			"population":   &gql.Field{Type: gql.Int},
+			"area":         &gql.Field{Type: gql.Float},
+			"temperature":  &gql.Field{Type: gql.Float},
+			"baseCurrency": &gql.Field{Type: gql.String},
+			"exchangeRates": &gql.Field{
+				Type: gql.NewList(exchangeRateType),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					rates := p.Source.(*dashboards.RegionAggregates).ExchangeRates
+					if rates == nil {
+						return nil, nil
+					}
+					return toExchangeRates(rates), nil
+				},
+			},
+		},
+	},
+)
+
+// currencyType is the GraphQL type of a currency.
+var currencyType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "Currency",
+		Fields: gql.Fields{
+			"code":   &gql.Field{Type: gql.String},
+			"name":   &gql.Field{Type: gql.String},
+			"symbol": &gql.Field{Type: gql.String},
+		},
+	},
+)
+
+// exchangeRate is an exchange rate to a target currency, as GraphQL has no map type.
+type exchangeRate struct {
+	Code string  `json:"code"`
+	Rate float64 `json:"rate"`
+}
+
+// baseExchangeRates are the exchange rates from a base currency, as GraphQL has no map type.
+type baseExchangeRates struct {
+	Base  string         `json:"base"`
+	Rates []exchangeRate `json:"rates"`
+}
+
+// exchangeRateType is the GraphQL type of an exchange rate.
+var exchangeRateType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "ExchangeRate",
+		Fields: gql.Fields{
+			"code": &gql.Field{Type: gql.String},
+			"rate": &gql.Field{Type: gql.Float},
+		},
+	},
+)
+
+// baseExchangeRatesType is the GraphQL type of the exchange rates from a base currency.
+var baseExchangeRatesType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "BaseExchangeRates",
+		Fields: gql.Fields{
+			"base":  &gql.Field{Type: gql.String},
+			"rates": &gql.Field{Type: gql.NewList(exchangeRateType)},
+		},
+	},
+)
+
+// forecastDayType is the GraphQL type of a day of the weather forecast.
+var forecastDayType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "ForecastDay",
+		Fields: withWeatherFields(
+			gql.Fields{
+				"date": &gql.Field{
+					Type: gql.String,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return p.Source.(dashboards.ForecastDay).Date, nil
+					},
+				},
+			},
+			func(source interface{}) (dashboards.WeatherFeatures, error) {
+				return source.(dashboards.ForecastDay).WeatherFeatures, nil
+			},
+		),
+	},
+)
+
+// pointWeatherType is the GraphQL type of the weather at one of the points the weather is measured at.
+var pointWeatherType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "PointWeather",
+		Fields: withWeatherFields(
+			gql.Fields{
+				"coordinates": &gql.Field{
+					Type: coordinatesType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return p.Source.(dashboards.PointWeather).Coordinates, nil
+					},
+				},
+				"forecast": &gql.Field{
+					Type: gql.NewList(forecastDayType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						forecast := p.Source.(dashboards.PointWeather).Forecast
+						if forecast == nil {
+							return nil, nil
+						}
+						return forecast, nil
+					},
+				},
+			},
+			func(source interface{}) (dashboards.WeatherFeatures, error) {
+				return source.(dashboards.PointWeather).WeatherFeatures, nil
+			},
+		),
+	},
+)
+
+// dashboardFeaturesType is the GraphQL type of the populated features of a dashboard. Every field is resolved lazily
+// by the dashboard loader, so only the external services supplying requested fields are called.
+var dashboardFeaturesType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "DashboardFeatures",
+		Fields: withWeatherFields(
+			gql.Fields{
+				"forecast": &gql.Field{
+					Type: gql.NewList(forecastDayType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						forecast, err := p.Source.(*dashboards.Loader).Forecast()
+						if err != nil || forecast == nil {
+							return nil, err
+						}
+						return forecast, nil
+					},
+				},
+				"weatherLocation": &gql.Field{
+					Type: weatherLocationType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValue(p.Source.(*dashboards.Loader).WeatherLocation())
+					},
+				},
+				"weatherPoints": &gql.Field{
+					Type: gql.NewList(pointWeatherType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						points, err := p.Source.(*dashboards.Loader).WeatherPoints()
+						if err != nil || points == nil {
+							return nil, err
+						}
+						return points, nil
+					},
+				},
+				"capital": &gql.Field{
+					Type: gql.String,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValue(p.Source.(*dashboards.Loader).Capital())
+					},
+				},
+				"capitals": &gql.Field{
+					Type: gql.NewList(gql.String),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						capitals, err := p.Source.(*dashboards.Loader).Capitals()
+						if err != nil || capitals == nil {
+							return nil, err
+						}
+						return capitals, nil
+					},
+				},
+				"coordinates": &gql.Field{
+					Type: coordinatesType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValue(p.Source.(*dashboards.Loader).Coordinates())
+					},
+				},
+				"population": &gql.Field{
+					Type: gql.Int,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValue(p.Source.(*dashboards.Loader).Population())
+					},
+				},
+				"area": &gql.Field{
+					Type: gql.Float,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValue(p.Source.(*dashboards.Loader).Area())
+					},
+				},
+				"languages": &gql.Field{
+					Type: gql.NewList(gql.String),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValues(p.Source.(*dashboards.Loader).Languages())
+					},
+				},
+				"borders": &gql.Field{
+					Type: gql.NewList(gql.String),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValues(p.Source.(*dashboards.Loader).Borders())
+					},
+				},
+				"region": &gql.Field{
+					Type: gql.String,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValue(p.Source.(*dashboards.Loader).Region())
+					},
+				},
+				"subregion": &gql.Field{
+					Type: gql.String,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValue(p.Source.(*dashboards.Loader).Subregion())
+					},
+				},
+				"flag": &gql.Field{
+					Type: flagType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValue(p.Source.(*dashboards.Loader).Flag())
+					},
+				},
+				"timezones": &gql.Field{
+					Type: gql.NewList(gql.String),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValues(p.Source.(*dashboards.Loader).Timezones())
+					},
+				},
+				"callingCodes": &gql.Field{
+					Type: gql.NewList(gql.String),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValues(p.Source.(*dashboards.Loader).CallingCodes())
+					},
+				},
+				"drivingSide": &gql.Field{
+					Type: gql.String,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValue(p.Source.(*dashboards.Loader).DrivingSide())
+					},
+				},
+				"gini": &gql.Field{
+					Type: giniType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						return nilIfNoValue(p.Source.(*dashboards.Loader).Gini())
+					},
+				},
+				"computed": &gql.Field{
+					Type: gql.NewList(computedValueType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						loader := p.Source.(*dashboards.Loader)
+						values, err := loader.Computed()
+						if err != nil || values == nil {
+							return nil, err
+						}
+						return toComputedValues(loader.Config().Computed, values), nil
+					},
+				},
+				"currency": &gql.Field{
+					Type: currencyType,
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						currency, err := p.Source.(*dashboards.Loader).Currency()
+						if err != nil {
+							return nil, err
+						}
+						return currency, nil
+					},
+				},
+				"currencies": &gql.Field{
+					Type: gql.NewList(currencyType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						currencies, err := p.Source.(*dashboards.Loader).Currencies()
+						if err != nil {
+							return nil, err
+						}
+						return currencies, nil
+					},
+				},
+				"exchangeRates": &gql.Field{
+					Type: gql.NewList(baseExchangeRatesType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						rates, err := p.Source.(*dashboards.Loader).ExchangeRates()
+						if err != nil || rates == nil {
+							return nil, err
+						}
+						return toBaseExchangeRates(rates), nil
+					},
+				},
+				"targetCurrencies": &gql.Field{
+					Type: gql.NewList(exchangeRateType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						rates, err := p.Source.(*dashboards.Loader).TargetCurrencies()
+						if err != nil {
+							return nil, err
+						}
+						return toExchangeRates(rates), nil
+					},
+				},
+				"inverseRates": &gql.Field{
+					Type: gql.NewList(exchangeRateType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						rates, err := p.Source.(*dashboards.Loader).InverseRates()
+						if err != nil {
+							return nil, err
+						}
+						return toExchangeRates(rates), nil
+					},
+				},
+				"crossRates": &gql.Field{
+					Type: gql.NewList(baseExchangeRatesType),
+					Resolve: func(p gql.ResolveParams) (interface{}, error) {
+						rates, err := p.Source.(*dashboards.Loader).CrossRates()
+						if err != nil || rates == nil {
+							return nil, err
+						}
+						return toBaseExchangeRates(rates), nil
+					},
+				},
+			},
+			func(source interface{}) (dashboards.WeatherFeatures, error) {
+				return source.(*dashboards.Loader).Weather()
+			},
+		),
+	},
+)
+
+// withWeatherFields adds the fields of the weather features to the given fields, resolved from the weather features
+// of the source.
+func withWeatherFields(
+	fields gql.Fields,
+	weather func(source interface{}) (dashboards.WeatherFeatures, error),
+) gql.Fields {
+	for name, value := range map[string]func(dashboards.WeatherFeatures) *float64{
+		"temperature":    func(w dashboards.WeatherFeatures) *float64 { return w.Temperature },
+		"precipitation":  func(w dashboards.WeatherFeatures) *float64 { return w.Precipitation },
+		"temperatureMin": func(w dashboards.WeatherFeatures) *float64 { return w.TemperatureMin },
+		"temperatureMax": func(w dashboards.WeatherFeatures) *float64 { return w.TemperatureMax },
+		"windSpeed":      func(w dashboards.WeatherFeatures) *float64 { return w.WindSpeed },
+		"windGusts":      func(w dashboards.WeatherFeatures) *float64 { return w.WindGusts },
+		"humidity":       func(w dashboards.WeatherFeatures) *float64 { return w.Humidity },
+		"cloudCover":     func(w dashboards.WeatherFeatures) *float64 { return w.CloudCover },
+		"uvIndex":        func(w dashboards.WeatherFeatures) *float64 { return w.UvIndex },
+	} {
+		fields[name] = &gql.Field{
+			Type: gql.Float,
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				features, err := weather(p.Source)
+				return nilIfNoValue(value(features), err)
+			},
+		}
+	}
+	return fields
+}
+
+// dashboardType is the GraphQL type of a populated dashboard.
+var dashboardType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "Dashboard",
+		Fields: gql.Fields{
+			"id": &gql.Field{
+				Type: gql.NewNonNull(gql.ID),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*dashboards.Loader).Config().ID, nil
+				},
+			},
+			"country": &gql.Field{
+				Type: gql.String,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*dashboards.Loader).Config().Country, nil
+				},
+			},
+			"isoCode": &gql.Field{
+				Type: gql.String,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*dashboards.Loader).Config().IsoCode, nil
+				},
+			},
+			"features": &gql.Field{
+				Type: dashboardFeaturesType,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					// A regional dashboard has the features of its countries instead
+					if p.Source.(*dashboards.Loader).Config().IsRegional() {
+						return nil, nil
+					}
+					return p.Source, nil
+				},
+			},
+			"lastRetrieval": &gql.Field{
+				Type: gql.DateTime,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*dashboards.Loader).LastRetrieval(), nil
+				},
+			},
+			"timezone": &gql.Field{
+				Type: gql.String,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					timezone, _ := p.Source.(*dashboards.Loader).LocalRetrieval()
+					if timezone == "" {
+						return nil, nil
+					}
+					return timezone, nil
+				},
+			},
+			"localRetrieval": &gql.Field{
+				Type: gql.DateTime,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					_, localRetrieval := p.Source.(*dashboards.Loader).LocalRetrieval()
+					if localRetrieval == nil {
+						return nil, nil
+					}
+					return *localRetrieval, nil
+				},
+			},
+			"units": &gql.Field{
+				Type: dashboardUnitsType,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*dashboards.Loader).Units(), nil
+				},
+			},
+			"registration": &gql.Field{
+				Type: dashboardConfigType,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*dashboards.Loader).Config(), nil
+				},
+			},
+			"aggregates": &gql.Field{
+				Type: regionAggregatesType,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					aggregates, err := p.Source.(*dashboards.Loader).Aggregates()
+					if err != nil || aggregates == nil {
+						return nil, err
+					}
+					return aggregates, nil
+				},
+			},
+		},
+	},
+)
+
+// notificationType is the GraphQL type of a registered webhook.
+var notificationType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "Notification",
+		Fields: gql.Fields{
+			"id":      &gql.Field{Type: gql.NewNonNull(gql.ID)},
+			"url":     &gql.Field{Type: gql.String},
+			"country": &gql.Field{Type: gql.String},
+			"event":   &gql.Field{Type: gql.String},
+			"lastInvoke": &gql.Field{
+				Type: gql.DateTime,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					lastInvoke := p.Source.(requests.Notification).LastInvoke
+					if lastInvoke == nil {
+						return nil, nil
+					}
+					return *lastInvoke, nil
+				},
+			},
+		},
+	},
+)
+
+// statusType is the GraphQL type of the status of the server and the APIs it relies on.
+var statusType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "Status",
+		Fields: gql.Fields{
+			"countriesApi":   statusField(gql.Int, func(s status.Status) interface{} { return s.CountriesAPI }),
+			"meteoApi":       statusField(gql.Int, func(s status.Status) interface{} { return s.MeteoAPI }),
+			"currencyApi":    statusField(gql.Int, func(s status.Status) interface{} { return s.CurrencyAPI }),
+			"dashboardDb":    statusField(gql.Int, func(s status.Status) interface{} { return s.DashboardDB }),
+			"notificationDb": statusField(gql.Int, func(s status.Status) interface{} { return s.NotificationDB }),
+			"dashboards":     statusField(gql.Int, func(s status.Status) interface{} { return s.Dashboards }),
+			"webhooks":       statusField(gql.Int, func(s status.Status) interface{} { return s.Webhooks }),
+			"version":        statusField(gql.String, func(s status.Status) interface{} { return s.Version }),
+			"uptime":         statusField(gql.Int, func(s status.Status) interface{} { return s.Uptime }),
+		},
+	},
+)
+
+// idArgument is the argument for queries of a single document.
+var idArgument = gql.FieldConfigArgument{
+	"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+}
+
+// queryType is the root query of the schema.
+var queryType = gql.NewObject(
+	gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"registrations": &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(dashboardConfigType))),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return getAllDocuments[requests.DashboardConfig](db.DashboardCollection)
+				},
+			},
+			"registration": &gql.Field{
+				Type: dashboardConfigType,
+				Args: idArgument,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return getDocument[requests.DashboardConfig](p.Args["id"].(string), db.DashboardCollection)
+				},
+			},
+			"dashboard": &gql.Field{
+				Type: dashboardType,
+				Args: idArgument,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return getDashboardLoader(p.Context, p.Args["id"].(string))
+				},
+			},
+			"dashboards": &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(dashboardType)),
+				Args: gql.FieldConfigArgument{
+					"ids": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.ID)))},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					var loaders []interface{}
+					for _, id := range p.Args["ids"].([]interface{}) {
+						loader, err := getDashboardLoader(p.Context, id.(string))
+						if err != nil {
+							return nil, err
+						}
+						loaders = append(loaders, loader)
+					}
+					return loaders, nil
+				},
+			},
+			"notifications": &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(notificationType))),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return getAllDocuments[requests.Notification](db.NotificationCollection)
+				},
+			},
+			"notification": &gql.Field{
+				Type: notificationType,
+				Args: idArgument,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return getDocument[requests.Notification](p.Args["id"].(string), db.NotificationCollection)
+				},
+			},
+			"status": &gql.Field{
+				Type: gql.NewNonNull(statusType),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					currentStatus, err := status.GetStatus()
+					if err != nil {
+						log.Println(constants.ErrDBCount, err.Error())
+						return nil, fmt.Errorf(constants.ErrDBCount)
+					}
+					return currentStatus, nil
+				},
+			},
+		},
+	},
+)
+
+// schema is the GraphQL schema of the service.
+var (
+	schema    gql.Schema
+	schemaErr error
+)
+
+// init builds the schema, after adding the members of regional dashboards to the dashboard type, as they are
+// dashboards themselves.
+func init() {
+	dashboardType.AddFieldConfig(
+		"members", &gql.Field{
+			Type: gql.NewList(dashboardType),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return nilIfNoValues(p.Source.(*dashboards.Loader).Members())
+			},
+		},
+	)
+	schema, schemaErr = gql.NewSchema(gql.SchemaConfig{Query: queryType})
+}
+
+// statusField creates a field of the status type, reading the value with the given function.
+func statusField(fieldType gql.Output, value func(status.Status) interface{}) *gql.Field {
+	return &gql.Field{
+		Type: fieldType,
+		Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return value(p.Source.(status.Status)), nil
+		},
+	}
+}
+
+// getDashboardLoader gets the registration with the given ID, and returns a loader for its dashboard.
+// As with the dashboards endpoint, retrieving a dashboard triggers the INVOKE event.
+func getDashboardLoader(ctx context.Context, id string) (interface{}, error) {
+	config, err := getDocument[requests.DashboardConfig](id, db.DashboardCollection)
+	if err != nil || config == nil {
+		return nil, err
+	}
+	dashboardConfig := config.(requests.DashboardConfig)
+
+	err = notifications.TriggerEvent(requests.EventInvoke, dashboardConfig.IsoCode, dashboardConfig.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dashboards.NewLoader(ctx, dashboardConfig), nil
+}
+
+// getDocument gets the document with the given ID, returning nil without an error if it is not found.
+func getDocument[T any](id string, collection string) (interface{}, error) {
+	document, err := db.GetDocument[T](id, collection)
+	if err != nil {
+		if err.Error() == constants.ErrDBDocNotFound {
+			return nil, nil
+		}
+		log.Println(constants.ErrDBGetDoc + err.Error())
+		return nil, fmt.Errorf(constants.ErrDBGetDoc)
+	}
+	return document, nil
+}
+
+// getAllDocuments gets all documents in the collection, as an empty list if there are none.
+func getAllDocuments[T any](collection string) (interface{}, error) {
+	documents, err := db.GetAllDocuments[T](collection)
+	if err != nil {
+		log.Println(constants.ErrDBGetDoc + err.Error())
+		return nil, fmt.Errorf(constants.ErrDBGetDoc)
+	}
+	if documents == nil {
+		return []T{}, nil
+	}
+	return documents, nil
+}
+
+// nilIfNoValue returns a nil interface for nil pointers, so features that are not enabled resolve to null.
+func nilIfNoValue[T any](value *T, err error) (interface{}, error) {
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return *value, nil
+}
+
+// nilIfNoValues returns nil for a list without values, so disabled features are null instead of empty lists.
+func nilIfNoValues[T any](values []T, err error) (interface{}, error) {
+	if err != nil || values == nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// toExchangeRates converts a map of exchange rates to a list sorted by currency code.
+func toExchangeRates(rates map[string]float64) []exchangeRate {
+	exchangeRates := make([]exchangeRate, 0, len(rates))
+	for code, rate := range rates {
+		exchangeRates = append(exchangeRates, exchangeRate{Code: code, Rate: rate})
+	}
+	sort.Slice(
+		exchangeRates, func(i, j int) bool {
+			return exchangeRates[i].Code < exchangeRates[j].Code
+		},
+	)
+	return exchangeRates
+}
+
+// toComputedValues converts a map of the values of computed features to a list in the order of the given computed
+// features, leaving out those without a value.
+func toComputedValues(computed []requests.ComputedFeature, values map[string]float64) []computedValue {
+	computedValues := make([]computedValue, 0, len(values))
+	for _, feature := range computed {
+		if value, ok := values[feature.Name]; ok {
+			computedValues = append(computedValues, computedValue{Name: feature.Name, Value: value})
+		}
+	}
+	return computedValues
+}
+
+// toBaseExchangeRates converts a map of exchange rates by base currency to a list sorted by base currency code.
+func toBaseExchangeRates(rates map[string]map[string]float64) []baseExchangeRates {
+	exchangeRates := make([]baseExchangeRates, 0, len(rates))
+	for base, baseRates := range rates {
+		exchangeRates = append(exchangeRates, baseExchangeRates{Base: base, Rates: toExchangeRates(baseRates)})
+	}
+	sort.Slice(
+		exchangeRates, func(i, j int) bool {
+			return exchangeRates[i].Base < exchangeRates[j].Base
+		},
+	)
+	return exchangeRates
+}
@@ -90,23 +90,21 @@ func handleNotificationsGetRequest(w http.ResponseWriter, r *http.Request) {
 func handleNotificationsPostRequest(w http.ResponseWriter, r *http.Request) {
 	var content requests.Notification
 
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&content); err != nil {
+	if err := utils.DecodeJSONBody(w, r, &content); err != nil {
 		log.Println(constants.ErrJsonDecode + err.Error())
-	}
-
-	// Checks if event in body is isValid
-	if isValidEvent(content.Event) == false {
-		http.Error(w, constants.ErrNotificationsInvalidType, http.StatusBadRequest)
+		http.Error(w, err.Message, err.Status)
 		return
 	}
 
-	content.ID = utils.GenerateRandomID()
-
-	// Save the Notification to the database
-	err2 := db.AddDocument[requests.Notification](content, db.NotificationCollection)
+	// Validate and save the Notification
+	content, err2 := CreateNotification(content)
 	if err2 != nil {
-		http.Error(w, constants.ErrDBAddDoc, http.StatusInternalServerError)
+		if err2.Error() == constants.ErrNotificationsInvalidType {
+			http.Error(w, err2.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err2.Error(), http.StatusInternalServerError)
+		}
+		return
 	}
 
 	// Return the ID of the saved Notification
@@ -6,9 +6,11 @@ import (
 	"assignment-2/internal/mock"
 	"bytes"
 	"encoding/json"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -20,6 +22,13 @@ var testNotification = requests.Notification{
 
 var jsonTestNotification, _ = json.Marshal(testNotification)
 
+// newJSONRequest creates a mock request with the content type application/json.
+func newJSONRequest(method string, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
 func setupDB() {
 	mockNotifications := []requests.Notification{
 		{Url: "testURL.com", Event: "REGISTER", Country: "NO"},
@@ -145,7 +154,7 @@ func Test_handleNotificationsPostRequest(t *testing.T) {
 			name: "PostValidRequest",
 			args: args{
 				w: httptest.NewRecorder(),
-				r: httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(jsonTestNotification)),
+				r: newJSONRequest(http.MethodPost, "/", bytes.NewBuffer(jsonTestNotification)),
 			},
 			wantedStatus: http.StatusCreated,
 		},
@@ -153,10 +162,26 @@ func Test_handleNotificationsPostRequest(t *testing.T) {
 			name: "PostInvalidRequest",
 			args: args{
 				w: httptest.NewRecorder(),
-				r: httptest.NewRequest(http.MethodPost, "/", nil),
+				r: newJSONRequest(http.MethodPost, "/", nil),
 			},
 			wantedStatus: http.StatusBadRequest,
 		},
+		{
+			name: "PostUnknownFieldRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(http.MethodPost, "/", strings.NewReader(`{"event":"REGISTER","unknown":true}`)),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostWithoutContentTypeRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(jsonTestNotification)),
+			},
+			wantedStatus: http.StatusUnsupportedMediaType,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(
@@ -177,11 +202,11 @@ func Test_handleNotificationsPostRequest(t *testing.T) {
 							tt.args.w.(*httptest.ResponseRecorder).Body.String(),
 						)
 					}
-				case http.StatusBadRequest:
-					if tt.args.w.(*httptest.ResponseRecorder).Code != http.StatusBadRequest {
+				case http.StatusBadRequest, http.StatusUnsupportedMediaType:
+					if tt.args.w.(*httptest.ResponseRecorder).Code != tt.wantedStatus {
 						t.Errorf(
 							"handleNotificationsPostRequest() = %v, want %v",
-							tt.args.w.(*httptest.ResponseRecorder).Code, http.StatusBadRequest,
+							tt.args.w.(*httptest.ResponseRecorder).Code, tt.wantedStatus,
 						)
 					}
 				}
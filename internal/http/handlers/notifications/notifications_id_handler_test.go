@@ -160,7 +160,7 @@ func getValidID() string {
 	// POST request to create a new registration, record the ID from the response
 
 	w := httptest.NewRecorder()
-	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(jsonTestNotification))
+	r := newJSONRequest(http.MethodPost, "/", bytes.NewBuffer(jsonTestNotification))
 
 	handleNotificationsPostRequest(w, r)
 
@@ -5,6 +5,7 @@ import (
 	"assignment-2/internal/db"
 	"assignment-2/internal/http/datatransfers/inhouse"
 	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/http/handlers/events"
 	"assignment-2/internal/utils"
 	"bytes"
 	"encoding/json"
@@ -23,6 +24,47 @@ func GetEndpointStructs() []inhouse.Endpoint {
 	return []inhouse.Endpoint{notificationsEndpointWithoutID, notificationsEndpointWithID}
 }
 
+// CreateNotification validates the webhook, and saves it to the database with a new ID.
+// Returns the saved webhook. The returned error message is safe to show to the client.
+func CreateNotification(notification requests.Notification) (requests.Notification, error) {
+	// Checks if event in body is isValid
+	if !isValidEvent(notification.Event) {
+		return requests.Notification{}, fmt.Errorf(constants.ErrNotificationsInvalidType)
+	}
+
+	notification.ID = utils.GenerateRandomID()
+
+	// Save the Notification to the database
+	err := db.AddDocument[requests.Notification](notification, db.NotificationCollection)
+	if err != nil {
+		log.Println(constants.ErrDBAddDoc + err.Error())
+		return requests.Notification{}, fmt.Errorf(constants.ErrDBAddDoc)
+	}
+
+	return notification, nil
+}
+
+// TriggerEvent publishes the event for the registration to the event stream, and invokes the webhooks registered for
+// the event and country. The returned error message is safe to show to the client.
+func TriggerEvent(event string, country string, registrationID string) error {
+	// Publish the event to the event stream
+	events.Publish(event, country, registrationID)
+
+	// Check if any notifications are registered for the event
+	foundNotifications, err := FindNotificationsByCountry(event, country)
+	if err != nil {
+		log.Println(constants.ErrNotificationsGetDocFromDB, err.Error())
+		return fmt.Errorf(constants.ErrNotificationsGetDocFromDB)
+	}
+
+	// If found, invoke the notifications
+	for _, n := range foundNotifications {
+		InvokeNotification(n)
+	}
+
+	return nil
+}
+
 /*
 FindNotifications returns all notifications for a specific event without any other conditions.
 */
@@ -5,7 +5,6 @@ import (
 	"assignment-2/internal/db"
 	"assignment-2/internal/http/datatransfers/inhouse"
 	"assignment-2/internal/http/datatransfers/requests"
-	"assignment-2/internal/http/handlers/notifications"
 	"assignment-2/internal/utils"
 	"encoding/json"
 	"fmt"
@@ -151,41 +150,19 @@ func handleRegistrationsPostRequest(w http.ResponseWriter, r *http.Request) {
 
 	var content requests.DashboardConfig
 
-	decoder := json.NewDecoder(r.Body)
-	err := decoder.Decode(&content)
-	if err != nil {
+	if err := utils.DecodeJSONBody(w, r, &content); err != nil {
 		log.Println(constants.ErrJsonDecode + err.Error())
-		http.Error(w, constants.ErrJsonDecode, http.StatusBadRequest)
+		http.Error(w, err.Message, err.Status)
 		return
 	}
 
-	content.LastChange = time.Now()
-	content.ID = utils.GenerateRandomID()
-
-	// Save the DashboardConfig to the database
-	err2 := db.AddDocument[requests.DashboardConfig](content, db.DashboardCollection)
+	// Save the registration and trigger the REGISTER event
+	content, err2 := CreateRegistration(content)
 	if err2 != nil {
-		http.Error(w, constants.ErrDBAddDoc, http.StatusInternalServerError)
-	}
-
-	// Check if any notifications are registered for the event
-	foundNotifications, err3 := notifications.FindNotificationsByCountry(
-		requests.EventRegister,
-		content.IsoCode,
-	)
-	if err3 != nil {
-		log.Println(constants.ErrNotificationsGetDocFromDB, err3.Error())
-		http.Error(w, constants.ErrNotificationsGetDocFromDB, http.StatusInternalServerError)
+		http.Error(w, err2.Error(), registrationErrorStatus(err2))
 		return
 	}
 
-	// If found, invoke the notifications
-	if len(foundNotifications) > 0 {
-		for _, n := range foundNotifications {
-			notifications.InvokeNotification(n)
-		}
-	}
-
 	marshaled, err4 := json.MarshalIndent(
 		registrationResponse{ID: content.ID, LastChange: content.LastChange},
 		"",
@@ -3,11 +3,14 @@ package registrations
 import (
 	"assignment-2/internal/http/datatransfers/requests"
 	"assignment-2/internal/mock"
+	"assignment-2/internal/utils"
 	"bytes"
 	"encoding/json"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -27,6 +30,13 @@ var testRegistration = requests.DashboardConfig{
 
 var jsonTestRegistration, _ = json.Marshal(testRegistration)
 
+// newJSONRequest creates a mock request with the content type application/json.
+func newJSONRequest(method string, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
 func TestMain(m *testing.M) {
 	// Setup function
 	log.Println("Setup for testing registrations")
@@ -172,7 +182,7 @@ func Test_handleRegistrationsPostRequest(t *testing.T) {
 			name: "PostValidRequest",
 			args: args{
 				w: httptest.NewRecorder(),
-				r: httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(jsonTestRegistration)),
+				r: newJSONRequest(http.MethodPost, "/", bytes.NewBuffer(jsonTestRegistration)),
 			},
 			wantedStatus: http.StatusCreated,
 		},
@@ -180,10 +190,150 @@ func Test_handleRegistrationsPostRequest(t *testing.T) {
 			name: "PostInvalidRequest",
 			args: args{
 				w: httptest.NewRecorder(),
-				r: httptest.NewRequest(http.MethodPost, "/", nil),
+				r: newJSONRequest(http.MethodPost, "/", nil),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostUnknownFieldRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(http.MethodPost, "/", strings.NewReader(`{"country":"Norway","unknown":true}`)),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostTrailingDataRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(http.MethodPost, "/", strings.NewReader(`{"country":"Norway"}{"country":"Sweden"}`)),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostInvalidForecastDaysRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(
+					http.MethodPost,
+					"/",
+					strings.NewReader(`{"country":"Norway","features":{"temperature":true,"forecastDays":17}}`),
+				),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostInvalidWeatherSourceRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(
+					http.MethodPost,
+					"/",
+					strings.NewReader(`{"country":"Norway","features":{"weatherLocation":{"source":"coast"}}}`),
+				),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostSeveralTargetsRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(
+					http.MethodPost,
+					"/",
+					strings.NewReader(`{"country":"Nordics","isoCode":"NO","isoCodes":["NO","SE"]}`),
+				),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostInvalidUnitsRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(
+					http.MethodPost,
+					"/",
+					strings.NewReader(`{"country":"Norway","units":{"system":"imperial","temperature":"kelvin"}}`),
+				),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostInvalidPrecisionRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(
+					http.MethodPost,
+					"/",
+					strings.NewReader(`{"country":"Norway","precision":7}`),
+				),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostInvalidComputedRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(
+					http.MethodPost,
+					"/",
+					strings.NewReader(`{"country":"Norway","computed":[{"name":"ratio","expression":"population / gdp"}]}`),
+				),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostComputedRegionalRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(
+					http.MethodPost,
+					"/",
+					strings.NewReader(`{"country":"Europe","region":"Europe","computed":[{"name":"density","builtin":"density"}]}`),
+				),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostInvalidWeatherPointsRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(
+					http.MethodPost,
+					"/",
+					strings.NewReader(`{"country":"Norway","features":{"weatherLocation":{"source":"points","points":[{"latitude":91,"longitude":0}]}}}`),
+				),
+			},
+			wantedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PostWrongTypeRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(http.MethodPost, "/", strings.NewReader(`{"country":1}`)),
 			},
 			wantedStatus: http.StatusBadRequest,
 		},
+		{
+			name: "PostTooLargeRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: newJSONRequest(
+					http.MethodPost,
+					"/",
+					strings.NewReader(`{"country":"`+strings.Repeat("a", utils.MaxBodyBytes)+`"}`),
+				),
+			},
+			wantedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "PostWithoutContentTypeRequest",
+			args: args{
+				w: httptest.NewRecorder(),
+				r: httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(jsonTestRegistration)),
+			},
+			wantedStatus: http.StatusUnsupportedMediaType,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(
@@ -204,11 +354,11 @@ func Test_handleRegistrationsPostRequest(t *testing.T) {
 							tt.args.w.(*httptest.ResponseRecorder).Body.String(),
 						)
 					}
-				case http.StatusBadRequest:
-					if tt.args.w.(*httptest.ResponseRecorder).Code != http.StatusBadRequest {
+				case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType:
+					if tt.args.w.(*httptest.ResponseRecorder).Code != tt.wantedStatus {
 						t.Errorf(
 							"handleRegistrationsPostRequest() = %v, want %v",
-							tt.args.w.(*httptest.ResponseRecorder).Code, http.StatusBadRequest,
+							tt.args.w.(*httptest.ResponseRecorder).Code, tt.wantedStatus,
 						)
 					}
 				}
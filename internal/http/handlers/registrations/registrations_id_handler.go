@@ -5,13 +5,11 @@ import (
 	"assignment-2/internal/db"
 	"assignment-2/internal/http/datatransfers/inhouse"
 	"assignment-2/internal/http/datatransfers/requests"
-	"assignment-2/internal/http/handlers/notifications"
 	"assignment-2/internal/utils"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-	"time"
 )
 
 // Implemented methods for the endpoint with ID
@@ -112,42 +110,19 @@ func handleRegistrationsPutRequestWithID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&update); err != nil {
+	if err := utils.DecodeJSONBody(w, r, &update); err != nil {
 		log.Println(constants.ErrJsonDecode + err.Error())
-		http.Error(w, constants.ErrJsonDecode, http.StatusBadRequest)
+		http.Error(w, err.Message, err.Status)
 		return
 	}
 
-	update.ID = id
-	update.LastChange = time.Now()
-
-	err3 := db.UpdateDocument[requests.DashboardConfig](
-		update, id,
-		db.DashboardCollection,
-	)
-	if err3 != nil {
-		http.Error(w, err3.Error(), http.StatusInternalServerError)
-	}
-
-	// Check if any notifications are registered for the event
-	foundNotifications, err4 := notifications.FindNotificationsByCountry(
-		requests.EventChange,
-		update.IsoCode,
-	)
-	if err4 != nil {
-		log.Println(constants.ErrNotificationsGetDocFromDB, err4.Error())
-		http.Error(w, constants.ErrNotificationsGetDocFromDB, http.StatusInternalServerError)
+	// Save the registration and trigger the CHANGE event
+	_, err2 := UpdateRegistration(id, update)
+	if err2 != nil {
+		http.Error(w, err2.Error(), registrationErrorStatus(err2))
 		return
 	}
 
-	// If found, invoke the notifications
-	if len(foundNotifications) > 0 {
-		for _, n := range foundNotifications {
-			notifications.InvokeNotification(n)
-		}
-	}
-
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -158,51 +133,19 @@ func handleRegistrationsDeleteRequestWithID(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Get the registration with the provided ID
-	dashboard, err3 := db.GetDocument[requests.DashboardConfig](
-		id,
-		db.DashboardCollection,
-	)
-	if err3 != nil {
-		switch err3.Error() {
+	// Delete the registration and trigger the DELETE event
+	err2 := DeleteRegistration(id)
+	if err2 != nil {
+		switch err2.Error() {
 		case constants.ErrIDInvalid:
 			http.Error(w, constants.ErrIDInvalid, http.StatusBadRequest)
 		case constants.ErrDBDocNotFound:
 			http.Error(w, constants.ErrDBDocNotFound, http.StatusNoContent)
 		default:
-			http.Error(
-				w,
-				constants.ErrDBGetDoc,
-				http.StatusInternalServerError,
-			)
+			http.Error(w, err2.Error(), http.StatusInternalServerError)
 		}
-		log.Println(constants.ErrDBGetDoc + err3.Error())
-		return
-	}
-
-	err2 := db.DeleteDocument(id, db.DashboardCollection)
-	if err2 != nil {
-		http.Error(w, err2.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Check if any notifications are registered for the event
-	foundNotifications, err4 := notifications.FindNotificationsByCountry(
-		requests.EventDelete,
-		dashboard.IsoCode,
-	)
-	if err4 != nil {
-		log.Println(constants.ErrNotificationsGetDocFromDB, err4.Error())
-		http.Error(w, constants.ErrNotificationsGetDocFromDB, http.StatusInternalServerError)
-		return
-	}
-
-	// If found, invoke the notifications
-	if len(foundNotifications) > 0 {
-		for _, n := range foundNotifications {
-			notifications.InvokeNotification(n)
-		}
-	}
-
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -172,7 +172,7 @@ func Test_handleRegistrationsPutRequestWithID(t *testing.T) {
 			name: "PositivePutRequestWithID",
 			args: args{
 				w: httptest.NewRecorder(),
-				r: httptest.NewRequest(
+				r: newJSONRequest(
 					http.MethodPut,
 					constants.RegistrationsPath+"?id="+getValidID(),
 					bytes.NewBuffer(jsonTestRegistration),
@@ -184,7 +184,7 @@ func Test_handleRegistrationsPutRequestWithID(t *testing.T) {
 			name: "NegativePutRequestWithID",
 			args: args{
 				w: httptest.NewRecorder(),
-				r: httptest.NewRequest(
+				r: newJSONRequest(
 					http.MethodPut,
 					constants.RegistrationsPath+"?id=invalidID",
 					bytes.NewBuffer(jsonTestRegistration),
@@ -196,7 +196,7 @@ func Test_handleRegistrationsPutRequestWithID(t *testing.T) {
 			name: "NegativePutRequestWithBadBody",
 			args: args{
 				w: httptest.NewRecorder(),
-				r: httptest.NewRequest(
+				r: newJSONRequest(
 					http.MethodPut,
 					constants.RegistrationsPath+"?id="+getValidID(),
 					bytes.NewBuffer([]byte("invalid json")),
@@ -225,7 +225,7 @@ func getValidID() string {
 	// POST request to create a new registration, record the ID from the response
 
 	w := httptest.NewRecorder()
-	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(jsonTestRegistration))
+	r := newJSONRequest(http.MethodPost, "/", bytes.NewBuffer(jsonTestRegistration))
 
 	handleRegistrationsPostRequest(w, r)
 
@@ -1,7 +1,19 @@
 package registrations
 
 import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/expression"
 	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/http/handlers/notifications"
+	"assignment-2/internal/utils"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -14,3 +26,286 @@ type registrationResponse struct {
 func GetEndpointStructs() []inhouse.Endpoint {
 	return []inhouse.Endpoint{registrationsEndpointWithoutID, registrationsEndpointWithID}
 }
+
+// CreateRegistration saves a new dashboard configuration with a new ID, and triggers the REGISTER event.
+// Returns the saved configuration. The returned error message is safe to show to the client.
+func CreateRegistration(config requests.DashboardConfig) (requests.DashboardConfig, error) {
+	err := validateRegistration(config)
+	if err != nil {
+		return requests.DashboardConfig{}, err
+	}
+
+	config.LastChange = time.Now()
+	config.ID = utils.GenerateRandomID()
+
+	// Save the DashboardConfig to the database
+	err = db.AddDocument[requests.DashboardConfig](config, db.DashboardCollection)
+	if err != nil {
+		log.Println(constants.ErrDBAddDoc + err.Error())
+		return requests.DashboardConfig{}, fmt.Errorf(constants.ErrDBAddDoc)
+	}
+
+	// The registration is saved, so failing to invoke the webhooks does not fail the request
+	err = notifications.TriggerEvent(requests.EventRegister, config.IsoCode, config.ID)
+	if err != nil {
+		log.Println(constants.ErrNotificationsTrigger + err.Error())
+	}
+
+	return config, nil
+}
+
+// UpdateRegistration replaces the dashboard configuration with the given ID, and triggers the CHANGE event.
+// Returns the saved configuration. The returned error message is safe to show to the client.
+func UpdateRegistration(id string, update requests.DashboardConfig) (requests.DashboardConfig, error) {
+	err := validateRegistration(update)
+	if err != nil {
+		return requests.DashboardConfig{}, err
+	}
+
+	// Check that the registration exists, as updating a missing document is not an error in the database
+	_, err = db.GetDocument[requests.DashboardConfig](id, db.DashboardCollection)
+	if err != nil {
+		log.Println(constants.ErrDBGetDoc + err.Error())
+		return requests.DashboardConfig{}, dbError(err)
+	}
+
+	update.ID = id
+	update.LastChange = time.Now()
+
+	err = db.UpdateDocument[requests.DashboardConfig](
+		update, id,
+		db.DashboardCollection,
+	)
+	if err != nil {
+		log.Println(constants.ErrDBUpdateDoc + err.Error())
+		return requests.DashboardConfig{}, fmt.Errorf(constants.ErrDBUpdateDoc)
+	}
+
+	// The registration is saved, so failing to invoke the webhooks does not fail the request
+	err = notifications.TriggerEvent(requests.EventChange, update.IsoCode, update.ID)
+	if err != nil {
+		log.Println(constants.ErrNotificationsTrigger + err.Error())
+	}
+
+	return update, nil
+}
+
+// DeleteRegistration deletes the dashboard configuration with the given ID and its snapshots, and triggers the DELETE
+// event.
+// The returned error message is safe to show to the client.
+func DeleteRegistration(id string) error {
+	// Get the registration with the provided ID
+	dashboard, err := db.GetDocument[requests.DashboardConfig](
+		id,
+		db.DashboardCollection,
+	)
+	if err != nil {
+		log.Println(constants.ErrDBGetDoc + err.Error())
+		return dbError(err)
+	}
+
+	// The snapshots are not deleted with the registration, and cannot be found once it is gone
+	err = db.DeleteSubCollection(id, db.DashboardCollection, db.SnapshotCollection)
+	if err != nil {
+		log.Println(constants.ErrDBDeleteDoc + err.Error())
+		return fmt.Errorf(constants.ErrDBDeleteDoc)
+	}
+
+	err = db.DeleteDocument(id, db.DashboardCollection)
+	if err != nil {
+		log.Println(constants.ErrDBDeleteDoc + err.Error())
+		return fmt.Errorf(constants.ErrDBDeleteDoc)
+	}
+
+	// The registration is deleted, so failing to invoke the webhooks does not fail the request
+	err = notifications.TriggerEvent(requests.EventDelete, dashboard.IsoCode, dashboard.ID)
+	if err != nil {
+		log.Println(constants.ErrNotificationsTrigger + err.Error())
+	}
+
+	return nil
+}
+
+// validateRegistration checks that the dashboard configuration can be saved. The returned error message is safe to
+// show to the client.
+func validateRegistration(config requests.DashboardConfig) error {
+	// A registration targets a single country, or the countries of a region, of a subregion or with the ISO codes
+	targets := 0
+	for _, target := range []bool{
+		config.IsoCode != "", config.Region != "", config.Subregion != "", len(config.IsoCodes) > 0,
+	} {
+		if target {
+			targets++
+		}
+	}
+	if targets > 1 {
+		return fmt.Errorf(constants.ErrRegistrationTarget)
+	}
+	if len(config.IsoCodes) > constants.MaxRegionCountries {
+		return fmt.Errorf(constants.ErrRegistrationIsoCodes)
+	}
+
+	if config.Features.ForecastDays < 0 || config.Features.ForecastDays > constants.MaxForecastDays {
+		return fmt.Errorf(constants.ErrRegistrationForecastDays)
+	}
+
+	location := config.Features.WeatherLocation
+	if location.Source != "" && !slices.Contains(requests.ImplementedWeatherSources, location.Source) {
+		return fmt.Errorf(constants.ErrRegistrationWeatherSource)
+	}
+	if location.Source == requests.WeatherAtPoints {
+		if len(location.Points) == 0 || len(location.Points) > constants.MaxWeatherPoints {
+			return fmt.Errorf(constants.ErrRegistrationWeatherPoints)
+		}
+		for _, point := range location.Points {
+			if math.Abs(point.Latitude) > 90 || math.Abs(point.Longitude) > 180 {
+				return fmt.Errorf(constants.ErrRegistrationWeatherPoints)
+			}
+		}
+	} else if len(location.Points) > 0 {
+		return fmt.Errorf(constants.ErrRegistrationWeatherPoints)
+	}
+
+	if !validUnits(config.Units) {
+		return fmt.Errorf(constants.ErrRegistrationUnits)
+	}
+	if config.Precision != nil && (*config.Precision < 0 || *config.Precision > constants.MaxPrecision) {
+		return fmt.Errorf(constants.ErrRegistrationPrecision)
+	}
+
+	return validateComputed(config)
+}
+
+// validateComputed checks the computed features of the dashboard configuration. Each has a unique name, and either a
+// builtin, where rateChange has one of the target currencies, or an expression over known variables, which may have
+// a reference country. The returned error message is safe to show to the client.
+func validateComputed(config requests.DashboardConfig) error {
+	if len(config.Computed) == 0 {
+		return nil
+	}
+	if config.IsRegional() {
+		return fmt.Errorf(constants.ErrRegistrationComputedRegional)
+	}
+	if len(config.Computed) > constants.MaxComputedFeatures {
+		return fmt.Errorf(constants.ErrRegistrationComputed)
+	}
+
+	var names []string
+	for _, computed := range config.Computed {
+		if !validComputedName(computed.Name) || slices.Contains(names, computed.Name) {
+			return fmt.Errorf(constants.ErrRegistrationComputed)
+		}
+
+		switch {
+		case computed.Builtin != "" && computed.Expression == "" && computed.Reference == "":
+			if !slices.Contains(requests.ImplementedComputedBuiltins, computed.Builtin) {
+				return fmt.Errorf(constants.ErrRegistrationComputed)
+			}
+			hasCurrency := slices.Contains(config.Features.TargetCurrencies, computed.Currency)
+			if (computed.Builtin == requests.ComputedRateChange) != hasCurrency {
+				return fmt.Errorf(constants.ErrRegistrationComputed)
+			}
+		case computed.Expression != "" && computed.Builtin == "" && computed.Currency == "":
+			if computed.Reference != "" && !utils.IsIsoCode(strings.ToUpper(computed.Reference)) {
+				return fmt.Errorf(constants.ErrRegistrationComputed)
+			}
+			parsed, err := expression.Parse(computed.Expression)
+			if err != nil {
+				log.Println(constants.ErrRegistrationComputed + ": " + err.Error())
+				return fmt.Errorf(constants.ErrRegistrationComputed)
+			}
+			for _, variable := range parsed.Variables() {
+				if !validComputedVariable(variable, config, names, computed.Reference != "") {
+					log.Println(constants.ErrRegistrationComputed + ": unknown variable " + variable)
+					return fmt.Errorf(constants.ErrRegistrationComputed)
+				}
+			}
+		default:
+			return fmt.Errorf(constants.ErrRegistrationComputed)
+		}
+		names = append(names, computed.Name)
+	}
+	return nil
+}
+
+// validComputedName returns whether the name of a computed feature starts with a letter, followed by letters, digits
+// and underscores, so expressions of later computed features can use it.
+func validComputedName(name string) bool {
+	for i, r := range name {
+		isLetter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		if !isLetter && (i == 0 || r != '_' && (r < '0' || r > '9')) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// validComputedVariable returns whether an expression can use the given variable: a numeric feature, an exchange rate
+// of a target currency, a computed feature declared before it, or, if it has a reference country, a feature of the
+// reference country but a computed one.
+func validComputedVariable(
+	variable string,
+	config requests.DashboardConfig,
+	computed []string,
+	hasReference bool,
+) bool {
+	if hasReference && strings.HasPrefix(variable, requests.ReferencePrefix) {
+		variable = strings.TrimPrefix(variable, requests.ReferencePrefix)
+		computed = nil
+	}
+	for _, prefix := range []string{requests.TargetCurrenciesPrefix, requests.InverseRatesPrefix} {
+		if currency, ok := strings.CutPrefix(variable, prefix); ok {
+			return slices.Contains(config.Features.TargetCurrencies, currency)
+		}
+	}
+	return slices.Contains(requests.ComputedVariables, variable) || slices.Contains(computed, variable)
+}
+
+// validUnits returns whether the units are of a known system, with units of quantities given only for the custom
+// system, each a known unit of its quantity.
+func validUnits(units requests.Units) bool {
+	if units.System != "" && !slices.Contains(requests.ImplementedUnitSystems, units.System) {
+		return false
+	}
+	for _, quantity := range []struct {
+		unit  string
+		known []string
+	}{
+		{units.Temperature, requests.TemperatureUnits},
+		{units.Precipitation, requests.PrecipitationUnits},
+		{units.WindSpeed, requests.WindSpeedUnits},
+		{units.Area, requests.AreaUnits},
+	} {
+		if quantity.unit == "" {
+			continue
+		}
+		if units.System != requests.UnitsCustom || !slices.Contains(quantity.known, quantity.unit) {
+			return false
+		}
+	}
+	return true
+}
+
+// registrationErrorStatus returns the HTTP status code for an error from saving a registration.
+func registrationErrorStatus(err error) int {
+	switch err.Error() {
+	case constants.ErrRegistrationForecastDays, constants.ErrRegistrationWeatherSource,
+		constants.ErrRegistrationWeatherPoints, constants.ErrRegistrationTarget, constants.ErrRegistrationIsoCodes,
+		constants.ErrRegistrationUnits, constants.ErrRegistrationPrecision, constants.ErrRegistrationComputed,
+		constants.ErrRegistrationComputedRegional:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// dbError returns an error for getting a document that is safe to show to the client, keeping the invalid ID and
+// not found errors so callers can tell them apart.
+func dbError(err error) error {
+	switch err.Error() {
+	case constants.ErrIDInvalid, constants.ErrDBDocNotFound:
+		return err
+	default:
+		return fmt.Errorf(constants.ErrDBGetDoc)
+	}
+}
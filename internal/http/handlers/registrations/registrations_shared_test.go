@@ -0,0 +1,112 @@
+package registrations
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/requests"
+	"testing"
+)
+
+func Test_validateComputed(t *testing.T) {
+	tests := []struct {
+		name     string
+		computed []requests.ComputedFeature
+		wantErr  bool
+	}{
+		{
+			name: "Builtins",
+			computed: []requests.ComputedFeature{
+				{Name: "density", Builtin: requests.ComputedDensity},
+				{Name: "areaRank", Builtin: requests.ComputedAreaRank},
+				{Name: "euroChange", Builtin: requests.ComputedRateChange, Currency: "EUR"},
+			},
+		},
+		{
+			name: "Expressions",
+			computed: []requests.ComputedFeature{
+				{Name: "perArea", Expression: "population / area"},
+				{Name: "inEuro", Expression: "perArea * targetCurrencies.EUR + inverseRates.USD"},
+				{Name: "warmer", Expression: "temperature - reference.temperature", Reference: "se"},
+			},
+		},
+		{
+			name:     "Unknown builtin",
+			computed: []requests.ComputedFeature{{Name: "gdp", Builtin: "gdp"}},
+			wantErr:  true,
+		},
+		{
+			name:     "Rate change without a target currency",
+			computed: []requests.ComputedFeature{{Name: "change", Builtin: requests.ComputedRateChange, Currency: "JPY"}},
+			wantErr:  true,
+		},
+		{
+			name:     "Both a builtin and an expression",
+			computed: []requests.ComputedFeature{{Name: "density", Builtin: "density", Expression: "population"}},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid name",
+			computed: []requests.ComputedFeature{{Name: "per area", Expression: "population / area"}},
+			wantErr:  true,
+		},
+		{
+			name: "Duplicate name",
+			computed: []requests.ComputedFeature{
+				{Name: "density", Builtin: "density"},
+				{Name: "density", Expression: "population / area"},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "Syntax error",
+			computed: []requests.ComputedFeature{{Name: "ratio", Expression: "population /"}},
+			wantErr:  true,
+		},
+		{
+			name:     "Unknown variable",
+			computed: []requests.ComputedFeature{{Name: "ratio", Expression: "population / gdp"}},
+			wantErr:  true,
+		},
+		{
+			name: "Computed feature declared after",
+			computed: []requests.ComputedFeature{
+				{Name: "doubled", Expression: "2 * perArea"},
+				{Name: "perArea", Expression: "population / area"},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "Reference without a reference country",
+			computed: []requests.ComputedFeature{{Name: "warmer", Expression: "temperature - reference.temperature"}},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid reference country",
+			computed: []requests.ComputedFeature{{Name: "warmer", Expression: "temperature", Reference: "Sweden"}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				config := testRegistration
+				config.Computed = tt.computed
+				err := validateComputed(config)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("validateComputed() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil && err.Error() != constants.ErrRegistrationComputed {
+					t.Errorf("validateComputed() error = %v, want %v", err, constants.ErrRegistrationComputed)
+				}
+			},
+		)
+	}
+
+	regional := requests.DashboardConfig{
+		Country:  "Europe",
+		Region:   "Europe",
+		Computed: []requests.ComputedFeature{{Name: "density", Builtin: requests.ComputedDensity}},
+	}
+	if err := validateComputed(regional); err == nil || err.Error() != constants.ErrRegistrationComputedRegional {
+		t.Errorf("validateComputed() error = %v, want %v", err, constants.ErrRegistrationComputedRegional)
+	}
+}
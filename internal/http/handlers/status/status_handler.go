@@ -1,9 +1,11 @@
 package status
 
 import (
+	"assignment-2/internal/cache"
 	"assignment-2/internal/constants"
 	"assignment-2/internal/db"
 	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/upstream"
 	"assignment-2/internal/utils"
 	"encoding/json"
 	"fmt"
@@ -13,19 +15,21 @@ import (
 	"time"
 )
 
-// A status struct to hold the status of the server,
+// Status is a struct to hold the status of the server,
 // including the status of the external APIs and the version
 // of the server.
-type status struct {
-	CountriesAPI   int    `json:"countries_api"`
-	MeteoAPI       int    `json:"meteo_api"`
-	CurrencyAPI    int    `json:"currency_api"`
-	DashboardDB    int    `json:"dashboard_db"`
-	NotificationDB int    `json:"notification_db"`
-	Dashboards     int    `json:"dashboards"`
-	Webhooks       int    `json:"webhooks"`
-	Version        string `json:"version"`
-	Uptime         int    `json:"uptime"`
+type Status struct {
+	CountriesAPI   int                       `json:"countries_api"`
+	MeteoAPI       int                       `json:"meteo_api"`
+	CurrencyAPI    int                       `json:"currency_api"`
+	DashboardDB    int                       `json:"dashboard_db"`
+	NotificationDB int                       `json:"notification_db"`
+	Dashboards     int                       `json:"dashboards"`
+	Webhooks       int                       `json:"webhooks"`
+	Version        string                    `json:"version"`
+	Uptime         int                       `json:"uptime"`
+	Cache          map[string]cache.Stats    `json:"cache"`
+	Breakers       map[string]upstream.Stats `json:"breakers"`
 }
 
 // implementedMethods is a list of the implemented HTTP methods for the status endpoint.
@@ -69,31 +73,13 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 // handleStatusGetRequest handles the GET request for the /status path.
 // It returns the status of the server and the APIs it relies on.
 func handleStatusGetRequest(w http.ResponseWriter, r *http.Request) {
-	notificationCount, err := db.NumOfDocumentsInCollection(db.NotificationCollection)
-	if err != nil {
-		http.Error(w, constants.ErrDBCount, http.StatusInternalServerError)
-		return
-	}
-
-	dashboardCount, err := db.NumOfDocumentsInCollection(db.DashboardCollection)
+	currentStatus, err := GetStatus()
 	if err != nil {
+		log.Println(constants.ErrDBCount, err.Error())
 		http.Error(w, constants.ErrDBCount, http.StatusInternalServerError)
 		return
 	}
 
-	// Create a new status object
-	currentStatus := status{
-		CountriesAPI:   getStatusCode(utils.CurrentRestCountriesApi, w),
-		MeteoAPI:       getStatusCode(utils.CurrentMeteoApi, w),
-		CurrencyAPI:    getStatusCode(utils.CurrentCurrencyApi, w),
-		DashboardDB:    db.GetStatusCodeOfCollection(db.DashboardCollection),
-		NotificationDB: db.GetStatusCodeOfCollection(db.NotificationCollection),
-		Dashboards:     dashboardCount,
-		Webhooks:       notificationCount,
-		Version:        constants.Version,
-		Uptime:         int(math.Round(time.Since(utils.StartTime).Seconds())),
-	}
-
 	// Marshal the status object to JSON
 	marshaledStatus, err := json.MarshalIndent(currentStatus, "", "\t")
 	if err != nil {
@@ -111,24 +97,64 @@ func handleStatusGetRequest(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// getStatusCode returns the status code of the given URL.
-// If the URL is not reachable, it returns 503.
-func getStatusCode(url string, w http.ResponseWriter) int {
+// GetStatus returns the current status of the server and the APIs it relies on.
+func GetStatus() (Status, error) {
+	notificationCount, err := db.NumOfDocumentsInCollection(db.NotificationCollection)
+	if err != nil {
+		return Status{}, err
+	}
+
+	dashboardCount, err := db.NumOfDocumentsInCollection(db.DashboardCollection)
+	if err != nil {
+		return Status{}, err
+	}
+
+	// Create a new status object
+	currentStatus := Status{
+		CountriesAPI:   getStatusCode(utils.CurrentRestCountriesApi),
+		MeteoAPI:       getStatusCode(utils.CurrentMeteoApi),
+		CurrencyAPI:    getStatusCode(utils.CurrentCurrencyApi),
+		DashboardDB:    db.GetStatusCodeOfCollection(db.DashboardCollection),
+		NotificationDB: db.GetStatusCodeOfCollection(db.NotificationCollection),
+		Dashboards:     dashboardCount,
+		Webhooks:       notificationCount,
+		Version:        constants.Version,
+		Uptime:         int(math.Round(time.Since(utils.StartTime).Seconds())),
+		Cache:          cache.AllStats(),
+		Breakers:       upstream.AllStats(),
+	}
+
+	return currentStatus, nil
+}
+
+// getStatusCode returns the status code of the given URL, probing it with the client of the external service, but
+// without its circuit breaker, whose state is reported separately. If the URL is not reachable, it returns 503.
+func getStatusCode(url string) int {
+	client := upstream.Countries
 	switch url {
 	case utils.CurrentRestCountriesApi:
 		url = url + "all"
 	case utils.CurrentCurrencyApi:
+		client = upstream.Currency
 		url = url + "nok"
 	case utils.CurrentMeteoApi:
+		client = upstream.Meteo
 		url = url + "?latitude=60.7957&longitude=10.6915"
 	}
 
 	// Send a GET request to the URL
-	resp, err := utils.Client.Get(url)
+	r, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		log.Println(constants.ErrExternalRequest, err.Error())
+		return http.StatusServiceUnavailable
+	}
+
+	resp, err := client.Probe(r)
 	if err != nil {
 		// If there is an error, return 503
 		return http.StatusServiceUnavailable
 	}
+	defer resp.Body.Close()
 
 	// Return the status code
 	return resp.StatusCode
@@ -78,7 +78,6 @@ func TestStatusHandler(t *testing.T) {
 func Test_getStatusCode(t *testing.T) {
 	type args struct {
 		url string
-		w   http.ResponseWriter
 	}
 	tests := []struct {
 		name string
@@ -89,7 +88,6 @@ func Test_getStatusCode(t *testing.T) {
 			name: "Test_getStatusCodeCurrentRestCountriesApi",
 			args: args{
 				url: utils.CurrentRestCountriesApi,
-				w:   httptest.NewRecorder(),
 			},
 			want: http.StatusOK,
 		},
@@ -97,7 +95,6 @@ func Test_getStatusCode(t *testing.T) {
 			name: "Test_getStatusCodeCurrentMeteoApi",
 			args: args{
 				url: utils.CurrentMeteoApi,
-				w:   httptest.NewRecorder(),
 			},
 			want: http.StatusOK,
 		},
@@ -105,7 +102,6 @@ func Test_getStatusCode(t *testing.T) {
 			name: "Test_getStatusCodeCurrentCurrencyApi",
 			args: args{
 				url: utils.CurrentCurrencyApi,
-				w:   httptest.NewRecorder(),
 			},
 			want: http.StatusOK,
 		},
@@ -114,7 +110,7 @@ func Test_getStatusCode(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(
 			tt.name, func(t *testing.T) {
-				if got := getStatusCode(tt.args.url, tt.args.w); got != tt.want {
+				if got := getStatusCode(tt.args.url); got != tt.want {
 					t.Errorf("getStatusCode() = %v, want %v", got, tt.want)
 				}
 			},
@@ -161,7 +157,7 @@ func Test_handleStatusGetRequest(t *testing.T) {
 				}
 
 				// Make body a status object
-				var status status
+				var status Status
 				err := json.Unmarshal(tt.args.w.(*httptest.ResponseRecorder).Body.Bytes(), &status)
 				if err != nil {
 					t.Errorf("handleStatusGetRequest() = %v", err)
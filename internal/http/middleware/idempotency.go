@@ -0,0 +1,189 @@
+// Package middleware contains wrappers that add cross-cutting behaviour to the endpoint handlers.
+package middleware
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/utils"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keyLock is a mutex shared by all in-flight requests using the same idempotency key.
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// keyLocks holds a lock per idempotency record ID, so a retry waits for the original request to finish.
+var (
+	keyLocks   = map[string]*keyLock{}
+	keyLocksMu sync.Mutex
+)
+
+// Idempotency
+// Wraps a handler so that POST requests with an Idempotency-Key header are only processed once.
+// The first response is stored together with a hash of the request body. A retry with the same key and body
+// gets the stored response replayed, while a retry with the same key and a different body gets 409 Conflict.
+// Requests without the header, and responses with a 5xx status, are passed through without being stored.
+func Idempotency(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(constants.IdempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next(w, r)
+			return
+		}
+
+		// Read the body so it can be hashed, then put it back for the wrapped handler
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, utils.MaxBodyBytes))
+		if err != nil {
+			log.Println(constants.ErrIdempotencyReadBody, err.Error())
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				http.Error(w, constants.ErrRequestTooLarge, http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, constants.ErrIdempotencyReadBody, http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		id := recordID(r, key)
+		requestHash := hashBytes(body)
+
+		unlock := lockKey(id)
+		defer unlock()
+
+		record, err := db.GetDocument[inhouse.IdempotencyRecord](id, db.IdempotencyCollection)
+		switch {
+		case err == nil && time.Since(record.CreatedAt) <= utils.GetIdempotencyTTL():
+			if record.RequestHash != requestHash {
+				http.Error(w, constants.ErrIdempotencyKeyConflict, http.StatusConflict)
+				return
+			}
+			replay(w, record)
+			return
+		case err == nil:
+			// The record has expired, so the key can be reused
+			if err2 := db.DeleteDocument(id, db.IdempotencyCollection); err2 != nil {
+				log.Println(constants.ErrDBDeleteDoc, err2.Error())
+			}
+		case err.Error() != constants.ErrDBDocNotFound:
+			log.Println(constants.ErrIdempotencyLookup, err.Error())
+			http.Error(w, constants.ErrIdempotencyLookup, http.StatusInternalServerError)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		next(recorder, r)
+
+		// Server errors are not stored, so the client can retry them with the same key
+		if recorder.statusCode() >= http.StatusInternalServerError {
+			return
+		}
+
+		newRecord := inhouse.IdempotencyRecord{
+			ID:          id,
+			RequestHash: requestHash,
+			StatusCode:  recorder.statusCode(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+			CreatedAt:   time.Now(),
+		}
+		err = db.AddDocument[inhouse.IdempotencyRecord](newRecord, db.IdempotencyCollection)
+		if err != nil {
+			log.Println(constants.ErrIdempotencyStore, err.Error())
+		}
+	}
+}
+
+// replay writes a stored response to the client.
+func replay(w http.ResponseWriter, record inhouse.IdempotencyRecord) {
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	w.Header().Set(constants.IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+
+	_, err := w.Write(record.Body)
+	if err != nil {
+		log.Println(constants.ErrWriteResponse + err.Error())
+	}
+}
+
+// recordID scopes the idempotency key to the method and path, so the same key can be used on different endpoints.
+func recordID(r *http.Request, key string) string {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	return hashBytes([]byte(r.Method + " " + path + " " + key))
+}
+
+// hashBytes returns the hexadecimal SHA-256 hash of the given bytes.
+func hashBytes(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// lockKey locks the given record ID and returns the function that unlocks it again.
+func lockKey(id string) func() {
+	keyLocksMu.Lock()
+	lock, ok := keyLocks[id]
+	if !ok {
+		lock = &keyLock{}
+		keyLocks[id] = lock
+	}
+	lock.refs++
+	keyLocksMu.Unlock()
+
+	lock.mu.Lock()
+
+	return func() {
+		lock.mu.Unlock()
+
+		keyLocksMu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(keyLocks, id)
+		}
+		keyLocksMu.Unlock()
+	}
+}
+
+// responseRecorder passes the response through to the client while keeping a copy of the status code and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records the first status code written.
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	if rec.status == 0 {
+		rec.status = statusCode
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the body written.
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// statusCode returns the recorded status code, which is 200 if the handler never wrote one.
+func (rec *responseRecorder) statusCode() int {
+	if rec.status == 0 {
+		return http.StatusOK
+	}
+	return rec.status
+}
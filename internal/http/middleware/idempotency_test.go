@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/mock"
+	"assignment-2/internal/utils"
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// Setup function
+	log.Println("Setup for testing middleware")
+	mock.InitForTesting()
+
+	// Run tests
+	m.Run()
+
+	defer func() {
+		// Teardown function
+		log.Println("Teardown for testing middleware")
+		mock.TeardownAfterTesting()
+	}()
+}
+
+// countingHandler returns a handler that counts its invocations and responds with 201 Created.
+func countingHandler(calls *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"` + utils.GenerateRandomID() + `"}`))
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	key := utils.GenerateRandomID()
+
+	tests := []struct {
+		name         string
+		method       string
+		key          string
+		body         string
+		wantedStatus int
+		wantedCalls  int
+		wantReplayed bool
+	}{
+		{
+			name:         "FirstRequestWithKey",
+			method:       http.MethodPost,
+			key:          key,
+			body:         `{"country":"Norway"}`,
+			wantedStatus: http.StatusCreated,
+			wantedCalls:  1,
+		},
+		{
+			name:         "RetryWithSameKeyAndBody",
+			method:       http.MethodPost,
+			key:          key,
+			body:         `{"country":"Norway"}`,
+			wantedStatus: http.StatusCreated,
+			wantedCalls:  1,
+			wantReplayed: true,
+		},
+		{
+			name:         "RetryWithSameKeyAndDifferentBody",
+			method:       http.MethodPost,
+			key:          key,
+			body:         `{"country":"Sweden"}`,
+			wantedStatus: http.StatusConflict,
+			wantedCalls:  1,
+		},
+		{
+			name:         "RequestWithoutKey",
+			method:       http.MethodPost,
+			body:         `{"country":"Norway"}`,
+			wantedStatus: http.StatusCreated,
+			wantedCalls:  2,
+		},
+		{
+			name:         "GetRequestWithKeyIsIgnored",
+			method:       http.MethodGet,
+			key:          key,
+			wantedStatus: http.StatusCreated,
+			wantedCalls:  3,
+		},
+	}
+
+	calls := 0
+	handler := Idempotency(countingHandler(&calls))
+
+	var firstBody string
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, constants.RegistrationsPath, bytes.NewBufferString(tt.body))
+				if tt.key != "" {
+					req.Header.Set(constants.IdempotencyKeyHeader, tt.key)
+				}
+				w := httptest.NewRecorder()
+
+				handler(w, req)
+
+				if w.Code != tt.wantedStatus {
+					t.Errorf("Idempotency() status = %v, want %v", w.Code, tt.wantedStatus)
+				}
+				if calls != tt.wantedCalls {
+					t.Errorf("Idempotency() handler calls = %v, want %v", calls, tt.wantedCalls)
+				}
+
+				replayed := w.Header().Get(constants.IdempotentReplayedHeader) == "true"
+				if replayed != tt.wantReplayed {
+					t.Errorf("Idempotency() replayed = %v, want %v", replayed, tt.wantReplayed)
+				}
+
+				// A replayed response must be identical to the original
+				if tt.name == "FirstRequestWithKey" {
+					firstBody = w.Body.String()
+				}
+				if tt.wantReplayed && w.Body.String() != firstBody {
+					t.Errorf("Idempotency() replayed body = %v, want %v", w.Body.String(), firstBody)
+				}
+			},
+		)
+	}
+}
+
+func Test_recordID(t *testing.T) {
+	tests := []struct {
+		name      string
+		first     *http.Request
+		second    *http.Request
+		wantEqual bool
+	}{
+		{
+			name:      "TrailingSlashIsIgnored",
+			first:     httptest.NewRequest(http.MethodPost, constants.RegistrationsPath, nil),
+			second:    httptest.NewRequest(http.MethodPost, constants.RegistrationsPath[:len(constants.RegistrationsPath)-1], nil),
+			wantEqual: true,
+		},
+		{
+			name:      "DifferentEndpointsAreSeparated",
+			first:     httptest.NewRequest(http.MethodPost, constants.RegistrationsPath, nil),
+			second:    httptest.NewRequest(http.MethodPost, constants.NotificationsPath, nil),
+			wantEqual: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				equal := recordID(tt.first, "key") == recordID(tt.second, "key")
+				if equal != tt.wantEqual {
+					t.Errorf("recordID() equal = %v, want %v", equal, tt.wantEqual)
+				}
+			},
+		)
+	}
+}
@@ -5,9 +5,13 @@ import (
 	"assignment-2/internal/db"
 	"assignment-2/internal/http/handlers"
 	"assignment-2/internal/http/handlers/dashboards"
+	"assignment-2/internal/http/handlers/events"
+	"assignment-2/internal/http/handlers/graphql"
 	"assignment-2/internal/http/handlers/notifications"
 	"assignment-2/internal/http/handlers/registrations"
 	"assignment-2/internal/http/handlers/status"
+	"assignment-2/internal/http/middleware"
+	"assignment-2/internal/rpc"
 	"assignment-2/internal/utils"
 	"log"
 	"net/http"
@@ -38,11 +42,11 @@ func Start() {
 	mux.HandleFunc(constants.StatusPath, status.Handler)
 	mux.HandleFunc(constants.StatusPath[:len(constants.StatusPath)-1], status.Handler)
 
-	// Registrations
-	mux.HandleFunc(constants.RegistrationsPath, registrations.HandlerWithoutID)
+	// Registrations, POST requests are made idempotent with the Idempotency-Key header
+	mux.HandleFunc(constants.RegistrationsPath, middleware.Idempotency(registrations.HandlerWithoutID))
 	mux.HandleFunc(
 		constants.RegistrationsPath[:len(constants.RegistrationsPath)-1],
-		registrations.HandlerWithoutID,
+		middleware.Idempotency(registrations.HandlerWithoutID),
 	)
 
 	// Registrations with ID
@@ -50,20 +54,39 @@ func Start() {
 
 	// Dashboards
 	mux.HandleFunc(constants.DashboardsPath+"{id}", dashboards.HandlerWithID)
+	mux.HandleFunc(constants.DashboardsPath+"{id}/live", dashboards.LiveHandler)
+	mux.HandleFunc(constants.DashboardsPath+"{id}/history", dashboards.HistoryHandler)
+	mux.HandleFunc(constants.DashboardsPath+"{id}/view", dashboards.ViewHandler)
+	mux.HandleFunc(constants.DashboardsPath+"{id}/badge.svg", dashboards.BadgeHandler)
 
-	// Notifications
-	mux.HandleFunc(constants.NotificationsPath, notifications.HandlerWithoutID)
+	// Comparison of dashboards
+	mux.HandleFunc(constants.ComparePath, dashboards.CompareHandler)
+	mux.HandleFunc(constants.ComparePath[:len(constants.ComparePath)-1], dashboards.CompareHandler)
+
+	// Notifications, POST requests are made idempotent with the Idempotency-Key header
+	mux.HandleFunc(constants.NotificationsPath, middleware.Idempotency(notifications.HandlerWithoutID))
 	mux.HandleFunc(
 		constants.NotificationsPath[:len(constants.NotificationsPath)-1],
-		notifications.HandlerWithoutID,
+		middleware.Idempotency(notifications.HandlerWithoutID),
 	)
 
 	// Notifications with ID
 	mux.HandleFunc(constants.NotificationsPath+"{id}", notifications.HandlerWithID)
 
+	// Events
+	mux.HandleFunc(constants.EventsPath, events.Handler)
+	mux.HandleFunc(constants.EventsPath[:len(constants.EventsPath)-1], events.Handler)
+
+	// GraphQL
+	mux.HandleFunc(constants.GraphQLPath, graphql.Handler)
+	mux.HandleFunc(constants.GraphQLPath[:len(constants.GraphQLPath)-1], graphql.Handler)
+
 	// Default
 	mux.HandleFunc("/", handlers.DefaultHandler)
 
+	// Start the gRPC server on its own port, sharing the database client
+	go rpc.Start()
+
 	// Start server
 	log.Println("Starting server on port " + port + " ...")
 	log.Fatal(http.ListenAndServe(":"+port, mux))
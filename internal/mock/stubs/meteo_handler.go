@@ -1,18 +1,47 @@
 package stubs
 
 import (
-	"fmt"
+	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
 )
 
+// meteoStubDays is the number of days of the Meteo API stub forecast.
+const meteoStubDays = 3
+
 func MeteoHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		w.Header().Add("content-type", "application/json")
 		output := ParseFile("../../../mock/resources/meteo_norway.json")
 
-		_, err := fmt.Fprint(w, string(output))
+		var forecast map[string]any
+		err := json.Unmarshal(output, &forecast)
+		if err != nil {
+			log.Println("Error while trying to decode the Meteo API stub: ", err.Error())
+			http.Error(
+				w,
+				"Error while trying to decode the Meteo API stub.",
+				http.StatusInternalServerError,
+			)
+			return
+		}
+
+		// Only respond with the hours of the requested number of days, like the Meteo API
+		days, err := strconv.Atoi(r.URL.Query().Get("forecast_days"))
+		if err != nil || days < 1 || days > meteoStubDays {
+			days = 1
+		}
+		if hourly, ok := forecast["hourly"].(map[string]any); ok {
+			for variable, values := range hourly {
+				if values, ok := values.([]any); ok && len(values) > days*24 {
+					hourly[variable] = values[:days*24]
+				}
+			}
+		}
+
+		err = json.NewEncoder(w).Encode(forecast)
 		if err != nil {
 			log.Println("Error while trying to display the Meteo API stub: ", err.Error())
 			http.Error(
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 )
 
 func RestCountriesHandler(w http.ResponseWriter, r *http.Request) {
@@ -21,6 +22,14 @@ func RestCountriesHandler(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("content-type", "application/json")
 		// Hideous way to get the path to the mock resources, but it works for now
 		output := ParseFile("../../../mock/resources/restcountries_no.json")
+		// The bordering countries of Norway are requested by their codes
+		if r.URL.Query().Has("codes") {
+			output = ParseFile("../../../mock/resources/restcountries_borders.json")
+		}
+		// Every region and subregion is the Nordic countries
+		if strings.Contains(r.URL.Path, "region/") {
+			output = ParseFile("../../../mock/resources/restcountries_region.json")
+		}
 
 		_, err := fmt.Fprint(w, string(output))
 		if err != nil {
@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/responses"
+	"assignment-2/internal/upstream"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// ExchangeRateApi supplies exchange rates from an API in the format of the open access ExchangeRate-API, such as the
+// currency API of the course.
+type ExchangeRateApi struct {
+	Api string
+}
+
+// ExchangeRates returns the exchange rates from the currency with the given code from the currency API.
+func (p ExchangeRateApi) ExchangeRates(ctx context.Context, base string) (ExchangeRates, error) {
+	// Get the exchange rates from the currency API
+	r, err1 := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf(
+			"%s%s",
+			p.Api, base,
+		),
+		nil,
+	)
+	if err1 != nil {
+		log.Println(constants.ErrExternalRequest, err1.Error())
+		return ExchangeRates{}, fmt.Errorf(constants.ErrExternalRequest)
+	}
+
+	r.Header.Add("content-type", "application/json")
+
+	// Issue request
+	res, err2 := upstream.Currency.Do(r)
+	if err2 != nil {
+		log.Println(constants.ErrExternalResponse, err2.Error())
+		return ExchangeRates{}, fmt.Errorf(constants.ErrExternalResponse)
+	}
+	defer res.Body.Close()
+	if !isSuccessful(res.StatusCode) {
+		log.Println(constants.ErrExternalResponse, res.Status)
+		return ExchangeRates{}, fmt.Errorf(constants.ErrExternalResponse)
+	}
+
+	// Decode JSON
+	var response responses.ResponseFromCurrency
+	err3 := json.NewDecoder(res.Body).Decode(&response)
+	if err3 != nil {
+		log.Println(constants.ErrJsonDecode, err3.Error())
+		return ExchangeRates{}, fmt.Errorf(constants.ErrJsonDecode)
+	}
+
+	return ExchangeRates{
+		Base:  response.BaseCode,
+		Rates: response.Rates,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package providers
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/responses"
+	"assignment-2/internal/upstream"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// openMeteoTimeLayout is the layout of the hourly times from the Open-Meteo API, in the local time of the location.
+const openMeteoTimeLayout = "2006-01-02T15:04"
+
+// openMeteoHourly are the hourly variables requested from the Open-Meteo API.
+const openMeteoHourly = "temperature_2m,precipitation,relative_humidity_2m,cloud_cover,wind_speed_10m,wind_gusts_10m," +
+	"uv_index"
+
+// OpenMeteo supplies weather forecasts from the Open-Meteo API.
+type OpenMeteo struct {
+	Api string
+}
+
+// Forecast returns the hourly forecast for the given number of days, starting today, at the given coordinates from
+// the Open-Meteo API. The API resolves the timezone of the coordinates, so the days are those of the local time.
+func (p OpenMeteo) Forecast(ctx context.Context, coordinates inhouse.Coordinates, days int) (Forecast, error) {
+	// Get the weather data from the meteo API
+	r, err1 := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf(
+			"%s?latitude=%f&longitude=%f&hourly=%s&timezone=auto&forecast_days=%d",
+			p.Api, coordinates.Latitude, coordinates.Longitude, openMeteoHourly, days,
+		),
+		nil,
+	)
+	if err1 != nil {
+		log.Println(constants.ErrExternalRequest, err1.Error())
+		return Forecast{}, fmt.Errorf(constants.ErrExternalRequest)
+	}
+
+	r.Header.Add("content-type", "application/json")
+
+	// Issue request
+	res, err2 := upstream.Meteo.Do(r)
+	if err2 != nil {
+		log.Println(constants.ErrExternalResponse, err2.Error())
+		return Forecast{}, fmt.Errorf(constants.ErrExternalResponse)
+	}
+	defer res.Body.Close()
+	if !isSuccessful(res.StatusCode) {
+		log.Println(constants.ErrExternalResponse, res.Status)
+		return Forecast{}, fmt.Errorf(constants.ErrExternalResponse)
+	}
+
+	// Decode JSON
+	var meteo responses.MeteoForecastResponse
+	err3 := json.NewDecoder(res.Body).Decode(&meteo)
+	if err3 != nil {
+		log.Println(constants.ErrJsonDecode, err3.Error())
+		return Forecast{}, fmt.Errorf(constants.ErrJsonDecode)
+	}
+
+	// Parse the hourly times in the timezone of the location, falling back to its offset if the timezone is unknown
+	location, err4 := time.LoadLocation(meteo.Timezone)
+	if err4 != nil || meteo.Timezone == "" {
+		location = time.FixedZone(meteo.TimezoneAbbreviation, meteo.UtcOffsetSeconds)
+	}
+
+	times := make([]time.Time, 0, len(meteo.Hourly.Time))
+	for _, hour := range meteo.Hourly.Time {
+		parsed, err5 := time.ParseInLocation(openMeteoTimeLayout, hour, location)
+		if err5 != nil {
+			log.Println(constants.ErrJsonDecode, err5.Error())
+			return Forecast{}, fmt.Errorf(constants.ErrJsonDecode)
+		}
+		times = append(times, parsed)
+	}
+
+	return Forecast{
+		Location:      location,
+		Time:          times,
+		Temperature:   meteo.Hourly.Temperature2M,
+		Precipitation: meteo.Hourly.Precipitation,
+		Humidity:      meteo.Hourly.RelativeHumidity2M,
+		CloudCover:    meteo.Hourly.CloudCover,
+		WindSpeed:     meteo.Hourly.WindSpeed10M,
+		WindGusts:     meteo.Hourly.WindGusts10M,
+		UvIndex:       meteo.Hourly.UvIndex,
+	}, nil
+}
@@ -0,0 +1,138 @@
+// Package providers supplies the country, weather and currency data of the dashboards. Each kind of data is supplied
+// by a provider behind an interface, so the provider can be chosen per deployment with the COUNTRY_PROVIDER,
+// WEATHER_PROVIDER and CURRENCY_PROVIDER environment variables.
+package providers
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/responses"
+	"assignment-2/internal/utils"
+	"context"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Country is the data of a country.
+type Country struct {
+	Name        string
+	IsoCode     string
+	Capitals    []string
+	Coordinates *inhouse.Coordinates
+	// CapitalCoordinates are the coordinates of the first capital, or nil if they are unknown.
+	CapitalCoordinates *inhouse.Coordinates
+	Population         int
+	Area               float64
+	Currencies         []responses.Currency
+	// Location is the timezone of the country, or nil if it has several.
+	Location *time.Location
+	// Languages are the names of the official languages, and Borders the names of the bordering countries.
+	Languages    []string
+	Borders      []string
+	Region       string
+	Subregion    string
+	Flag         inhouse.Flag
+	Timezones    []string
+	CallingCodes []string
+	DrivingSide  string
+	// Gini is the latest Gini index, or nil if it is unknown.
+	Gini *inhouse.Gini
+	// Translations are the names of the country in other languages than English, by language tag such as "de".
+	Translations map[string]string
+}
+
+// Forecast is the hourly weather forecast at a location. The values are by hour, at the times in Time, which are in
+// the local time of the location. Hours without a value are nil.
+type Forecast struct {
+	Location      *time.Location
+	Time          []time.Time
+	Temperature   []*float64
+	Precipitation []*float64
+	Humidity      []*float64
+	CloudCover    []*float64
+	WindSpeed     []*float64
+	WindGusts     []*float64
+	UvIndex       []*float64
+}
+
+// ExchangeRates are the exchange rates from a base currency, by currency code.
+type ExchangeRates struct {
+	Base  string
+	Rates map[string]float64
+}
+
+// CountryProvider supplies the data of countries.
+type CountryProvider interface {
+	// Country returns the country with the given ISO code, or constants.ErrDashboardCountryNotFound if there is none.
+	Country(ctx context.Context, isoCode string) (Country, error)
+	// Region returns the names, ISO codes and areas of the countries of the given region, such as "Europe", sorted by
+	// ISO code, or constants.ErrDashboardRegionNotFound if there is none.
+	Region(ctx context.Context, region string) ([]Country, error)
+	// Subregion returns the names, ISO codes and areas of the countries of the given subregion, such as
+	// "Northern Europe", sorted by ISO code, or constants.ErrDashboardRegionNotFound if there is none.
+	Subregion(ctx context.Context, subregion string) ([]Country, error)
+}
+
+// WeatherProvider supplies weather forecasts.
+type WeatherProvider interface {
+	// Forecast returns the hourly forecast for the given number of days, starting today, at the given coordinates.
+	Forecast(ctx context.Context, coordinates inhouse.Coordinates, days int) (Forecast, error)
+}
+
+// CurrencyProvider supplies exchange rates.
+type CurrencyProvider interface {
+	// ExchangeRates returns the exchange rates from the currency with the given code.
+	ExchangeRates(ctx context.Context, base string) (ExchangeRates, error)
+}
+
+// The available providers, by name. The providers are created for every request, so they use the API URLs at the time
+// of the request.
+var (
+	countryProviders = map[string]func() CountryProvider{
+		"restcountries": func() CountryProvider { return RestCountries{Api: utils.CurrentRestCountriesApi} },
+	}
+	weatherProviders = map[string]func() WeatherProvider{
+		"open-meteo": func() WeatherProvider { return OpenMeteo{Api: utils.CurrentMeteoApi} },
+	}
+	currencyProviders = map[string]func() CurrencyProvider{
+		"exchangerate-api": func() CurrencyProvider { return ExchangeRateApi{Api: utils.CurrentCurrencyApi} },
+	}
+)
+
+// The providers chosen for the deployment
+var (
+	newCountryProvider  = choose(countryProviders, utils.GetCountryProvider(), utils.DefaultCountryProvider)
+	newWeatherProvider  = choose(weatherProviders, utils.GetWeatherProvider(), utils.DefaultWeatherProvider)
+	newCurrencyProvider = choose(currencyProviders, utils.GetCurrencyProvider(), utils.DefaultCurrencyProvider)
+)
+
+// Countries returns the provider of country data.
+func Countries() CountryProvider {
+	return newCountryProvider()
+}
+
+// Weather returns the provider of weather data.
+func Weather() WeatherProvider {
+	return newWeatherProvider()
+}
+
+// Currencies returns the provider of exchange rates.
+func Currencies() CurrencyProvider {
+	return newCurrencyProvider()
+}
+
+// isSuccessful returns whether a response with the given status code holds the data asked for, rather than an error of
+// the external service.
+func isSuccessful(statusCode int) bool {
+	return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+}
+
+// choose returns the provider with the given name, or the default provider if there is no provider with the name.
+func choose[T any](available map[string]func() T, name string, fallback string) func() T {
+	if newProvider, ok := available[name]; ok {
+		return newProvider
+	}
+	log.Printf("%s %q, using default: %s\n", constants.ErrExternalProvider, name, fallback)
+	return available[fallback]
+}
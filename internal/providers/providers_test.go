@@ -0,0 +1,396 @@
+package providers
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/responses"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestServer starts a server that responds to every request with the given JSON body, and records the URL of the
+// last request.
+func newTestServer(t *testing.T, body string) (*httptest.Server, *string) {
+	var requested string
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				requested = r.URL.String()
+				_, _ = w.Write([]byte(body))
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server, &requested
+}
+
+func Test_failingStatus(t *testing.T) {
+	// The error object of the service is not decoded as data. A client error is used, as it is neither retried nor
+	// counted by the circuit breakers shared with the other tests
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"result": "error", "error-type": "malformed-request"}`, http.StatusBadRequest)
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	api := server.URL + "/"
+
+	tests := []struct {
+		name  string
+		fetch func() error
+	}{
+		{
+			name: "Country",
+			fetch: func() error {
+				_, err := RestCountries{Api: api}.Country(context.Background(), "NO")
+				return err
+			},
+		},
+		{
+			name: "Region",
+			fetch: func() error {
+				_, err := RestCountries{Api: api}.Region(context.Background(), "Europe")
+				return err
+			},
+		},
+		{
+			name: "Forecast",
+			fetch: func() error {
+				_, err := OpenMeteo{Api: api}.Forecast(context.Background(), inhouse.Coordinates{}, 1)
+				return err
+			},
+		},
+		{
+			name: "ExchangeRates",
+			fetch: func() error {
+				_, err := ExchangeRateApi{Api: api}.ExchangeRates(context.Background(), "NOK")
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if err := tt.fetch(); err == nil || err.Error() != constants.ErrExternalResponse {
+					t.Errorf("%s() error = %v, want %v", tt.name, err, constants.ErrExternalResponse)
+				}
+			},
+		)
+	}
+}
+
+func TestRestCountries_Country(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    Country
+		wantErr string
+	}{
+		{
+			name: "Country is converted",
+			body: `{
+				"name": {"common": "Norway", "official": "Kingdom of Norway"},
+				"cca2": "NO",
+				"currencies": {"NOK": {"name": "Norwegian krone", "symbol": "kr"}},
+				"capital": ["Oslo"],
+				"latlng": [62, 10],
+				"capitalInfo": {"latlng": [59.92, 10.75]},
+				"area": 323802,
+				"population": 5379475,
+				"timezones": ["UTC+01:00"],
+				"languages": {"nob": "Norwegian Bokmål", "nno": "Norwegian Nynorsk", "smi": "Sami"},
+				"region": "Europe",
+				"subregion": "Northern Europe",
+				"flag": "🇳🇴",
+				"flags": {"png": "https://flagcdn.com/w320/no.png", "svg": "https://flagcdn.com/no.svg"},
+				"idd": {"root": "+4", "suffixes": ["7"]},
+				"car": {"side": "right"},
+				"gini": {"2018": 27.7}
+			}`,
+			want: Country{
+				Name:               "Norway",
+				IsoCode:            "NO",
+				Capitals:           []string{"Oslo"},
+				Coordinates:        &inhouse.Coordinates{Latitude: 62, Longitude: 10},
+				CapitalCoordinates: &inhouse.Coordinates{Latitude: 59.92, Longitude: 10.75},
+				Population:         5379475,
+				Area:               323802,
+				Currencies:         []responses.Currency{{Name: "Norwegian krone", Symbol: "kr", Code: "NOK"}},
+				Location:           time.FixedZone("UTC+01:00", 3600),
+				Languages:          []string{"Norwegian Bokmål", "Norwegian Nynorsk", "Sami"},
+				Region:             "Europe",
+				Subregion:          "Northern Europe",
+				Flag: inhouse.Flag{
+					Emoji: "🇳🇴",
+					Png:   "https://flagcdn.com/w320/no.png",
+					Svg:   "https://flagcdn.com/no.svg",
+				},
+				Timezones:    []string{"UTC+01:00"},
+				CallingCodes: []string{"+47"},
+				DrivingSide:  "right",
+				Gini:         &inhouse.Gini{Year: 2018, Value: 27.7},
+			},
+		},
+		{
+			name: "Calling codes without suffixes and the latest Gini index",
+			body: `{
+				"name": {"common": "Åland Islands"},
+				"idd": {"root": "+358"},
+				"gini": {"2011": 30.1, "2019": 27.7, "2015": 27.1}
+			}`,
+			want: Country{
+				Name:         "Åland Islands",
+				CallingCodes: []string{"+358"},
+				Gini:         &inhouse.Gini{Year: 2019, Value: 27.7},
+			},
+		},
+		{
+			name: "Translations by language tag",
+			body: `{
+				"name": {"common": "Germany"},
+				"translations": {
+					"fra": {"official": "République fédérale d'Allemagne", "common": "Allemagne"},
+					"per": {"official": "جمهوری فدرال آلمان", "common": "آلمان"},
+					"xyz": {"official": "Unknown", "common": "Unknown"}
+				}
+			}`,
+			want: Country{
+				Name:         "Germany",
+				Translations: map[string]string{"fr": "Allemagne", "fa": "آلمان"},
+			},
+		},
+		{
+			name: "Currencies are sorted by code",
+			body: `{
+				"name": {"common": "Zimbabwe"},
+				"currencies": {"ZWL": {"name": "Zimbabwean dollar"}, "BWP": {"name": "Botswana pula"}}
+			}`,
+			want: Country{
+				Name: "Zimbabwe",
+				Currencies: []responses.Currency{
+					{Name: "Botswana pula", Code: "BWP"},
+					{Name: "Zimbabwean dollar", Code: "ZWL"},
+				},
+			},
+		},
+		{
+			name: "Timezone of a country with several timezones is unknown",
+			body: `{"name": {"common": "Russia"}, "timezones": ["UTC+03:00", "UTC+04:00"]}`,
+			want: Country{Name: "Russia", Timezones: []string{"UTC+03:00", "UTC+04:00"}},
+		},
+		{
+			name: "Timezone at UTC",
+			body: `{"name": {"common": "Iceland"}, "timezones": ["UTC"]}`,
+			want: Country{Name: "Iceland", Location: time.UTC, Timezones: []string{"UTC"}},
+		},
+		{
+			name:    "Unknown country is not found",
+			body:    `{"status": 404, "message": "Not Found"}`,
+			wantErr: constants.ErrDashboardCountryNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				server, requested := newTestServer(t, tt.body)
+
+				got, err := RestCountries{Api: server.URL + "/"}.Country(context.Background(), "no")
+				if tt.wantErr != "" {
+					if err == nil || err.Error() != tt.wantErr {
+						t.Errorf("Country() error = %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Country() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Country() = %+v, want %+v", got, tt.want)
+				}
+				if want := "/alpha/no?fields=" + restCountriesFields; *requested != want {
+					t.Errorf("Country() requested %v, want %v", *requested, want)
+				}
+			},
+		)
+	}
+}
+
+func TestRestCountries_Country_borders(t *testing.T) {
+	tests := []struct {
+		name    string
+		borders string
+		want    []string
+	}{
+		{
+			name: "Borders are resolved to names",
+			borders: `[
+				{"name": {"common": "Sweden"}, "cca3": "SWE"},
+				{"name": {"common": "Finland"}, "cca3": "FIN"},
+				{"name": {"common": "Russia"}, "cca3": "RUS"}
+			]`,
+			want: []string{"Finland", "Russia", "Sweden"},
+		},
+		{
+			name:    "Borders the API does not return are left as codes",
+			borders: `[{"name": {"common": "Sweden"}, "cca3": "SWE"}, {"name": {"common": "Finland"}, "cca3": "FIN"}]`,
+			want:    []string{"Finland", "RUS", "Sweden"},
+		},
+		{
+			name:    "Unresolved borders are left as codes",
+			borders: `{"status": 400, "message": "Bad Request"}`,
+			want:    []string{"FIN", "SWE", "RUS"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var requested string
+				server := httptest.NewServer(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							if r.URL.Query().Has("codes") {
+								requested = r.URL.String()
+								_, _ = w.Write([]byte(tt.borders))
+								return
+							}
+							_, _ = w.Write([]byte(`{"name": {"common": "Norway"}, "borders": ["FIN", "SWE", "RUS"]}`))
+						},
+					),
+				)
+				defer server.Close()
+
+				got, err := RestCountries{Api: server.URL + "/"}.Country(context.Background(), "no")
+				if err != nil {
+					t.Fatalf("Country() error = %v", err)
+				}
+				if !reflect.DeepEqual(got.Borders, tt.want) {
+					t.Errorf("Country() borders = %v, want %v", got.Borders, tt.want)
+				}
+				if want := "/alpha?codes=FIN,SWE,RUS&fields=name,cca3"; requested != want {
+					t.Errorf("Country() requested %v, want %v", requested, want)
+				}
+			},
+		)
+	}
+}
+
+func TestRestCountries_Subregion(t *testing.T) {
+	server, requested := newTestServer(
+		t, `[{"name": {"common": "Sweden"}, "cca2": "SE", "area": 450295}, {"name": {"common": "Norway"}, "cca2": "NO"}]`,
+	)
+
+	got, err := RestCountries{Api: server.URL + "/"}.Subregion(context.Background(), "Northern Europe")
+	if err != nil {
+		t.Fatalf("Subregion() error = %v", err)
+	}
+
+	want := []Country{{Name: "Norway", IsoCode: "NO"}, {Name: "Sweden", IsoCode: "SE", Area: 450295}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Subregion() = %+v, want %+v", got, want)
+	}
+	if want := "/subregion/Northern%20Europe?fields=name,cca2,area"; *requested != want {
+		t.Errorf("Subregion() requested %v, want %v", *requested, want)
+	}
+}
+
+// hourly returns a pointer to the given hourly value of a forecast.
+func hourly(value float64) *float64 {
+	return &value
+}
+
+func TestOpenMeteo_Forecast(t *testing.T) {
+	server, requested := newTestServer(
+		t, `{"timezone": "Asia/Tokyo", "hourly": {
+			"time": ["2024-04-17T00:00", "2024-04-17T01:00"],
+			"temperature_2m": [1.5, null],
+			"precipitation": [0, 0.2],
+			"relative_humidity_2m": [80, 85],
+			"cloud_cover": [100, 90],
+			"wind_speed_10m": [3.2, 4.1],
+			"wind_gusts_10m": [7.6, 9.4],
+			"uv_index": [0, 0]
+		}}`,
+	)
+
+	got, err := OpenMeteo{Api: server.URL + "/"}.Forecast(
+		context.Background(), inhouse.Coordinates{Latitude: 62, Longitude: 10}, 2,
+	)
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	want := Forecast{
+		Location: tokyo,
+		Time: []time.Time{
+			time.Date(2024, 4, 17, 0, 0, 0, 0, tokyo),
+			time.Date(2024, 4, 17, 1, 0, 0, 0, tokyo),
+		},
+		Temperature:   []*float64{hourly(1.5), nil},
+		Precipitation: []*float64{hourly(0), hourly(0.2)},
+		Humidity:      []*float64{hourly(80), hourly(85)},
+		CloudCover:    []*float64{hourly(100), hourly(90)},
+		WindSpeed:     []*float64{hourly(3.2), hourly(4.1)},
+		WindGusts:     []*float64{hourly(7.6), hourly(9.4)},
+		UvIndex:       []*float64{hourly(0), hourly(0)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Forecast() = %+v, want %+v", got, want)
+	}
+	if want := "/?latitude=62.000000&longitude=10.000000&hourly=" + openMeteoHourly + "&timezone=auto&forecast_days=2"; *requested != want {
+		t.Errorf("Forecast() requested %v, want %v", *requested, want)
+	}
+}
+
+func TestExchangeRateApi_ExchangeRates(t *testing.T) {
+	server, requested := newTestServer(
+		t, `{"result": "success", "base_code": "NOK", "rates": {"NOK": 1, "EUR": 0.086289}}`,
+	)
+
+	got, err := ExchangeRateApi{Api: server.URL + "/"}.ExchangeRates(context.Background(), "NOK")
+	if err != nil {
+		t.Fatalf("ExchangeRates() error = %v", err)
+	}
+
+	want := ExchangeRates{Base: "NOK", Rates: map[string]float64{"NOK": 1, "EUR": 0.086289}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExchangeRates() = %+v, want %+v", got, want)
+	}
+	if *requested != "/NOK" {
+		t.Errorf("ExchangeRates() requested %v, want /NOK", *requested)
+	}
+}
+
+func Test_choose(t *testing.T) {
+	available := map[string]func() string{
+		"default":     func() string { return "default" },
+		"alternative": func() string { return "alternative" },
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		want     string
+	}{
+		{name: "Chosen provider", provider: "alternative", want: "alternative"},
+		{name: "Unknown provider falls back to the default", provider: "unknown", want: "default"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := choose(available, tt.provider, "default")(); got != tt.want {
+					t.Errorf("choose() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,303 @@
+package providers
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/responses"
+	"assignment-2/internal/upstream"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// restCountriesFields are the fields requested from the REST Countries API.
+const restCountriesFields = "name,cca2,currencies,capital,latlng,area,population,timezones,capitalInfo,languages," +
+	"borders,region,subregion,flag,flags,idd,car,gini,translations"
+
+// restCountriesLanguages are the language tags of the translations of the REST Countries API, by its language codes.
+var restCountriesLanguages = map[string]string{
+	"ara": "ar", "bre": "br", "ces": "cs", "cym": "cy", "deu": "de", "est": "et", "fin": "fi", "fra": "fr",
+	"hrv": "hr", "hun": "hu", "ita": "it", "jpn": "ja", "kor": "ko", "nld": "nl", "per": "fa", "pol": "pl",
+	"por": "pt", "rus": "ru", "slk": "sk", "spa": "es", "srp": "sr", "swe": "sv", "tur": "tr", "urd": "ur",
+	"zho": "zh",
+}
+
+// RestCountries supplies country data from the REST Countries API.
+type RestCountries struct {
+	Api string
+}
+
+// Country returns the country with the given ISO code from the REST Countries API.
+func (p RestCountries) Country(ctx context.Context, isoCode string) (Country, error) {
+	// Get the country data from the restcountries API
+	r, err1 := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		p.Api+"alpha/"+isoCode+"?fields="+restCountriesFields,
+		nil,
+	)
+	if err1 != nil {
+		log.Println(constants.ErrExternalRequest, err1.Error())
+		return Country{}, fmt.Errorf(constants.ErrExternalRequest)
+	}
+
+	r.Header.Add("content-type", "application/json")
+
+	// Issue request
+	res, err2 := upstream.Countries.Do(r)
+	if err2 != nil {
+		log.Println(constants.ErrExternalResponse, err2.Error())
+		return Country{}, fmt.Errorf(constants.ErrExternalResponse)
+	}
+	defer res.Body.Close()
+
+	// Unknown countries are not found, with an error object instead of the country
+	if res.StatusCode == http.StatusNotFound {
+		log.Println(constants.ErrDashboardCountryNotFound, isoCode)
+		return Country{}, fmt.Errorf(constants.ErrDashboardCountryNotFound)
+	}
+	if !isSuccessful(res.StatusCode) {
+		log.Println(constants.ErrExternalResponse, res.Status)
+		return Country{}, fmt.Errorf(constants.ErrExternalResponse)
+	}
+
+	// Decode JSON
+	var country responses.ResponseFromRestcountries
+	err3 := json.NewDecoder(res.Body).Decode(&country)
+	if err3 != nil {
+		log.Println(constants.ErrJsonDecode, err3.Error())
+		return Country{}, fmt.Errorf(constants.ErrJsonDecode)
+	}
+
+	if country.Name.Common == "" {
+		log.Println(constants.ErrDashboardCountryNotFound)
+		return Country{}, fmt.Errorf(constants.ErrDashboardCountryNotFound)
+	}
+
+	converted := toCountry(country)
+	converted.Borders = p.borderNames(ctx, country.Borders)
+
+	return converted, nil
+}
+
+// Region returns the countries of the given region from the REST Countries API.
+func (p RestCountries) Region(ctx context.Context, region string) ([]Country, error) {
+	return p.countriesOf(ctx, "region/"+url.PathEscape(region))
+}
+
+// Subregion returns the countries of the given subregion from the REST Countries API.
+func (p RestCountries) Subregion(ctx context.Context, subregion string) ([]Country, error) {
+	return p.countriesOf(ctx, "subregion/"+url.PathEscape(subregion))
+}
+
+// countriesOf returns the names, ISO codes and areas of the countries at the given path of the REST Countries API.
+func (p RestCountries) countriesOf(ctx context.Context, path string) ([]Country, error) {
+	r, err1 := http.NewRequestWithContext(ctx, http.MethodGet, p.Api+path+"?fields=name,cca2,area", nil)
+	if err1 != nil {
+		log.Println(constants.ErrExternalRequest, err1.Error())
+		return nil, fmt.Errorf(constants.ErrExternalRequest)
+	}
+
+	r.Header.Add("content-type", "application/json")
+
+	res, err2 := upstream.Countries.Do(r)
+	if err2 != nil {
+		log.Println(constants.ErrExternalResponse, err2.Error())
+		return nil, fmt.Errorf(constants.ErrExternalResponse)
+	}
+	defer res.Body.Close()
+
+	// Unknown regions are not found, with an error object instead of a list of countries
+	if res.StatusCode == http.StatusNotFound {
+		log.Println(constants.ErrDashboardRegionNotFound, path)
+		return nil, fmt.Errorf(constants.ErrDashboardRegionNotFound)
+	}
+	if !isSuccessful(res.StatusCode) {
+		log.Println(constants.ErrExternalResponse, res.Status)
+		return nil, fmt.Errorf(constants.ErrExternalResponse)
+	}
+
+	var countries []responses.ResponseFromRestcountries
+	err3 := json.NewDecoder(res.Body).Decode(&countries)
+	if err3 != nil {
+		log.Println(constants.ErrJsonDecode, err3.Error())
+		return nil, fmt.Errorf(constants.ErrJsonDecode)
+	}
+	if len(countries) == 0 {
+		log.Println(constants.ErrDashboardRegionNotFound, path)
+		return nil, fmt.Errorf(constants.ErrDashboardRegionNotFound)
+	}
+
+	converted := make([]Country, 0, len(countries))
+	for _, country := range countries {
+		converted = append(converted, Country{Name: country.Name.Common, IsoCode: country.Cca2, Area: country.Area})
+	}
+	sort.Slice(
+		converted, func(i, j int) bool {
+			return converted[i].IsoCode < converted[j].IsoCode
+		},
+	)
+	return converted, nil
+}
+
+// borderNames resolves the codes of the bordering countries to their names, sorted by name. If they cannot be
+// resolved, the codes are returned instead, as the borders are not worth failing the dashboard for.
+func (p RestCountries) borderNames(ctx context.Context, codes []string) []string {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	r, err1 := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		p.Api+"alpha?codes="+strings.Join(codes, ",")+"&fields=name,cca3",
+		nil,
+	)
+	if err1 != nil {
+		log.Println(constants.ErrExternalRequest, err1.Error())
+		return codes
+	}
+
+	r.Header.Add("content-type", "application/json")
+
+	res, err2 := upstream.Countries.Do(r)
+	if err2 != nil {
+		log.Println(constants.ErrExternalResponse, err2.Error())
+		return codes
+	}
+	defer res.Body.Close()
+	if !isSuccessful(res.StatusCode) {
+		log.Println(constants.ErrExternalResponse, res.Status)
+		return codes
+	}
+
+	var borders []responses.ResponseFromRestcountries
+	err3 := json.NewDecoder(res.Body).Decode(&borders)
+	if err3 != nil {
+		log.Println(constants.ErrJsonDecode, err3.Error())
+		return codes
+	}
+
+	names := make(map[string]string, len(borders))
+	for _, border := range borders {
+		names[border.Cca3] = border.Name.Common
+	}
+
+	resolved := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if name, ok := names[code]; ok && name != "" {
+			resolved = append(resolved, name)
+		} else {
+			resolved = append(resolved, code)
+		}
+	}
+	sort.Strings(resolved)
+	return resolved
+}
+
+// toCountry converts a response from the REST Countries API. The currencies are sorted by code, as the API returns
+// them in no particular order, and so are the languages by name. The timezone is only known for countries with a
+// single timezone. The borders are left as codes.
+func toCountry(country responses.ResponseFromRestcountries) Country {
+	converted := Country{
+		Name:        country.Name.Common,
+		IsoCode:     country.Cca2,
+		Capitals:    country.Capital,
+		Population:  country.Population,
+		Area:        country.Area,
+		Borders:     country.Borders,
+		Region:      country.Region,
+		Subregion:   country.Subregion,
+		Timezones:   country.Timezones,
+		DrivingSide: country.Car.Side,
+		Flag: inhouse.Flag{
+			Emoji: country.Flag,
+			Png:   country.Flags.Png,
+			Svg:   country.Flags.Svg,
+		},
+	}
+
+	for _, language := range country.Languages {
+		converted.Languages = append(converted.Languages, language)
+	}
+	sort.Strings(converted.Languages)
+
+	// A calling code is the root followed by one of the suffixes, such as "+1" and "201" for the United States
+	for _, suffix := range country.Idd.Suffixes {
+		converted.CallingCodes = append(converted.CallingCodes, country.Idd.Root+suffix)
+	}
+	if len(country.Idd.Suffixes) == 0 && country.Idd.Root != "" {
+		converted.CallingCodes = []string{country.Idd.Root}
+	}
+
+	// The Gini index is by year of measurement, of which the latest is kept
+	for year, value := range country.Gini {
+		parsed, err := strconv.Atoi(year)
+		if err != nil {
+			continue
+		}
+		if converted.Gini == nil || parsed > converted.Gini.Year {
+			converted.Gini = &inhouse.Gini{Year: parsed, Value: value}
+		}
+	}
+
+	for code, translation := range country.Translations {
+		if tag, ok := restCountriesLanguages[code]; ok && translation.Common != "" {
+			if converted.Translations == nil {
+				converted.Translations = make(map[string]string)
+			}
+			converted.Translations[tag] = translation.Common
+		}
+	}
+
+	if len(country.Timezones) == 1 {
+		converted.Location = parseUtcOffset(country.Timezones[0])
+	}
+
+	converted.Coordinates = toCoordinates(country.Latlng)
+	converted.CapitalCoordinates = toCoordinates(country.CapitalInfo.Latlng)
+
+	for code, currency := range country.Currencies {
+		currency.Code = code
+		converted.Currencies = append(converted.Currencies, currency)
+	}
+	sort.Slice(
+		converted.Currencies, func(i, j int) bool {
+			return converted.Currencies[i].Code < converted.Currencies[j].Code
+		},
+	)
+
+	return converted
+}
+
+// parseUtcOffset returns a fixed timezone for a timezone of the REST Countries API, such as "UTC+01:00", or nil if it
+// is not in that format.
+func parseUtcOffset(timezone string) *time.Location {
+	if timezone == "UTC" {
+		return time.UTC
+	}
+	offset, err := time.Parse("UTC-07:00", timezone)
+	if err != nil {
+		return nil
+	}
+	_, seconds := offset.Zone()
+	return time.FixedZone(timezone, seconds)
+}
+
+// toCoordinates converts a latitude and longitude pair from the REST Countries API, or returns nil if it is not a pair.
+func toCoordinates(latlng []float64) *inhouse.Coordinates {
+	if len(latlng) != 2 {
+		return nil
+	}
+	return &inhouse.Coordinates{
+		Latitude:  latlng[0],
+		Longitude: latlng[1],
+	}
+}
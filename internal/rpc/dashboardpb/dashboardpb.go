@@ -0,0 +1,5 @@
+// Package dashboardpb contains the protobuf messages and gRPC services of the dashboard API, generated from
+// dashboard.proto.
+package dashboardpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative dashboard.proto
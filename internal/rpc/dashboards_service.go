@@ -0,0 +1,266 @@
+package rpc
+
+import (
+	"assignment-2/internal/db"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/http/datatransfers/responses"
+	"assignment-2/internal/http/handlers/dashboards"
+	"assignment-2/internal/rpc/dashboardpb"
+	"assignment-2/internal/utils"
+	"context"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// dashboardService retrieves populated dashboards, as the /dashboard/v1/dashboards/ endpoint.
+type dashboardService struct {
+	dashboardpb.UnimplementedDashboardServiceServer
+}
+
+// GetDashboard returns the populated dashboard of the registration with the given ID.
+func (s *dashboardService) GetDashboard(
+	ctx context.Context,
+	req *dashboardpb.GetDashboardRequest,
+) (*dashboardpb.Dashboard, error) {
+	options := dashboards.Options{Units: req.GetUnits(), Amount: req.Amount}
+	if req.GetLanguage() != "" {
+		options.Language = utils.NegotiateLanguage(req.GetLanguage())
+	}
+	dashboard, err := dashboards.GetDashboard(ctx, req.GetId(), options)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toDashboard(req.GetId(), dashboard), nil
+}
+
+// WatchDashboard streams the populated dashboard whenever it is refreshed or the registration changes, sharing the
+// live dashboard with the WebSocket subscribers. The stream ends when the registration is deleted.
+func (s *dashboardService) WatchDashboard(
+	req *dashboardpb.WatchDashboardRequest,
+	stream dashboardpb.DashboardService_WatchDashboardServer,
+) error {
+	// Check that the registration exists before subscribing
+	_, err := getDocument[requests.DashboardConfig](req.GetId(), db.DashboardCollection)
+	if err != nil {
+		return err
+	}
+
+	updates, latest, unsubscribe := dashboards.SubscribeLive(req.GetId())
+	defer unsubscribe()
+
+	// Send the current dashboard right away if it has already been populated for another subscriber
+	if latest != nil {
+		if err := stream.Send(toDashboard(req.GetId(), *latest)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case update, ok := <-updates:
+			if !ok {
+				// The registration has been deleted
+				return nil
+			}
+			if err := stream.Send(toDashboard(req.GetId(), update)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toDashboard converts a populated dashboard to its protobuf message.
+func toDashboard(id string, dashboard dashboards.Dashboard) *dashboardpb.Dashboard {
+	features := &dashboardpb.DashboardFeatures{
+		Temperature:      dashboard.Features.Temperature,
+		Precipitation:    dashboard.Features.Precipitation,
+		TemperatureMin:   dashboard.Features.TemperatureMin,
+		TemperatureMax:   dashboard.Features.TemperatureMax,
+		WindSpeed:        dashboard.Features.WindSpeed,
+		WindGusts:        dashboard.Features.WindGusts,
+		Humidity:         dashboard.Features.Humidity,
+		CloudCover:       dashboard.Features.CloudCover,
+		UvIndex:          dashboard.Features.UvIndex,
+		Capital:          dashboard.Features.Capital,
+		Area:             dashboard.Features.Area,
+		TargetCurrencies: dashboard.Features.TargetCurrencies,
+		Currency:         toCurrency(dashboard.Features.Currency),
+		Capitals:         dashboard.Features.Capitals,
+		Languages:        dashboard.Features.Languages,
+		Borders:          dashboard.Features.Borders,
+		Region:           dashboard.Features.Region,
+		Subregion:        dashboard.Features.Subregion,
+		Timezones:        dashboard.Features.Timezones,
+		CallingCodes:     dashboard.Features.CallingCodes,
+		DrivingSide:      dashboard.Features.DrivingSide,
+	}
+	if dashboard.Features.Flag != nil {
+		features.Flag = &dashboardpb.Flag{
+			Emoji: dashboard.Features.Flag.Emoji,
+			Png:   dashboard.Features.Flag.Png,
+			Svg:   dashboard.Features.Flag.Svg,
+		}
+	}
+	if dashboard.Features.Gini != nil {
+		features.Gini = &dashboardpb.Gini{
+			Year:  int32(dashboard.Features.Gini.Year),
+			Value: dashboard.Features.Gini.Value,
+		}
+	}
+	for _, currency := range dashboard.Features.Currencies {
+		features.Currencies = append(features.Currencies, toCurrency(currency))
+	}
+	features.InverseRates = dashboard.Features.InverseRates
+	if len(dashboard.Features.CrossRates) > 0 {
+		features.CrossRates = make(map[string]*dashboardpb.ExchangeRates, len(dashboard.Features.CrossRates))
+		for from, rates := range dashboard.Features.CrossRates {
+			features.CrossRates[from] = &dashboardpb.ExchangeRates{Rates: rates}
+		}
+	}
+	features.Amount = dashboard.Features.Amount
+	features.ConvertedAmounts = dashboard.Features.ConvertedAmounts
+	features.Computed = dashboard.Features.Computed
+	if len(dashboard.Features.ExchangeRates) > 0 {
+		features.ExchangeRates = make(map[string]*dashboardpb.ExchangeRates, len(dashboard.Features.ExchangeRates))
+		for base, rates := range dashboard.Features.ExchangeRates {
+			features.ExchangeRates[base] = &dashboardpb.ExchangeRates{Rates: rates}
+		}
+	}
+	if dashboard.Features.Coordinates != nil {
+		features.Coordinates = &dashboardpb.Coordinates{
+			Latitude:  dashboard.Features.Coordinates.Latitude,
+			Longitude: dashboard.Features.Coordinates.Longitude,
+		}
+	}
+	if dashboard.Features.Population != nil {
+		population := int64(*dashboard.Features.Population)
+		features.Population = &population
+	}
+	features.Forecast = toForecast(dashboard.Features.Forecast)
+	if dashboard.Features.WeatherLocation != nil {
+		features.WeatherLocation = toWeatherLocation(*dashboard.Features.WeatherLocation)
+	}
+	for _, point := range dashboard.Features.WeatherPoints {
+		features.WeatherPoints = append(
+			features.WeatherPoints, &dashboardpb.PointWeather{
+				Coordinates: &dashboardpb.Coordinates{
+					Latitude:  point.Coordinates.Latitude,
+					Longitude: point.Coordinates.Longitude,
+				},
+				Temperature:    point.Temperature,
+				Precipitation:  point.Precipitation,
+				TemperatureMin: point.TemperatureMin,
+				TemperatureMax: point.TemperatureMax,
+				WindSpeed:      point.WindSpeed,
+				WindGusts:      point.WindGusts,
+				Humidity:       point.Humidity,
+				CloudCover:     point.CloudCover,
+				UvIndex:        point.UvIndex,
+				Forecast:       toForecast(point.Forecast),
+			},
+		)
+	}
+
+	var dashboardErrors map[string]*dashboardpb.DashboardError
+	if dashboard.IsPartial() {
+		dashboardErrors = make(map[string]*dashboardpb.DashboardError, len(dashboard.Errors))
+		for source, dashboardError := range dashboard.Errors {
+			dashboardErrors[source] = &dashboardpb.DashboardError{
+				Features: dashboardError.Features,
+				Message:  dashboardError.Message,
+			}
+		}
+	}
+
+	var localRetrieval string
+	if dashboard.LocalRetrieval != nil {
+		localRetrieval = dashboard.LocalRetrieval.Format(time.RFC3339Nano)
+	}
+
+	var members []*dashboardpb.Dashboard
+	for _, member := range dashboard.Members {
+		members = append(members, toDashboard("", member))
+	}
+
+	var aggregates *dashboardpb.RegionAggregates
+	if dashboard.Aggregates != nil {
+		aggregates = &dashboardpb.RegionAggregates{
+			Countries:     int32(dashboard.Aggregates.Countries),
+			Area:          dashboard.Aggregates.Area,
+			Temperature:   dashboard.Aggregates.Temperature,
+			BaseCurrency:  dashboard.Aggregates.BaseCurrency,
+			ExchangeRates: dashboard.Aggregates.ExchangeRates,
+		}
+		if dashboard.Aggregates.Population != nil {
+			population := int64(*dashboard.Aggregates.Population)
+			aggregates.Population = &population
+		}
+	}
+
+	var delta *dashboardpb.DashboardDelta
+	if dashboard.Delta != nil {
+		delta = &dashboardpb.DashboardDelta{
+			Since:   timestamppb.New(dashboard.Delta.Since),
+			Changes: dashboard.Delta.Changes,
+		}
+	}
+
+	var units *dashboardpb.DashboardUnits
+	if dashboard.Units != nil {
+		units = &dashboardpb.DashboardUnits{
+			Temperature:   dashboard.Units.Temperature,
+			Precipitation: dashboard.Units.Precipitation,
+			WindSpeed:     dashboard.Units.WindSpeed,
+			Area:          dashboard.Units.Area,
+		}
+	}
+
+	return &dashboardpb.Dashboard{
+		Id:             id,
+		Country:        dashboard.Country,
+		IsoCode:        dashboard.IsoCode,
+		Features:       features,
+		LastRetrieval:  timestamppb.New(dashboard.LastRetrieval),
+		Errors:         dashboardErrors,
+		Timezone:       dashboard.Timezone,
+		LocalRetrieval: localRetrieval,
+		Members:        members,
+		Aggregates:     aggregates,
+		Delta:          delta,
+		Units:          units,
+		Language:       dashboard.Language,
+	}
+}
+
+// toForecast converts the days of a weather forecast to their protobuf messages.
+func toForecast(forecast []dashboards.ForecastDay) []*dashboardpb.ForecastDay {
+	var converted []*dashboardpb.ForecastDay
+	for _, day := range forecast {
+		converted = append(
+			converted, &dashboardpb.ForecastDay{
+				Date:           day.Date,
+				Temperature:    day.Temperature,
+				Precipitation:  day.Precipitation,
+				TemperatureMin: day.TemperatureMin,
+				TemperatureMax: day.TemperatureMax,
+				WindSpeed:      day.WindSpeed,
+				WindGusts:      day.WindGusts,
+				Humidity:       day.Humidity,
+				CloudCover:     day.CloudCover,
+				UvIndex:        day.UvIndex,
+			},
+		)
+	}
+	return converted
+}
+
+// toCurrency converts a currency to its protobuf message.
+func toCurrency(currency responses.Currency) *dashboardpb.Currency {
+	return &dashboardpb.Currency{
+		Code:   currency.Code,
+		Name:   currency.Name,
+		Symbol: currency.Symbol,
+	}
+}
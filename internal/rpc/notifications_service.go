@@ -0,0 +1,99 @@
+package rpc
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/http/handlers/notifications"
+	"assignment-2/internal/rpc/dashboardpb"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log"
+)
+
+// notificationService manages webhooks, as the /dashboard/v1/notifications/ endpoint.
+type notificationService struct {
+	dashboardpb.UnimplementedNotificationServiceServer
+}
+
+// CreateNotification registers a new webhook.
+func (s *notificationService) CreateNotification(
+	_ context.Context,
+	req *dashboardpb.CreateNotificationRequest,
+) (*dashboardpb.Notification, error) {
+	notification, err := notifications.CreateNotification(
+		requests.Notification{
+			Url:     req.GetUrl(),
+			Country: req.GetCountry(),
+			Event:   req.GetEvent(),
+		},
+	)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toNotification(notification), nil
+}
+
+// GetNotification returns the webhook with the given ID.
+func (s *notificationService) GetNotification(
+	_ context.Context,
+	req *dashboardpb.GetNotificationRequest,
+) (*dashboardpb.Notification, error) {
+	notification, err := getDocument[requests.Notification](req.GetId(), db.NotificationCollection)
+	if err != nil {
+		return nil, err
+	}
+	return toNotification(notification), nil
+}
+
+// ListNotifications returns all webhooks.
+func (s *notificationService) ListNotifications(
+	_ context.Context,
+	_ *dashboardpb.ListNotificationsRequest,
+) (*dashboardpb.ListNotificationsResponse, error) {
+	allDocuments, err := db.GetAllDocuments[requests.Notification](db.NotificationCollection)
+	if err != nil {
+		log.Println(constants.ErrDBGetDoc + err.Error())
+		return nil, status.Error(codes.Internal, constants.ErrDBGetDoc)
+	}
+
+	response := &dashboardpb.ListNotificationsResponse{}
+	for _, notification := range allDocuments {
+		response.Notifications = append(response.Notifications, toNotification(notification))
+	}
+	return response, nil
+}
+
+// DeleteNotification deletes the webhook with the given ID.
+func (s *notificationService) DeleteNotification(
+	_ context.Context,
+	req *dashboardpb.DeleteNotificationRequest,
+) (*emptypb.Empty, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, constants.ErrIDNotProvided)
+	}
+
+	err := db.DeleteDocument(req.GetId(), db.NotificationCollection)
+	if err != nil {
+		log.Println(constants.ErrDBDeleteDoc + err.Error())
+		return nil, status.Error(codes.Internal, constants.ErrDBDeleteDoc)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// toNotification converts a webhook to its protobuf message.
+func toNotification(notification requests.Notification) *dashboardpb.Notification {
+	message := &dashboardpb.Notification{
+		Id:      notification.ID,
+		Url:     notification.Url,
+		Country: notification.Country,
+		Event:   notification.Event,
+	}
+	if notification.LastInvoke != nil {
+		message.LastInvoke = timestamppb.New(*notification.LastInvoke)
+	}
+	return message
+}
@@ -0,0 +1,292 @@
+package rpc
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/http/datatransfers/inhouse"
+	"assignment-2/internal/http/datatransfers/requests"
+	"assignment-2/internal/http/handlers/registrations"
+	"assignment-2/internal/rpc/dashboardpb"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log"
+)
+
+// registrationService manages dashboard configurations, as the /dashboard/v1/registrations/ endpoint.
+type registrationService struct {
+	dashboardpb.UnimplementedRegistrationServiceServer
+}
+
+// CreateRegistration registers a new dashboard configuration.
+func (s *registrationService) CreateRegistration(
+	_ context.Context,
+	req *dashboardpb.CreateRegistrationRequest,
+) (*dashboardpb.Registration, error) {
+	registration, err := registrations.CreateRegistration(
+		requests.DashboardConfig{
+			Country:   req.GetCountry(),
+			IsoCode:   req.GetIsoCode(),
+			Region:    req.GetRegion(),
+			Subregion: req.GetSubregion(),
+			IsoCodes:  req.GetIsoCodes(),
+			Features:  fromConfigFeatures(req.GetFeatures()),
+			Units:     fromUnits(req.GetUnits()),
+			Precision: fromPrecision(req.Precision),
+			Computed:  fromComputed(req.GetComputed()),
+		},
+	)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toRegistration(registration), nil
+}
+
+// GetRegistration returns the dashboard configuration with the given ID.
+func (s *registrationService) GetRegistration(
+	_ context.Context,
+	req *dashboardpb.GetRegistrationRequest,
+) (*dashboardpb.Registration, error) {
+	registration, err := getDocument[requests.DashboardConfig](req.GetId(), db.DashboardCollection)
+	if err != nil {
+		return nil, err
+	}
+	return toRegistration(registration), nil
+}
+
+// ListRegistrations returns all dashboard configurations.
+func (s *registrationService) ListRegistrations(
+	_ context.Context,
+	_ *dashboardpb.ListRegistrationsRequest,
+) (*dashboardpb.ListRegistrationsResponse, error) {
+	allDocuments, err := db.GetAllDocuments[requests.DashboardConfig](db.DashboardCollection)
+	if err != nil {
+		log.Println(constants.ErrDBGetDoc + err.Error())
+		return nil, status.Error(codes.Internal, constants.ErrDBGetDoc)
+	}
+
+	response := &dashboardpb.ListRegistrationsResponse{}
+	for _, registration := range allDocuments {
+		response.Registrations = append(response.Registrations, toRegistration(registration))
+	}
+	return response, nil
+}
+
+// UpdateRegistration replaces the dashboard configuration with the given ID.
+func (s *registrationService) UpdateRegistration(
+	_ context.Context,
+	req *dashboardpb.UpdateRegistrationRequest,
+) (*dashboardpb.Registration, error) {
+	registration, err := registrations.UpdateRegistration(
+		req.GetId(),
+		requests.DashboardConfig{
+			Country:   req.GetCountry(),
+			IsoCode:   req.GetIsoCode(),
+			Region:    req.GetRegion(),
+			Subregion: req.GetSubregion(),
+			IsoCodes:  req.GetIsoCodes(),
+			Features:  fromConfigFeatures(req.GetFeatures()),
+			Units:     fromUnits(req.GetUnits()),
+			Precision: fromPrecision(req.Precision),
+			Computed:  fromComputed(req.GetComputed()),
+		},
+	)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toRegistration(registration), nil
+}
+
+// DeleteRegistration deletes the dashboard configuration with the given ID.
+func (s *registrationService) DeleteRegistration(
+	_ context.Context,
+	req *dashboardpb.DeleteRegistrationRequest,
+) (*emptypb.Empty, error) {
+	if err := registrations.DeleteRegistration(req.GetId()); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// toRegistration converts a dashboard configuration to its protobuf message.
+func toRegistration(config requests.DashboardConfig) *dashboardpb.Registration {
+	return &dashboardpb.Registration{
+		Id:        config.ID,
+		Country:   config.Country,
+		IsoCode:   config.IsoCode,
+		Region:    config.Region,
+		Subregion: config.Subregion,
+		IsoCodes:  config.IsoCodes,
+		Units: &dashboardpb.Units{
+			System:        config.Units.System,
+			Temperature:   config.Units.Temperature,
+			Precipitation: config.Units.Precipitation,
+			WindSpeed:     config.Units.WindSpeed,
+			Area:          config.Units.Area,
+		},
+		Precision: toPrecision(config.Precision),
+		Computed:  toComputed(config.Computed),
+		Features: &dashboardpb.ConfigFeatures{
+			Temperature:      config.Features.Temperature,
+			Precipitation:    config.Features.Precipitation,
+			Capital:          config.Features.Capital,
+			Coordinates:      config.Features.Coordinates,
+			Population:       config.Features.Population,
+			Area:             config.Features.Area,
+			TargetCurrencies: config.Features.TargetCurrencies,
+			TemperatureMin:   config.Features.TemperatureMin,
+			TemperatureMax:   config.Features.TemperatureMax,
+			WindSpeed:        config.Features.WindSpeed,
+			WindGusts:        config.Features.WindGusts,
+			Humidity:         config.Features.Humidity,
+			CloudCover:       config.Features.CloudCover,
+			UvIndex:          config.Features.UvIndex,
+			ForecastDays:     int32(config.Features.ForecastDays),
+			WeatherLocation:  toWeatherLocation(config.Features.WeatherLocation),
+			PrimaryCapital:   config.Features.PrimaryCapital,
+			PrimaryCurrency:  config.Features.PrimaryCurrency,
+			Languages:        config.Features.Languages,
+			Borders:          config.Features.Borders,
+			Region:           config.Features.Region,
+			Flag:             config.Features.Flag,
+			Timezones:        config.Features.Timezones,
+			CallingCodes:     config.Features.CallingCodes,
+			DrivingSide:      config.Features.DrivingSide,
+			Gini:             config.Features.Gini,
+			BaseCurrency:     config.Features.BaseCurrency,
+		},
+		LastChange: timestamppb.New(config.LastChange),
+	}
+}
+
+// fromConfigFeatures converts the protobuf message of the enabled features, which may be nil.
+func fromConfigFeatures(features *dashboardpb.ConfigFeatures) requests.ConfigFeatures {
+	return requests.ConfigFeatures{
+		Temperature:      features.GetTemperature(),
+		Precipitation:    features.GetPrecipitation(),
+		Capital:          features.GetCapital(),
+		Coordinates:      features.GetCoordinates(),
+		Population:       features.GetPopulation(),
+		Area:             features.GetArea(),
+		TargetCurrencies: features.GetTargetCurrencies(),
+		TemperatureMin:   features.GetTemperatureMin(),
+		TemperatureMax:   features.GetTemperatureMax(),
+		WindSpeed:        features.GetWindSpeed(),
+		WindGusts:        features.GetWindGusts(),
+		Humidity:         features.GetHumidity(),
+		CloudCover:       features.GetCloudCover(),
+		UvIndex:          features.GetUvIndex(),
+		ForecastDays:     int(features.GetForecastDays()),
+		WeatherLocation:  fromWeatherLocation(features.GetWeatherLocation()),
+		PrimaryCapital:   features.GetPrimaryCapital(),
+		PrimaryCurrency:  features.GetPrimaryCurrency(),
+		Languages:        features.GetLanguages(),
+		Borders:          features.GetBorders(),
+		Region:           features.GetRegion(),
+		Flag:             features.GetFlag(),
+		Timezones:        features.GetTimezones(),
+		CallingCodes:     features.GetCallingCodes(),
+		DrivingSide:      features.GetDrivingSide(),
+		Gini:             features.GetGini(),
+		BaseCurrency:     features.GetBaseCurrency(),
+	}
+}
+
+// toWeatherLocation converts where the weather of a dashboard is measured to its protobuf message.
+func toWeatherLocation(location requests.WeatherLocation) *dashboardpb.WeatherLocation {
+	converted := &dashboardpb.WeatherLocation{
+		Source:   location.Source,
+		Separate: location.Separate,
+	}
+	for _, point := range location.Points {
+		converted.Points = append(
+			converted.Points, &dashboardpb.Coordinates{
+				Latitude:  point.Latitude,
+				Longitude: point.Longitude,
+			},
+		)
+	}
+	return converted
+}
+
+// fromWeatherLocation converts the protobuf message of where the weather is measured, which may be nil.
+func fromWeatherLocation(location *dashboardpb.WeatherLocation) requests.WeatherLocation {
+	converted := requests.WeatherLocation{
+		Source:   location.GetSource(),
+		Separate: location.GetSeparate(),
+	}
+	for _, point := range location.GetPoints() {
+		converted.Points = append(
+			converted.Points, inhouse.Coordinates{
+				Latitude:  point.GetLatitude(),
+				Longitude: point.GetLongitude(),
+			},
+		)
+	}
+	return converted
+}
+
+// fromUnits converts the units of a registration from their protobuf message.
+func fromUnits(units *dashboardpb.Units) requests.Units {
+	return requests.Units{
+		System:        units.GetSystem(),
+		Temperature:   units.GetTemperature(),
+		Precipitation: units.GetPrecipitation(),
+		WindSpeed:     units.GetWindSpeed(),
+		Area:          units.GetArea(),
+	}
+}
+
+// fromPrecision converts the precision of a registration from its protobuf field, which is unset if not given.
+func fromPrecision(precision *int32) *int {
+	if precision == nil {
+		return nil
+	}
+	converted := int(*precision)
+	return &converted
+}
+
+// toPrecision converts the precision of a registration to its protobuf field.
+func toPrecision(precision *int) *int32 {
+	if precision == nil {
+		return nil
+	}
+	converted := int32(*precision)
+	return &converted
+}
+
+// fromComputed converts the computed features of a registration from their protobuf messages.
+func fromComputed(computed []*dashboardpb.ComputedFeature) []requests.ComputedFeature {
+	var converted []requests.ComputedFeature
+	for _, feature := range computed {
+		converted = append(
+			converted, requests.ComputedFeature{
+				Name:       feature.GetName(),
+				Builtin:    feature.GetBuiltin(),
+				Expression: feature.GetExpression(),
+				Currency:   feature.GetCurrency(),
+				Reference:  feature.GetReference(),
+			},
+		)
+	}
+	return converted
+}
+
+// toComputed converts the computed features of a registration to their protobuf messages.
+func toComputed(computed []requests.ComputedFeature) []*dashboardpb.ComputedFeature {
+	var converted []*dashboardpb.ComputedFeature
+	for _, feature := range computed {
+		converted = append(
+			converted, &dashboardpb.ComputedFeature{
+				Name:       feature.Name,
+				Builtin:    feature.Builtin,
+				Expression: feature.Expression,
+				Currency:   feature.Currency,
+				Reference:  feature.Reference,
+			},
+		)
+	}
+	return converted
+}
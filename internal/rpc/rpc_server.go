@@ -0,0 +1,76 @@
+// Package rpc serves the dashboard API over gRPC, alongside the REST endpoints. The services share the business logic
+// of the HTTP handlers.
+package rpc
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/db"
+	"assignment-2/internal/rpc/dashboardpb"
+	"assignment-2/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"net"
+)
+
+// NewServer creates a gRPC server with all services registered.
+func NewServer() *grpc.Server {
+	server := grpc.NewServer()
+	dashboardpb.RegisterRegistrationServiceServer(server, &registrationService{})
+	dashboardpb.RegisterDashboardServiceServer(server, &dashboardService{})
+	dashboardpb.RegisterNotificationServiceServer(server, &notificationService{})
+	dashboardpb.RegisterStatusServiceServer(server, &statusService{})
+	return server
+}
+
+// Start
+/*
+Start the gRPC server on the port specified in the environment variable GRPC_PORT. If GRPC_PORT is not set, the default
+port 9000 is used. The database must be initialized before the server is started.
+*/
+func Start() {
+	port := utils.GetGrpcPort()
+
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("Failed to listen on gRPC port %s: %v", port, err)
+	}
+
+	log.Println("Starting gRPC server on port " + port + " ...")
+	log.Fatal(NewServer().Serve(listener))
+}
+
+// toStatusError converts an error from the handlers, whose message is safe to show to the client, to a gRPC status
+// error with a matching code.
+func toStatusError(err error) error {
+	switch err.Error() {
+	case constants.ErrIDInvalid, constants.ErrIDNotProvided, constants.ErrNotificationsInvalidType,
+		constants.ErrRegistrationForecastDays, constants.ErrRegistrationWeatherSource,
+		constants.ErrRegistrationWeatherPoints, constants.ErrRegistrationTarget, constants.ErrRegistrationIsoCodes,
+		constants.ErrRegistrationUnits, constants.ErrRegistrationPrecision, constants.ErrDashboardUnits,
+		constants.ErrDashboardAmount, constants.ErrRegistrationComputed, constants.ErrRegistrationComputedRegional:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case constants.ErrDBDocNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case constants.ErrDashboardTimeout:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// getDocument gets the document with the given ID, returning a gRPC status error if it fails.
+func getDocument[T any](id string, collection string) (T, error) {
+	document, err := db.GetDocument[T](id, collection)
+	if err != nil {
+		log.Println(constants.ErrDBGetDoc + err.Error())
+		switch err.Error() {
+		case constants.ErrIDInvalid, constants.ErrDBDocNotFound:
+			return document, toStatusError(err)
+		default:
+			return document, status.Error(codes.Internal, constants.ErrDBGetDoc)
+		}
+	}
+	return document, nil
+}
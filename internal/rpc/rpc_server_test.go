@@ -0,0 +1,237 @@
+package rpc
+
+import (
+	"assignment-2/internal/mock"
+	"assignment-2/internal/rpc/dashboardpb"
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"log"
+	"net"
+	"testing"
+	"time"
+)
+
+// conn is the client connection to the in-process server
+var conn *grpc.ClientConn
+
+func TestMain(m *testing.M) {
+	// Setup function
+	log.Println("Setup for testing")
+	mock.InitForTesting()
+
+	// Serve the gRPC server in-process over an in-memory listener
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer()
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Println("gRPC server stopped: ", err.Error())
+		}
+	}()
+
+	var err error
+	conn, err = grpc.DialContext(
+		context.Background(), "bufnet",
+		grpc.WithContextDialer(
+			func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			},
+		),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		log.Fatalf("Failed to dial in-process gRPC server: %v", err)
+	}
+
+	// Run tests
+	m.Run()
+
+	// Teardown function
+	log.Println("Teardown for testing")
+	_ = conn.Close()
+	server.Stop()
+	mock.TeardownAfterTesting()
+}
+
+func TestRegistrationService(t *testing.T) {
+	client := dashboardpb.NewRegistrationServiceClient(conn)
+	ctx := context.Background()
+
+	created, err := client.CreateRegistration(
+		ctx, &dashboardpb.CreateRegistrationRequest{
+			Country:  "Norway",
+			IsoCode:  "NO",
+			Features: &dashboardpb.ConfigFeatures{Capital: true, TargetCurrencies: []string{"EUR"}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("CreateRegistration() error = %v", err)
+	}
+	if created.GetId() == "" || created.GetLastChange() == nil {
+		t.Errorf("CreateRegistration() = %v, want ID and last change", created)
+	}
+
+	got, err := client.GetRegistration(ctx, &dashboardpb.GetRegistrationRequest{Id: created.GetId()})
+	if err != nil || got.GetCountry() != "Norway" || !got.GetFeatures().GetCapital() {
+		t.Errorf("GetRegistration() = %v, %v, want the created registration", got, err)
+	}
+
+	list, err := client.ListRegistrations(ctx, &dashboardpb.ListRegistrationsRequest{})
+	if err != nil || len(list.GetRegistrations()) == 0 {
+		t.Errorf("ListRegistrations() = %v, %v, want at least one registration", list, err)
+	}
+
+	updated, err := client.UpdateRegistration(
+		ctx, &dashboardpb.UpdateRegistrationRequest{
+			Id:       created.GetId(),
+			Country:  "Sweden",
+			IsoCode:  "SE",
+			Features: &dashboardpb.ConfigFeatures{Area: true},
+		},
+	)
+	if err != nil || updated.GetCountry() != "Sweden" || !updated.GetFeatures().GetArea() {
+		t.Errorf("UpdateRegistration() = %v, %v, want the updated registration", updated, err)
+	}
+
+	_, err = client.DeleteRegistration(ctx, &dashboardpb.DeleteRegistrationRequest{Id: created.GetId()})
+	if err != nil {
+		t.Errorf("DeleteRegistration() error = %v", err)
+	}
+
+	_, err = client.GetRegistration(ctx, &dashboardpb.GetRegistrationRequest{Id: created.GetId()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetRegistration() after delete error = %v, want %v", err, codes.NotFound)
+	}
+}
+
+func TestRegistrationService_InvalidArgument(t *testing.T) {
+	client := dashboardpb.NewRegistrationServiceClient(conn)
+
+	_, err := client.GetRegistration(context.Background(), &dashboardpb.GetRegistrationRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetRegistration() without ID error = %v, want %v", err, codes.InvalidArgument)
+	}
+}
+
+func TestDashboardService(t *testing.T) {
+	registrationClient := dashboardpb.NewRegistrationServiceClient(conn)
+	client := dashboardpb.NewDashboardServiceClient(conn)
+	ctx := context.Background()
+
+	registration, err := registrationClient.CreateRegistration(
+		ctx, &dashboardpb.CreateRegistrationRequest{
+			Country:  "Norway",
+			IsoCode:  "NO",
+			Features: &dashboardpb.ConfigFeatures{Temperature: true, Capital: true, TargetCurrencies: []string{"EUR"}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("CreateRegistration() error = %v", err)
+	}
+
+	dashboard, err := client.GetDashboard(ctx, &dashboardpb.GetDashboardRequest{Id: registration.GetId()})
+	if err != nil {
+		t.Fatalf("GetDashboard() error = %v", err)
+	}
+	if dashboard.GetFeatures().GetCapital() != "Oslo" || dashboard.GetFeatures().Temperature == nil {
+		t.Errorf("GetDashboard() = %v, want capital and temperature", dashboard)
+	}
+	if dashboard.GetFeatures().Area != nil {
+		t.Errorf("GetDashboard() area = %v, want it unset as the feature is not enabled", dashboard.GetFeatures().Area)
+	}
+	if _, ok := dashboard.GetFeatures().GetTargetCurrencies()["EUR"]; !ok {
+		t.Errorf("GetDashboard() target currencies = %v, want EUR", dashboard.GetFeatures().GetTargetCurrencies())
+	}
+
+	// Watch the dashboard until the registration is deleted
+	watchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	stream, err := client.WatchDashboard(watchCtx, &dashboardpb.WatchDashboardRequest{Id: registration.GetId()})
+	if err != nil {
+		t.Fatalf("WatchDashboard() error = %v", err)
+	}
+
+	update, err := stream.Recv()
+	if err != nil || update.GetId() != registration.GetId() || update.GetFeatures().GetCapital() != "Oslo" {
+		t.Errorf("WatchDashboard() first update = %v, %v, want the populated dashboard", update, err)
+	}
+
+	_, err = registrationClient.DeleteRegistration(ctx, &dashboardpb.DeleteRegistrationRequest{Id: registration.GetId()})
+	if err != nil {
+		t.Fatalf("DeleteRegistration() error = %v", err)
+	}
+
+	// The stream ends when the registration is deleted
+	for {
+		_, err = stream.Recv()
+		if err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("WatchDashboard() after delete error = %v, want end of stream", err)
+	}
+
+	_, err = client.GetDashboard(ctx, &dashboardpb.GetDashboardRequest{Id: registration.GetId()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetDashboard() after delete error = %v, want %v", err, codes.NotFound)
+	}
+}
+
+func TestNotificationService(t *testing.T) {
+	client := dashboardpb.NewNotificationServiceClient(conn)
+	ctx := context.Background()
+
+	_, err := client.CreateNotification(
+		ctx, &dashboardpb.CreateNotificationRequest{
+			Url:   "http://localhost:8080/client/",
+			Event: "INVALID_EVENT",
+		},
+	)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CreateNotification() with invalid event error = %v, want %v", err, codes.InvalidArgument)
+	}
+
+	created, err := client.CreateNotification(
+		ctx, &dashboardpb.CreateNotificationRequest{
+			Url:     "http://localhost:8080/client/",
+			Country: "NO",
+			Event:   "INVOKE",
+		},
+	)
+	if err != nil || created.GetId() == "" {
+		t.Fatalf("CreateNotification() = %v, %v, want a notification with ID", created, err)
+	}
+
+	got, err := client.GetNotification(ctx, &dashboardpb.GetNotificationRequest{Id: created.GetId()})
+	if err != nil || got.GetEvent() != "INVOKE" || got.GetCountry() != "NO" {
+		t.Errorf("GetNotification() = %v, %v, want the created notification", got, err)
+	}
+
+	list, err := client.ListNotifications(ctx, &dashboardpb.ListNotificationsRequest{})
+	if err != nil || len(list.GetNotifications()) == 0 {
+		t.Errorf("ListNotifications() = %v, %v, want at least one notification", list, err)
+	}
+
+	_, err = client.DeleteNotification(ctx, &dashboardpb.DeleteNotificationRequest{Id: created.GetId()})
+	if err != nil {
+		t.Errorf("DeleteNotification() error = %v", err)
+	}
+}
+
+func TestStatusService(t *testing.T) {
+	client := dashboardpb.NewStatusServiceClient(conn)
+
+	got, err := client.GetStatus(context.Background(), &dashboardpb.GetStatusRequest{})
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if got.GetVersion() == "" {
+		t.Errorf("GetStatus() = %v, want a version", got)
+	}
+}
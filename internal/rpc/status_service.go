@@ -0,0 +1,40 @@
+package rpc
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/http/handlers/status"
+	"assignment-2/internal/rpc/dashboardpb"
+	"context"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"log"
+)
+
+// statusService reports the status of the service, as the /dashboard/v1/status/ endpoint.
+type statusService struct {
+	dashboardpb.UnimplementedStatusServiceServer
+}
+
+// GetStatus returns the status of the service and the APIs it relies on.
+func (s *statusService) GetStatus(
+	_ context.Context,
+	_ *dashboardpb.GetStatusRequest,
+) (*dashboardpb.Status, error) {
+	currentStatus, err := status.GetStatus()
+	if err != nil {
+		log.Println(constants.ErrDBCount, err.Error())
+		return nil, grpcstatus.Error(codes.Internal, constants.ErrDBCount)
+	}
+
+	return &dashboardpb.Status{
+		CountriesApi:   int32(currentStatus.CountriesAPI),
+		MeteoApi:       int32(currentStatus.MeteoAPI),
+		CurrencyApi:    int32(currentStatus.CurrencyAPI),
+		DashboardDb:    int32(currentStatus.DashboardDB),
+		NotificationDb: int32(currentStatus.NotificationDB),
+		Dashboards:     int32(currentStatus.Dashboards),
+		Webhooks:       int32(currentStatus.Webhooks),
+		Version:        currentStatus.Version,
+		Uptime:         int64(currentStatus.Uptime),
+	}, nil
+}
@@ -0,0 +1,115 @@
+package upstream
+
+import (
+	"sync"
+	"time"
+)
+
+// breakerState is the state of a circuit breaker.
+type breakerState int
+
+const (
+	// closed lets every request through.
+	closed breakerState = iota
+	// open rejects every request, until the cooldown has passed.
+	open
+	// halfOpen lets a single trial request through, which closes the breaker if it succeeds, and opens it again if it
+	// fails.
+	halfOpen
+)
+
+// String returns the name of the state, as shown on the status endpoint.
+func (s breakerState) String() string {
+	switch s {
+	case open:
+		return "open"
+	case halfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// breaker is a circuit breaker, which opens after a number of consecutive failures, so a failing external service is
+// not called until the cooldown has passed.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// newBreaker creates a closed circuit breaker.
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow returns whether a request may be sent. Once the cooldown of an open breaker has passed, a single trial request
+// is allowed.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case open:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = halfOpen
+		return true
+	case halfOpen:
+		// The trial request is still in flight
+		return false
+	default:
+		return true
+	}
+}
+
+// record records the outcome of a request that was allowed.
+func (b *breaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state = closed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == halfOpen || b.failures >= b.threshold {
+		b.state = open
+		b.openedAt = b.now()
+	}
+}
+
+// release records that a request that was allowed ended without an outcome, as it was cancelled by the caller. A trial
+// request is let through again.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == halfOpen {
+		b.state = open
+	}
+}
+
+// stats returns the state of the breaker and the number of consecutive failures.
+func (b *breaker) stats() (breakerState, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// An open breaker whose cooldown has passed lets the next request through
+	if b.state == open && b.now().Sub(b.openedAt) >= b.cooldown {
+		return halfOpen, b.failures
+	}
+	return b.state, b.failures
+}
@@ -0,0 +1,200 @@
+// Package upstream sends requests to the external services, with a circuit breaker per service, retries with
+// exponential backoff for idempotent requests, and jittered timeouts.
+package upstream
+
+import (
+	"assignment-2/internal/constants"
+	"assignment-2/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrBreakerOpen is returned for requests that are not sent, as the circuit breaker of the service is open.
+var ErrBreakerOpen = errors.New(constants.ErrExternalBreaker)
+
+// ErrFailingStatus is returned for requests whose last attempt got a response with a 5xx or 429 status code.
+var ErrFailingStatus = errors.New(constants.ErrExternalStatus)
+
+// Stats are the state of the circuit breaker of a service.
+type Stats struct {
+	State    string `json:"state"`
+	Failures int    `json:"failures"`
+}
+
+// Client sends requests to an external service.
+type Client struct {
+	name     string
+	settings utils.UpstreamSettings
+	client   *http.Client
+	breaker  *breaker
+}
+
+// Clients of the external services, configured from the environment variables with their prefix
+var (
+	Countries = New("countries", utils.GetUpstreamSettings("COUNTRIES"))
+	Meteo     = New("meteo", utils.GetUpstreamSettings("METEO"))
+	Currency  = New("currency", utils.GetUpstreamSettings("CURRENCY"))
+)
+
+// registry holds the statistics of every client, by name.
+var (
+	registry   = map[string]func() Stats{}
+	registryMu sync.Mutex
+)
+
+// New creates a client with the given settings, and registers its statistics under the given name.
+func New(name string, settings utils.UpstreamSettings) *Client {
+	c := &Client{
+		name:     name,
+		settings: settings,
+		// The timeout is set per attempt instead, see do
+		client:  &http.Client{},
+		breaker: newBreaker(settings.BreakerThreshold, settings.BreakerCooldown),
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = c.Stats
+
+	return c
+}
+
+// Do sends the request. Idempotent requests that fail, or get a response with a 5xx or 429 status code, are retried
+// with exponential backoff. Returns ErrBreakerOpen without sending the request while the circuit breaker is open, and
+// ErrFailingStatus once the attempts are used up, so a failure of the service, as counted by the breaker, is an error
+// for the caller too. Other responses are returned whatever their status code. If the context of the request is done,
+// its error is returned without retrying, and without counting as a failure of the service.
+func (c *Client) Do(r *http.Request) (*http.Response, error) {
+	attempts := 1
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		attempts = c.settings.MaxAttempts
+	}
+
+	var res *http.Response
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if err := sleep(r.Context(), c.backoff(attempt-1)); err != nil {
+				return nil, err
+			}
+		}
+
+		if !c.breaker.allow() {
+			return nil, ErrBreakerOpen
+		}
+
+		res, err = c.do(r)
+		if ctxErr := r.Context().Err(); ctxErr != nil {
+			// The caller gave up, which says nothing about the service
+			c.breaker.release()
+			if res != nil {
+				_ = res.Body.Close()
+			}
+			return nil, ctxErr
+		}
+		failed := err != nil || isRetryable(res.StatusCode)
+		c.breaker.record(!failed)
+		if !failed {
+			return res, nil
+		}
+
+		// Discard the response of a failed attempt
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			_ = res.Body.Close()
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: %s", ErrFailingStatus, res.Status)
+}
+
+// Probe sends the request once, without retries and without the circuit breaker, so the status of the service is
+// that of the request even while the breaker is open. Its outcome does not count towards the breaker.
+func (c *Client) Probe(r *http.Request) (*http.Response, error) {
+	return c.do(r)
+}
+
+// do sends a single attempt of the request, with a jittered timeout so clients do not time out in lockstep.
+func (c *Client) do(r *http.Request) (*http.Response, error) {
+	timeout := c.settings.Timeout + time.Duration(rand.Int63n(int64(c.settings.TimeoutJitter)+1))
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+
+	res, err := c.client.Do(r.Clone(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// The timeout also covers reading the body, so it is only cancelled when the body is closed
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// backoff returns the time to wait before the given retry: the retry backoff, doubled for every earlier retry, of
+// which a random half is waited.
+func (c *Client) backoff(retry int) time.Duration {
+	backoff := c.settings.RetryBackoff << (retry - 1)
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// Stats returns the state of the circuit breaker of the client.
+func (c *Client) Stats() Stats {
+	state, failures := c.breaker.stats()
+	return Stats{
+		State:    state.String(),
+		Failures: failures,
+	}
+}
+
+// AllStats returns the state of the circuit breaker of every client, by name.
+func AllStats() map[string]Stats {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	allStats := make(map[string]Stats, len(registry))
+	for name, stats := range registry {
+		allStats[name] = stats()
+	}
+	return allStats
+}
+
+// isRetryable returns whether a response with the given status code means the service failed, and the request may
+// succeed when retried.
+func isRetryable(statusCode int) bool {
+	return statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests
+}
+
+// sleep waits for the given duration, or until the context is done.
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cancelOnClose is a response body that cancels the context of its request when it is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context of the request.
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,280 @@
+package upstream
+
+import (
+	"assignment-2/internal/utils"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testSettings are settings that keep the tests fast.
+var testSettings = utils.UpstreamSettings{
+	Timeout:          100 * time.Millisecond,
+	TimeoutJitter:    10 * time.Millisecond,
+	MaxAttempts:      3,
+	RetryBackoff:     time.Millisecond,
+	BreakerThreshold: 3,
+	BreakerCooldown:  time.Minute,
+}
+
+// newTestServer starts a server that responds with the status codes in order, repeating the last one, and counts the
+// requests it receives.
+func newTestServer(t *testing.T, statusCodes ...int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				n := int(atomic.AddInt32(&requests, 1))
+				w.WriteHeader(statusCodes[min(n, len(statusCodes))-1])
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestClient_Do(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		statusCodes  []int
+		wantStatus   int
+		wantErr      error
+		wantRequests int32
+	}{
+		{
+			name:         "Successful request is not retried",
+			method:       http.MethodGet,
+			statusCodes:  []int{http.StatusOK},
+			wantStatus:   http.StatusOK,
+			wantRequests: 1,
+		},
+		{
+			name:         "Failing GET is retried until it succeeds",
+			method:       http.MethodGet,
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			wantStatus:   http.StatusOK,
+			wantRequests: 3,
+		},
+		{
+			name:         "Failing GET returns an error once the attempts are used up",
+			method:       http.MethodGet,
+			statusCodes:  []int{http.StatusInternalServerError},
+			wantErr:      ErrFailingStatus,
+			wantRequests: 3,
+		},
+		{
+			name:         "Client error is not retried",
+			method:       http.MethodGet,
+			statusCodes:  []int{http.StatusNotFound},
+			wantStatus:   http.StatusNotFound,
+			wantRequests: 1,
+		},
+		{
+			name:         "POST is not retried",
+			method:       http.MethodPost,
+			statusCodes:  []int{http.StatusInternalServerError},
+			wantErr:      ErrFailingStatus,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				server, requests := newTestServer(t, tt.statusCodes...)
+				client := New(t.Name(), testSettings)
+
+				r, _ := http.NewRequest(tt.method, server.URL, nil)
+				res, err := client.Do(r)
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) || res != nil {
+						t.Errorf("Do() = %v, %v, want %v", res, err, tt.wantErr)
+					}
+				} else if err != nil {
+					t.Fatalf("Do() error = %v", err)
+				} else {
+					_ = res.Body.Close()
+					if res.StatusCode != tt.wantStatus {
+						t.Errorf("Do() status = %v, want %v", res.StatusCode, tt.wantStatus)
+					}
+				}
+				if got := atomic.LoadInt32(requests); got != tt.wantRequests {
+					t.Errorf("Do() sent %v requests, want %v", got, tt.wantRequests)
+				}
+			},
+		)
+	}
+}
+
+func TestClient_DoTimeout(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(time.Second):
+				case <-r.Context().Done():
+				}
+			},
+		),
+	)
+	defer server.Close()
+
+	settings := testSettings
+	settings.MaxAttempts = 1
+	client := New(t.Name(), settings)
+
+	start := time.Now()
+	r, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := client.Do(r); err == nil {
+		t.Errorf("Do() error = nil, want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > settings.Timeout+settings.TimeoutJitter+50*time.Millisecond {
+		t.Errorf("Do() took %v, want at most the jittered timeout", elapsed)
+	}
+}
+
+func TestClient_DoContextDone(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				select {
+				case <-time.After(time.Second):
+				case <-r.Context().Done():
+				}
+			},
+		),
+	)
+	defer server.Close()
+
+	settings := testSettings
+	settings.Timeout = time.Second
+	settings.BreakerThreshold = 1
+	client := New(t.Name(), settings)
+
+	// A request whose caller gives up is neither retried nor counted as a failure of the service
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	r, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := client.Do(r); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Do() sent %v requests, want 1", got)
+	}
+	if stats := client.Stats(); stats != (Stats{State: "closed", Failures: 0}) {
+		t.Errorf("Stats() = %+v, want a closed breaker", stats)
+	}
+}
+
+func TestClient_Probe(t *testing.T) {
+	server, requests := newTestServer(t, http.StatusInternalServerError, http.StatusOK)
+
+	settings := testSettings
+	settings.MaxAttempts = 1
+	settings.BreakerThreshold = 1
+	client := New(t.Name(), settings)
+
+	r, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := client.Do(r); !errors.Is(err, ErrFailingStatus) {
+		t.Fatalf("Do() error = %v, want %v", err, ErrFailingStatus)
+	}
+
+	// A probe is sent while the breaker is open, and does not close it
+	res, err := client.Probe(r)
+	if err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("Probe() = %v, %v, want 200", res, err)
+	}
+	_ = res.Body.Close()
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("Probe() sent %v requests in total, want 2", got)
+	}
+	if stats := client.Stats(); stats.State != "open" {
+		t.Errorf("Stats() = %+v, want an open breaker", stats)
+	}
+}
+
+func TestClient_Breaker(t *testing.T) {
+	server, requests := newTestServer(t, http.StatusInternalServerError, http.StatusInternalServerError,
+		http.StatusInternalServerError, http.StatusOK)
+
+	settings := testSettings
+	settings.MaxAttempts = 1
+	client := New(t.Name(), settings)
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+
+	get := func() error {
+		r, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		res, err := client.Do(r)
+		if err == nil {
+			_ = res.Body.Close()
+		}
+		return err
+	}
+
+	// The breaker opens after the threshold of consecutive failures
+	for i := 0; i < settings.BreakerThreshold; i++ {
+		_ = get()
+	}
+	if stats := AllStats()[t.Name()]; stats != (Stats{State: "open", Failures: 3}) {
+		t.Errorf("AllStats() = %+v, want an open breaker with 3 failures", stats)
+	}
+
+	// An open breaker rejects requests without sending them
+	if err := get(); !errors.Is(err, ErrBreakerOpen) {
+		t.Errorf("Do() error = %v, want %v", err, ErrBreakerOpen)
+	}
+	if got := atomic.LoadInt32(requests); got != 3 {
+		t.Errorf("Do() sent %v requests, want 3", got)
+	}
+
+	// After the cooldown, a successful trial request closes the breaker
+	now = now.Add(settings.BreakerCooldown)
+	if stats := client.Stats(); stats.State != "half-open" {
+		t.Errorf("Stats() = %+v, want a half-open breaker", stats)
+	}
+	if err := get(); err != nil {
+		t.Errorf("Do() error = %v, want the trial request to succeed", err)
+	}
+	if stats := client.Stats(); stats != (Stats{State: "closed", Failures: 0}) {
+		t.Errorf("Stats() = %+v, want a closed breaker", stats)
+	}
+}
+
+func TestBreaker_FailedTrial(t *testing.T) {
+	b := newBreaker(1, time.Minute)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.record(false)
+	if b.allow() {
+		t.Errorf("allow() = true, want false for an open breaker")
+	}
+
+	// Only a single trial request is let through after the cooldown
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Errorf("allow() = false, want true for the trial request")
+	}
+	if b.allow() {
+		t.Errorf("allow() = true, want false while the trial request is in flight")
+	}
+
+	// A cancelled trial lets another trial through
+	b.release()
+	if !b.allow() {
+		t.Errorf("allow() = false, want true for a trial after a cancelled one")
+	}
+
+	// A failed trial opens the breaker for another cooldown
+	b.record(false)
+	if state, _ := b.stats(); state != open {
+		t.Errorf("stats() = %v, want %v", state, open)
+	}
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"assignment-2/internal/constants"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MaxBodyBytes Maximum size of a JSON request body, 1 MiB
+const MaxBodyBytes = 1 << 20
+
+// DecodeError is an error from decoding a request body, with the status code that should be returned to the client.
+type DecodeError struct {
+	Status  int
+	Message string
+}
+
+// Error returns the message of the decode error.
+func (e *DecodeError) Error() string {
+	return e.Message
+}
+
+// DecodeJSONBody
+// Decodes the JSON body of the request into dst. The request must have the content type application/json, the body
+// must be at most MaxBodyBytes, and it must contain exactly one JSON value without fields unknown to dst.
+// Returns a DecodeError with status 400, 413 or 415 if the body is rejected.
+func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) *DecodeError {
+	// Check the content type, parameters such as charset are allowed
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return &DecodeError{
+			Status:  http.StatusUnsupportedMediaType,
+			Message: constants.ErrRequestContentType,
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return toDecodeError(err)
+	}
+
+	// Anything after the first JSON value is rejected
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return toDecodeError(err)
+		}
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: constants.ErrRequestTrailingData,
+		}
+	}
+
+	return nil
+}
+
+// toDecodeError converts an error from the JSON decoder to a DecodeError with a precise message.
+func toDecodeError(err error) *DecodeError {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	var maxBytesError *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesError):
+		return &DecodeError{
+			Status:  http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("%s: limit is %d bytes", constants.ErrRequestTooLarge, maxBytesError.Limit),
+		}
+	case errors.As(err, &syntaxError):
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("%s at position %d", constants.ErrJsonInvalid, syntaxError.Offset),
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: constants.ErrJsonInvalid,
+		}
+	case errors.As(err, &typeError):
+		return &DecodeError{
+			Status: http.StatusBadRequest,
+			Message: fmt.Sprintf(
+				"%s: field '%s' must be of type %s", constants.ErrJsonInvalid, typeError.Field, typeError.Type,
+			),
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// The decoder has no typed error for unknown fields, so the field name is taken from the message
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("%s: unknown field %s", constants.ErrJsonInvalid, field),
+		}
+	case errors.Is(err, io.EOF):
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: constants.ErrRequestEmptyBody,
+		}
+	default:
+		return &DecodeError{
+			Status:  http.StatusBadRequest,
+			Message: constants.ErrJsonDecode,
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"assignment-2/internal/constants"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DefaultIdempotencyTTL Default time an idempotency key is remembered
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// DefaultEventsBufferSize Default number of recent events kept for resuming event streams
+const DefaultEventsBufferSize = 256
+
+// DefaultLiveRefreshInterval Default time between refreshes of live dashboards
+const DefaultLiveRefreshInterval = time.Minute
+
+// DefaultCountryCacheTTL Default time country data from the restcountries API is cached
+const DefaultCountryCacheTTL = 24 * time.Hour
+
+// DefaultCurrencyCacheTTL Default time exchange rates from the currency API are cached
+const DefaultCurrencyCacheTTL = time.Hour
+
+// DefaultWeatherCacheTTL Default time weather data from the Open-Meteo API is cached
+const DefaultWeatherCacheTTL = 15 * time.Minute
+
+// DefaultBadgeCacheTTL Default time the dashboards of badges are cached
+const DefaultBadgeCacheTTL = 5 * time.Minute
+
+// DefaultDashboardTimeout Default time the external services have to populate a dashboard
+const DefaultDashboardTimeout = 5 * time.Second
+
+// DefaultRegionConcurrency Default number of countries of a regional dashboard populated at the same time
+const DefaultRegionConcurrency = 4
+
+// DefaultSnapshotRetention Default time the snapshots of a dashboard are kept
+const DefaultSnapshotRetention = 30 * 24 * time.Hour
+
+// DefaultSnapshotLimit Default largest number of snapshots kept of a dashboard
+const DefaultSnapshotLimit = 1000
+
+// DefaultUpstreamTimeout Default time a single request to an external service may take
+const DefaultUpstreamTimeout = 3 * time.Second
+
+// DefaultUpstreamTimeoutJitter Default maximum random time added to the timeout of a request to an external service
+const DefaultUpstreamTimeoutJitter = 300 * time.Millisecond
+
+// DefaultUpstreamMaxAttempts Default number of attempts of an idempotent request to an external service
+const DefaultUpstreamMaxAttempts = 3
+
+// DefaultUpstreamRetryBackoff Default time waited before the first retry of a request to an external service,
+// doubling for every further retry
+const DefaultUpstreamRetryBackoff = 100 * time.Millisecond
+
+// DefaultUpstreamBreakerThreshold Default number of consecutive failures opening the circuit breaker of an external
+// service
+const DefaultUpstreamBreakerThreshold = 5
+
+// DefaultUpstreamBreakerCooldown Default time the circuit breaker of an external service stays open, before a trial
+// request is let through
+const DefaultUpstreamBreakerCooldown = 30 * time.Second
+
+// DefaultCountryProvider Default provider of country data
+const DefaultCountryProvider = "restcountries"
+
+// DefaultWeatherProvider Default provider of weather data
+const DefaultWeatherProvider = "open-meteo"
+
+// DefaultCurrencyProvider Default provider of exchange rates
+const DefaultCurrencyProvider = "exchangerate-api"
+
+// UpstreamSettings are the settings of the client for an external service
+type UpstreamSettings struct {
+	Timeout          time.Duration
+	TimeoutJitter    time.Duration
+	MaxAttempts      int
+	RetryBackoff     time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// GetIdempotencyTTL Get the idempotency key TTL from the environment variable, or use the default TTL
+func GetIdempotencyTTL() time.Duration {
+	return getDurationEnv("IDEMPOTENCY_TTL", DefaultIdempotencyTTL)
+}
+
+// GetEventsBufferSize Get the number of recent events kept for resuming event streams, or use the default size
+func GetEventsBufferSize() int {
+	return getIntEnv("EVENTS_BUFFER_SIZE", DefaultEventsBufferSize)
+}
+
+// GetLiveRefreshInterval Get the time between refreshes of live dashboards, or use the default interval
+func GetLiveRefreshInterval() time.Duration {
+	return getDurationEnv("LIVE_REFRESH_INTERVAL", DefaultLiveRefreshInterval)
+}
+
+// GetCountryCacheTTL Get the time country data is cached, or use the default TTL
+func GetCountryCacheTTL() time.Duration {
+	return getDurationEnv("COUNTRY_CACHE_TTL", DefaultCountryCacheTTL)
+}
+
+// GetCurrencyCacheTTL Get the time exchange rates are cached, or use the default TTL
+func GetCurrencyCacheTTL() time.Duration {
+	return getDurationEnv("CURRENCY_CACHE_TTL", DefaultCurrencyCacheTTL)
+}
+
+// GetWeatherCacheTTL Get the time weather data is cached, or use the default TTL
+func GetWeatherCacheTTL() time.Duration {
+	return getDurationEnv("WEATHER_CACHE_TTL", DefaultWeatherCacheTTL)
+}
+
+// GetBadgeCacheTTL Get the time the dashboards of badges are cached, or use the default TTL
+func GetBadgeCacheTTL() time.Duration {
+	return getDurationEnv("BADGE_CACHE_TTL", DefaultBadgeCacheTTL)
+}
+
+// GetDashboardTimeout Get the time the external services have to populate a dashboard, or use the default timeout
+func GetDashboardTimeout() time.Duration {
+	return getDurationEnv("DASHBOARD_TIMEOUT", DefaultDashboardTimeout)
+}
+
+// GetRegionConcurrency Get the number of countries of a regional dashboard populated at the same time, or use the
+// default number
+func GetRegionConcurrency() int {
+	concurrency := getIntEnv("REGION_CONCURRENCY", DefaultRegionConcurrency)
+	if concurrency < 1 {
+		return 1
+	}
+	return concurrency
+}
+
+// GetSnapshotRetention Get the time the snapshots of a dashboard are kept, or use the default retention. A retention
+// of 0 keeps them regardless of age
+func GetSnapshotRetention() time.Duration {
+	return getDurationEnvFrom("SNAPSHOT_RETENTION", DefaultSnapshotRetention, 0)
+}
+
+// GetSnapshotLimit Get the largest number of snapshots kept of a dashboard, or use the default limit. A limit of 0
+// keeps them regardless of number
+func GetSnapshotLimit() int {
+	return getIntEnvFrom("SNAPSHOT_LIMIT", DefaultSnapshotLimit, 0)
+}
+
+// GetUpstreamSettings Get the settings of the client for an external service from the environment variables with the
+// given prefix, e.g. "COUNTRIES" for $COUNTRIES_TIMEOUT, or use the defaults
+func GetUpstreamSettings(prefix string) UpstreamSettings {
+	return UpstreamSettings{
+		Timeout:          getDurationEnv(prefix+"_TIMEOUT", DefaultUpstreamTimeout),
+		TimeoutJitter:    getDurationEnv(prefix+"_TIMEOUT_JITTER", DefaultUpstreamTimeoutJitter),
+		MaxAttempts:      getIntEnv(prefix+"_MAX_ATTEMPTS", DefaultUpstreamMaxAttempts),
+		RetryBackoff:     getDurationEnv(prefix+"_RETRY_BACKOFF", DefaultUpstreamRetryBackoff),
+		BreakerThreshold: getIntEnv(prefix+"_BREAKER_THRESHOLD", DefaultUpstreamBreakerThreshold),
+		BreakerCooldown:  getDurationEnv(prefix+"_BREAKER_COOLDOWN", DefaultUpstreamBreakerCooldown),
+	}
+}
+
+// GetCountryProvider Get the name of the provider of country data, or use the default provider
+func GetCountryProvider() string {
+	return getStringEnv("COUNTRY_PROVIDER", DefaultCountryProvider)
+}
+
+// GetWeatherProvider Get the name of the provider of weather data, or use the default provider
+func GetWeatherProvider() string {
+	return getStringEnv("WEATHER_PROVIDER", DefaultWeatherProvider)
+}
+
+// GetCurrencyProvider Get the name of the provider of exchange rates, or use the default provider
+func GetCurrencyProvider() string {
+	return getStringEnv("CURRENCY_PROVIDER", DefaultCurrencyProvider)
+}
+
+// getStringEnv Get a string from an environment variable, or use the fallback
+func getStringEnv(name string, fallback string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// getIntEnv Get a positive integer from an environment variable, or use the fallback
+func getIntEnv(name string, fallback int) int {
+	return getIntEnvFrom(name, fallback, 1)
+}
+
+// getIntEnvFrom Get an integer of at least the minimum from an environment variable, or use the fallback
+func getIntEnvFrom(name string, fallback int, minimum int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < minimum {
+		log.Printf("%s $%s=%q, using default: %d\n", constants.ErrParsingEnvVar, name, value, fallback)
+		return fallback
+	}
+
+	return number
+}
+
+// getDurationEnv Get a duration such as "15m" or "24h" from an environment variable, or use the fallback
+func getDurationEnv(name string, fallback time.Duration) time.Duration {
+	return getDurationEnvFrom(name, fallback, time.Nanosecond)
+}
+
+// getDurationEnvFrom Get a duration of at least the minimum from an environment variable, or use the fallback
+func getDurationEnvFrom(name string, fallback time.Duration, minimum time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < minimum {
+		log.Printf("%s $%s=%q, using default: %s\n", constants.ErrParsingEnvVar, name, value, fallback)
+		return fallback
+	}
+
+	return duration
+}
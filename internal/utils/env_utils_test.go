@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetSnapshotRetention(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "Unset", value: "", want: DefaultSnapshotRetention},
+		{name: "Duration", value: "48h", want: 48 * time.Hour},
+		{name: "Zero disables the rule", value: "0", want: 0},
+		{name: "Negative duration", value: "-1h", want: DefaultSnapshotRetention},
+		{name: "Invalid duration", value: "a month", want: DefaultSnapshotRetention},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				t.Setenv("SNAPSHOT_RETENTION", tt.value)
+				if got := GetSnapshotRetention(); got != tt.want {
+					t.Errorf("GetSnapshotRetention() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestGetSnapshotLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  int
+	}{
+		{name: "Unset", value: "", want: DefaultSnapshotLimit},
+		{name: "Number", value: "50", want: 50},
+		{name: "Zero disables the rule", value: "0", want: 0},
+		{name: "Negative number", value: "-1", want: DefaultSnapshotLimit},
+		{name: "Invalid number", value: "many", want: DefaultSnapshotLimit},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				t.Setenv("SNAPSHOT_LIMIT", tt.value)
+				if got := GetSnapshotLimit(); got != tt.want {
+					t.Errorf("GetSnapshotLimit() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,14 @@
+package utils
+
+// IsIsoCode returns whether the given upper case code has the form of an ISO 3166-1 alpha-2 or alpha-3 code.
+func IsIsoCode(isoCode string) bool {
+	if len(isoCode) != 2 && len(isoCode) != 3 {
+		return false
+	}
+	for _, letter := range isoCode {
+		if letter < 'A' || letter > 'Z' {
+			return false
+		}
+	}
+	return true
+}
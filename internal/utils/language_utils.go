@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"golang.org/x/text/language"
+	"net/http"
+)
+
+// SupportedLanguages are the languages the names of dashboards can be in, which are those the countries service
+// translates to. English is the fallback.
+var SupportedLanguages = []language.Tag{
+	language.English,
+	language.Arabic,
+	language.Make("br"),
+	language.Czech,
+	language.Make("cy"),
+	language.German,
+	language.Estonian,
+	language.Finnish,
+	language.French,
+	language.Croatian,
+	language.Hungarian,
+	language.Italian,
+	language.Japanese,
+	language.Korean,
+	language.Dutch,
+	language.Persian,
+	language.Polish,
+	language.Portuguese,
+	language.Russian,
+	language.Slovak,
+	language.Spanish,
+	language.Serbian,
+	language.Swedish,
+	language.Turkish,
+	language.Urdu,
+	language.Chinese,
+}
+
+// languageMatcher matches the languages of requests to the supported languages
+var languageMatcher = language.NewMatcher(SupportedLanguages)
+
+// GetLanguageFromRequest returns the supported language best matching the lang query parameter of the request, or
+// else its Accept-Language header, or an empty string if the request asks for no language.
+func GetLanguageFromRequest(r *http.Request) string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return NegotiateLanguage(lang)
+	}
+	if accept := r.Header.Get("Accept-Language"); accept != "" {
+		return NegotiateLanguage(accept)
+	}
+	return ""
+}
+
+// NegotiateLanguage returns the base of the supported language best matching the given Accept-Language value, such as
+// "de" for "de-CH, fr;q=0.8". It is English if no supported language matches, or the value cannot be parsed.
+func NegotiateLanguage(accept string) string {
+	tags, _, err := language.ParseAcceptLanguage(accept)
+	if err != nil || len(tags) == 0 {
+		return language.English.String()
+	}
+
+	_, index, confidence := languageMatcher.Match(tags...)
+	if confidence == language.No {
+		return language.English.String()
+	}
+	return SupportedLanguages[index].String()
+}
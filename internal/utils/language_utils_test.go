@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{name: "Single language", accept: "de", want: "de"},
+		{name: "Regional variant", accept: "fr-CH", want: "fr"},
+		{name: "Highest quality first", accept: "it;q=0.5, es;q=0.9", want: "es"},
+		{name: "Unsupported languages are skipped", accept: "nb-NO, sv;q=0.8, en;q=0.5", want: "sv"},
+		{name: "English fallback", accept: "nb-NO, da;q=0.8", want: "en"},
+		{name: "Any language", accept: "*", want: "en"},
+		{name: "Invalid value", accept: "not a language;q=x", want: "en"},
+		{name: "Script variant", accept: "zh-Hant-TW", want: "zh"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := NegotiateLanguage(tt.accept); got != tt.want {
+					t.Errorf("NegotiateLanguage(%q) = %v, want %v", tt.accept, got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestGetLanguageFromRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		accept string
+		want   string
+	}{
+		{name: "No language asked for", target: "/", want: ""},
+		{name: "Accept-Language header", target: "/", accept: "ja-JP,ja;q=0.9", want: "ja"},
+		{name: "Parameter over header", target: "/?lang=pt-BR", accept: "de", want: "pt"},
+		{name: "Unsupported parameter", target: "/?lang=xx", accept: "de", want: "en"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				r := httptest.NewRequest("GET", tt.target, nil)
+				if tt.accept != "" {
+					r.Header.Set("Accept-Language", tt.accept)
+				}
+				if got := GetLanguageFromRequest(r); got != tt.want {
+					t.Errorf("GetLanguageFromRequest() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
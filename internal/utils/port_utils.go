@@ -8,6 +8,9 @@ import (
 // DefaultPort Default port for the server
 const DefaultPort = "8000"
 
+// DefaultGrpcPort Default port for the gRPC server
+const DefaultGrpcPort = "9000"
+
 // TestPort Test port for the server
 const TestPort = "8001"
 
@@ -25,6 +28,20 @@ func GetPort() string {
 	return port
 }
 
+// GetGrpcPort Get the gRPC port from the environment variable, or use the default gRPC port
+func GetGrpcPort() string {
+	// Get the GRPC_PORT environment variable
+	port := os.Getenv("GRPC_PORT")
+
+	// Use default gRPC port if not provided
+	if port == "" {
+		log.Println("$GRPC_PORT has not been set. Default: " + DefaultGrpcPort)
+		port = DefaultGrpcPort
+	}
+
+	return port
+}
+
 // GetTestPort Get the test port from the environment variable, or use the default test port
 func GetTestPort() string {
 	// Get the PORT environment variable